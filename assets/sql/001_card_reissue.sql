@@ -0,0 +1,10 @@
+-- card reissue (POST /v1/cards/{id}/reissue)
+-- a reissued card is linked to its predecessor, a DAMAGED or RENEWAL reissue keeps the card_number
+-- so the card_number is only unique among the cards not canceled
+
+ALTER TABLE public.card ADD COLUMN IF NOT EXISTS fk_predecessor_id integer REFERENCES public.card(id);
+ALTER TABLE public.card ADD COLUMN IF NOT EXISTS reissue_reason varchar(20);
+
+ALTER TABLE public.card DROP CONSTRAINT IF EXISTS card_card_number_key;
+CREATE UNIQUE INDEX IF NOT EXISTS card_card_number_active_uk ON public.card (card_number) WHERE status <> 'CANCELED';
+CREATE INDEX IF NOT EXISTS card_fk_predecessor_id_idx ON public.card (fk_predecessor_id);
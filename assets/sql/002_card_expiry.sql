@@ -0,0 +1,6 @@
+-- card expiry job (go-card jobs run expiry)
+-- a card is flagged once as a renewal candidate, the partial index keeps the job scan on the live cards
+
+ALTER TABLE public.card ADD COLUMN IF NOT EXISTS renewal_candidate_at timestamptz;
+
+CREATE INDEX IF NOT EXISTS card_expired_at_live_idx ON public.card (expired_at) WHERE status not in ('EXPIRED', 'CANCELED');
@@ -0,0 +1,4 @@
+-- cvv2 verification (POST /v1/cards/{id}/cvv/verify)
+-- only the failed verifications counter is stored, never the cvv2
+
+ALTER TABLE public.card ADD COLUMN IF NOT EXISTS cvv_failures integer NOT NULL DEFAULT 0;
@@ -0,0 +1,6 @@
+-- card pin (POST/PUT /v1/cards/{id}/pin, POST /v1/cards/{id}/pin/verify)
+-- only the Visa PVV and the index of its key are stored, never the pin
+
+ALTER TABLE public.card ADD COLUMN IF NOT EXISTS pin_pvv varchar(4);
+ALTER TABLE public.card ADD COLUMN IF NOT EXISTS pin_pvki smallint;
+ALTER TABLE public.card ADD COLUMN IF NOT EXISTS pin_failures integer NOT NULL DEFAULT 0;
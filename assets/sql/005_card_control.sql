@@ -0,0 +1,24 @@
+-- card spending controls (GET/PUT /v1/cards/{id}/controls, POST /v1/cards/{id}/controls/evaluate)
+-- a card without a row has no limit and every channel enabled, an empty mcc_allow allows every mcc
+-- the amounts spent in the day and month of usage_date are kept in the row (velocity counters)
+-- the amounts are in minor units of the currency (ex: 12050 is 120.50 BRL)
+
+CREATE TABLE IF NOT EXISTS public.card_control (
+    fk_card_id              integer PRIMARY KEY REFERENCES public.card(id),
+    currency                varchar(3) NOT NULL,
+    transaction_limit       bigint,
+    daily_limit             bigint,
+    monthly_limit           bigint,
+    mcc_allow               varchar(4)[] NOT NULL DEFAULT '{}',
+    mcc_deny                varchar(4)[] NOT NULL DEFAULT '{}',
+    ecommerce_enabled       boolean NOT NULL DEFAULT true,
+    international_enabled   boolean NOT NULL DEFAULT true,
+    atm_transaction_limit   bigint,
+    atm_daily_limit         bigint,
+    usage_date              date NOT NULL DEFAULT CURRENT_DATE,
+    daily_spent             bigint NOT NULL DEFAULT 0,
+    monthly_spent           bigint NOT NULL DEFAULT 0,
+    atm_daily_spent         bigint NOT NULL DEFAULT 0,
+    created_at              timestamptz NOT NULL DEFAULT now(),
+    updated_at              timestamptz
+);
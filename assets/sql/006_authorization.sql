@@ -0,0 +1,61 @@
+-- card authorizations (POST /v1/cards/{id}/authorize) and their history (GET /v1/cards/{id}/authorizations)
+-- every attempt is recorded with its ISO 8583 response code, the card not found (14) is not recorded
+-- authorization is a reserved word, the table name must be quoted
+-- the table is partitioned by month (UTC) of created_at, so the primary key has created_at: a query of a card
+-- and a date range only scans the partitions of the range and an old month is dropped with its partition (DROP TABLE)
+-- go-card creates the partitions of the current and next 2 months at start and every day,
+-- the default partition keeps the rows of a month without partition (it must stay empty)
+
+CREATE TABLE IF NOT EXISTS public."authorization" (
+    id              bigserial,
+    fk_card_id      integer NOT NULL REFERENCES public.card(id),
+    amount          bigint NOT NULL, -- minor units of the currency
+    currency        varchar(3) NOT NULL,
+    mcc             varchar(4) NOT NULL,
+    channel         varchar(20) NOT NULL,
+    merchant_id     varchar(15),
+    merchant_name   varchar(40),
+    atc             integer,
+    response_code   varchar(2) NOT NULL,
+    decision        varchar(10) NOT NULL,
+    reasons         varchar(40)[] NOT NULL DEFAULT '{}',
+    trace_id        varchar(100),
+    created_at      timestamptz NOT NULL DEFAULT now(),
+    PRIMARY KEY (id, created_at)
+) PARTITION BY RANGE (created_at);
+
+-- the history of a card, newest first
+CREATE INDEX IF NOT EXISTS authorization_fk_card_id_created_at_idx ON public."authorization" (fk_card_id, created_at DESC, id DESC);
+
+CREATE TABLE IF NOT EXISTS public.authorization_default PARTITION OF public."authorization" DEFAULT;
+
+-- create the missing monthly partitions (authorization_YYYY_MM) from the month of from_month, returns the created count
+-- the pods call it at the same time, the advisory lock serializes them
+CREATE OR REPLACE FUNCTION public.create_authorization_partitions(from_month timestamptz, months integer)
+RETURNS integer
+LANGUAGE plpgsql
+SET timezone = 'UTC'
+AS $$
+DECLARE
+    month_start     timestamptz := date_trunc('month', from_month);
+    partition_name  text;
+    created         integer := 0;
+BEGIN
+    PERFORM pg_advisory_xact_lock(hashtext('public.create_authorization_partitions'));
+
+    FOR i IN 1 .. months LOOP
+        partition_name := 'authorization_' || to_char(month_start, 'YYYY_MM');
+        IF to_regclass('public.' || partition_name) IS NULL THEN
+            EXECUTE format('CREATE TABLE public.%I PARTITION OF public."authorization" FOR VALUES FROM (%L) TO (%L)',
+                            partition_name, month_start, month_start + interval '1 month');
+            created := created + 1;
+        END IF;
+        month_start := month_start + interval '1 month';
+    END LOOP;
+
+    RETURN created;
+END
+$$;
+
+-- the partitions of the current and next 2 months, before the first pod starts
+SELECT public.create_authorization_partitions(now(), 3);
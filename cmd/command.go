@@ -0,0 +1,107 @@
+package main
+
+import(
+	"fmt"
+	"context"
+	"os"
+	"strings"
+
+	"github.com/go-card/internal/adapter/api"
+	"github.com/go-card/internal/core/model"
+	"github.com/go-card/internal/infra/configuration"
+	"github.com/go-card/internal/infra/server"
+)
+
+const usage = `usage: go-card [command]
+
+commands:
+  config validate      load the config file and env var and check them, exit 1 when invalid
+  openapi check        check that every route has an openapi entry, exit 1 when a route is missing
+  jobs run expiry      run the card expiry job once (EXPIRED cards and renewal candidates), exit 1 on error
+  jobs run partitions  create the monthly partitions of the authorizations (current and next 2 months), exit 1 on error
+  hsm [command]        manage the key file of the software HSM (go-card hsm for the commands)
+  iso8583 send         send an authorization message to the iso 8583 server (go-card iso8583 for the flags)`
+
+// About run a command instead of the server, returns the exit code
+func runCommand(args []string) int {
+	childLogger.Info().Str("func","runCommand").Strs("args", args).Send()
+
+	if len(args) > 0 && args[0] == "hsm" {
+		return runHSMCommand(args[1:])
+	}
+	if len(args) > 0 && args[0] == "iso8583" {
+		return runIso8583Command(args[1:])
+	}
+
+	switch strings.Join(args, " ") {
+	case "config validate":
+		err := configuration.ValidateConfig()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "invalid configuration:\n%v\n", err)
+			return 1
+		}
+		fmt.Fprintln(os.Stdout, "configuration OK")
+		return 0
+	case "openapi check":
+		return checkOpenAPI()
+	case "jobs run expiry":
+		return runExpiryJob()
+	case "jobs run partitions":
+		return runPartitionJob()
+	default:
+		fmt.Fprintln(os.Stderr, usage)
+		return 2
+	}
+}
+
+// About build the router (no dependency is started) and check its openapi entries
+func checkOpenAPI() int {
+	httpRouters := api.NewHttpRouters(nil, 0)
+	router := server.NewRouter(&httpRouters, &model.AppServer{InfoPod: &model.InfoPod{}}, server.NewRateLimiter(model.RateLimit{}))
+
+	missing := (&api.OpenAPI{}).Build(server.Routes(router), "")
+	if len(missing) > 0 {
+		fmt.Fprintf(os.Stderr, "routes without openapi entry:\n  %s\n", strings.Join(missing, "\n  "))
+		return 1
+	}
+	fmt.Fprintln(os.Stdout, "openapi OK")
+	return 0
+}
+
+// About run the expiry job once with the server configuration and database (ex: a kubernetes CronJob)
+func runExpiryJob() int {
+	loadAppServer()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// the connections of the pool are closed with the process
+	openDatabase(ctx)
+
+	res, err := newWorkerService().RunExpiryJob(ctx, *appServer.ExpiryJob)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "expiry job failed (expired: %d, renewal candidates: %d):\n%v\n", res.Expired, res.RenewalCandidates, err)
+		return 1
+	}
+	fmt.Fprintf(os.Stdout, "expiry job OK (expired: %d, renewal candidates: %d)\n", res.Expired, res.RenewalCandidates)
+	return 0
+}
+
+// About create the authorization partitions once with the server configuration and database
+func runPartitionJob() int {
+	loadAppServer()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// the connections of the pool are closed with the process
+	openDatabase(ctx)
+
+	created, err := newWorkerService().CreateAuthorizationPartitions(ctx)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "partition job failed:\n%v\n", err)
+		return 1
+	}
+	fmt.Fprintf(os.Stdout, "partition job OK (created: %d)\n", created)
+	return 0
+}
@@ -0,0 +1,149 @@
+package main
+
+import(
+	"io"
+	"os"
+	"fmt"
+	"time"
+	"errors"
+	"strings"
+	"strconv"
+	"encoding/hex"
+	"text/tabwriter"
+
+	"github.com/go-card/internal/core/hsm"
+	"github.com/go-card/internal/infra/configuration"
+)
+
+const hsmUsage = `usage: go-card hsm [command]
+
+commands:
+  init                          create the key file with a random version 1 of every required key
+  keys                          list the key versions (algorithm, KCV, status)
+  rotate <name>                 add a random active version of a key, the previous one becomes VERIFY_ONLY
+  import <name> <algorithm>     add an active version of a key, the value is read (hex) from stdin
+  retire <name> <version>       retire a VERIFY_ONLY version of a key`
+
+// About run a key management command of the software HSM, the key file and master key come from the env var
+func runHSMCommand(args []string) int {
+	childLogger.Info().Str("func","runHSMCommand").Strs("args", args).Send()
+
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, hsmUsage)
+		return 2
+	}
+
+	keyFile, masterKey, err := configuration.GetKeyFileEnv()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "invalid key file configuration: %v\n", err)
+		return 1
+	}
+	defer clear(masterKey)
+
+	now := time.Now().UTC()
+
+	switch {
+	case args[0] == "init" && len(args) == 1:
+		if _, err := os.Stat(keyFile); !errors.Is(err, os.ErrNotExist) {
+			fmt.Fprintf(os.Stderr, "key file %s already exists\n", keyFile)
+			return 1
+		}
+		keyStore := hsm.NewSoftwareKeyStore()
+		for name, algorithm := range hsm.RequiredKeys {
+			if _, err := keyStore.Rotate(name, algorithm, nil, now); err != nil {
+				fmt.Fprintf(os.Stderr, "error generate key %s: %v\n", name, err)
+				return 1
+			}
+		}
+		return saveKeyFile(keyStore, keyFile, masterKey)
+	case args[0] == "keys" && len(args) == 1:
+		keyStore, err := hsm.LoadKeyFile(keyFile, masterKey)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error load key file %s: %v\n", keyFile, err)
+			return 1
+		}
+		printKeys(keyStore.Keys())
+		if err := keyStore.Check(hsm.RequiredKeys); err != nil {
+			fmt.Fprintf(os.Stderr, "missing keys:\n%v\n", err)
+			return 1
+		}
+		return 0
+	case args[0] == "rotate" && len(args) == 2:
+		return updateKeyFile(keyFile, masterKey, func(keyStore *hsm.SoftwareKeyStore) error {
+			algorithm, ok := hsm.RequiredKeys[args[1]]
+			if !ok {
+				return fmt.Errorf("unknown key %s", args[1])
+			}
+			_, err := keyStore.Rotate(args[1], algorithm, nil, now)
+			return err
+		})
+	case args[0] == "import" && len(args) == 3:
+		data, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error read stdin: %v\n", err)
+			return 1
+		}
+		value, err := hex.DecodeString(strings.TrimSpace(string(data)))
+		clear(data)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "the key value must be hex encoded")
+			return 1
+		}
+		defer clear(value)
+		return updateKeyFile(keyFile, masterKey, func(keyStore *hsm.SoftwareKeyStore) error {
+			_, err := keyStore.Rotate(args[1], strings.ToUpper(args[2]), value, now)
+			return err
+		})
+	case args[0] == "retire" && len(args) == 3:
+		version, err := strconv.Atoi(args[2])
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "invalid version %s\n", args[2])
+			return 2
+		}
+		return updateKeyFile(keyFile, masterKey, func(keyStore *hsm.SoftwareKeyStore) error {
+			return keyStore.Retire(args[1], version, now)
+		})
+	default:
+		fmt.Fprintln(os.Stderr, hsmUsage)
+		return 2
+	}
+}
+
+// About load the key file, apply a change and write it back
+func updateKeyFile(keyFile string, masterKey []byte, update func(keyStore *hsm.SoftwareKeyStore) error) int {
+	keyStore, err := hsm.LoadKeyFile(keyFile, masterKey)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error load key file %s: %v\n", keyFile, err)
+		return 1
+	}
+	if err := update(keyStore); err != nil {
+		fmt.Fprintf(os.Stderr, "error update key file: %v\n", err)
+		return 1
+	}
+	return saveKeyFile(keyStore, keyFile, masterKey)
+}
+
+// About write the key file and list its keys
+func saveKeyFile(keyStore *hsm.SoftwareKeyStore, keyFile string, masterKey []byte) int {
+	if err := keyStore.SaveKeyFile(keyFile, masterKey); err != nil {
+		fmt.Fprintf(os.Stderr, "error save key file %s: %v\n", keyFile, err)
+		return 1
+	}
+	printKeys(keyStore.Keys())
+	fmt.Fprintf(os.Stdout, "key file %s OK\n", keyFile)
+	return 0
+}
+
+// About print the metadata of the key versions, never the values
+func printKeys(keys []hsm.KeyInfo) {
+	writer := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(writer, "NAME\tVERSION\tALGORITHM\tKCV\tSTATUS\tCREATED AT\tROTATED AT")
+	for _, key := range keys {
+		rotatedAt := ""
+		if key.RotatedAt != nil {
+			rotatedAt = key.RotatedAt.Format(time.RFC3339)
+		}
+		fmt.Fprintf(writer, "%s\t%d\t%s\t%s\t%s\t%s\t%s\n", key.Name, key.Version, key.Algorithm, key.KCV, key.Status, key.CreatedAt.Format(time.RFC3339), rotatedAt)
+	}
+	writer.Flush()
+}
@@ -0,0 +1,144 @@
+package main
+
+import(
+	"os"
+	"fmt"
+	"net"
+	"time"
+	"flag"
+	"strconv"
+	"math/rand"
+	"encoding/hex"
+	"text/tabwriter"
+
+	"github.com/go-card/internal/core/iso8583"
+	"github.com/go-card/internal/infra/configuration"
+)
+
+const iso8583Usage = `usage: go-card iso8583 send [flags]
+
+send one authorization message to the iso 8583 server (local test client) and print the response,
+the framing and the fields use the spec of the server (ISO8583_SPEC_FILE or the default spec)
+
+flags:`
+
+// About run the iso 8583 test client
+func runIso8583Command(args []string) int {
+	childLogger.Info().Str("func","runIso8583Command").Strs("args", args).Send()
+
+	flags := flag.NewFlagSet("iso8583 send", flag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Fprintln(os.Stderr, iso8583Usage)
+		flags.PrintDefaults()
+	}
+	addr := flags.String("addr", "localhost:" + os.Getenv("ISO8583_PORT"), "address of the iso 8583 server")
+	mti := flags.String("mti", "0100", "request mti, 0100 or 0200")
+	pan := flags.String("pan", "", "card number (DE2)")
+	amount := flags.String("amount", "", "amount in minor units (DE4), ex: 12050 for 120.50")
+	currency := flags.String("currency", "986", "numeric currency code (DE49)")
+	mcc := flags.String("mcc", "5411", "merchant category code (DE18)")
+	processingCode := flags.String("processing-code", "000000", "processing code (DE3), 01xxxx for an ATM withdrawal")
+	entryMode := flags.String("entry-mode", "051", "pos entry mode (DE22), 01x or 81x for e-commerce")
+	emvData := flags.String("emv", "", "emv data hex encoded (DE55), ex: 9F36020006")
+
+	if len(args) == 0 || args[0] != "send" {
+		flags.Usage()
+		return 2
+	}
+	if err := flags.Parse(args[1:]); err != nil {
+		return 2
+	}
+	if *pan == "" || *amount == "" {
+		flags.Usage()
+		return 2
+	}
+
+	spec, err := configuration.GetIso8583SpecEnv()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "invalid iso 8583 spec: %v\n", err)
+		return 1
+	}
+
+	value, err := strconv.ParseInt(*amount, 10, 64)
+	if err != nil || value <= 0 {
+		fmt.Fprintf(os.Stderr, "invalid amount %s\n", *amount)
+		return 2
+	}
+	now := time.Now().UTC()
+	stan := fmt.Sprintf("%06d", rand.Intn(1000000))
+
+	request := iso8583.NewMessage(*mti)
+	fields := map[int]string{
+		iso8583.FieldPAN:				*pan,
+		iso8583.FieldProcessingCode:	*processingCode,
+		iso8583.FieldAmount:			fmt.Sprintf("%012d", value),
+		7:								now.Format("0102150405"),
+		11:								stan,
+		iso8583.FieldMcc:				*mcc,
+		iso8583.FieldPosEntryMode:		*entryMode,
+		37:								now.Format("060102") + stan,
+		iso8583.FieldCurrency:			*currency,
+	}
+	for number, field := range fields {
+		if _, ok := spec.Fields[number]; ok {
+			request.Set(number, []byte(field))
+		}
+	}
+	if *emvData != "" {
+		data, err := hex.DecodeString(*emvData)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "the emv data must be hex encoded")
+			return 2
+		}
+		request.Set(iso8583.FieldEMV, data)
+	}
+
+	data, err := spec.Pack(request)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error pack message: %v\n", err)
+		return 1
+	}
+
+	conn, err := net.DialTimeout("tcp", *addr, 5 * time.Second)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error connect %s: %v\n", *addr, err)
+		return 1
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(30 * time.Second))
+
+	printMessage(spec, request)
+	if err := spec.WriteFrame(conn, data); err != nil {
+		fmt.Fprintf(os.Stderr, "error send message: %v\n", err)
+		return 1
+	}
+	data, err = spec.ReadFrame(conn)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error read response: %v\n", err)
+		return 1
+	}
+	response, err := spec.Unpack(data)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error unpack response: %v\n", err)
+		return 1
+	}
+	fmt.Fprintln(os.Stdout)
+	printMessage(spec, response)
+	return 0
+}
+
+// About print the fields of a message, the binary fields hex encoded
+func printMessage(spec *iso8583.Spec, message *iso8583.Message) {
+	writer := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(writer, "MTI\t\t%s\n", message.MTI)
+	for _, number := range message.Numbers() {
+		field := spec.Fields[number]
+		value, _ := message.Get(number)
+		text := string(value)
+		if field.Encoding == iso8583.EncodingBinary {
+			text = fmt.Sprintf("%X", value)
+		}
+		fmt.Fprintf(writer, "DE%d\t%s\t%s\n", number, field.Name, text)
+	}
+	writer.Flush()
+}
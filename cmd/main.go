@@ -1,18 +1,25 @@
 package main
 
 import(
+	"os"
 	"time"
+	"errors"
 	"context"
+	"io/fs"
 	
 	"github.com/rs/zerolog"
-	"github.com/rs/zerolog/log"
 
 	"github.com/go-card/internal/infra/configuration"
 	"github.com/go-card/internal/core/model"
+	"github.com/go-card/internal/core/hsm"
+	"github.com/go-card/internal/core/iso8583"
 	"github.com/go-card/internal/core/service"
 	"github.com/go-card/internal/infra/server"
 	"github.com/go-card/internal/adapter/api"
 	"github.com/go-card/internal/adapter/database"
+	card_grpc "github.com/go-card/internal/adapter/grpc"
+	iso8583_adapter "github.com/go-card/internal/adapter/iso8583"
+	"github.com/go-card/internal/infra/logger"
 
 	go_core_pg "github.com/eliezerraj/go-core/database/pg"
 	go_core_api "github.com/eliezerraj/go-core/api"
@@ -20,10 +27,12 @@ import(
 
 var(
 	logLevel = zerolog.InfoLevel // zerolog.InfoLevel zerolog.DebugLevel
-	childLogger = log.With().Str("component","go-card").Str("package", "main").Logger()
+	childLogger = logger.With().Str("component","go-card").Str("package", "main").Logger()
 	appServer			model.AppServer
 	databaseConfig 		go_core_pg.DatabaseConfig
 	databasePGServer 	go_core_pg.DatabasePGServer
+	cardHSM				hsm.HSM
+	isoSpec				*iso8583.Spec
 )
 
 // Above init
@@ -31,27 +40,91 @@ func init(){
 	childLogger.Info().Str("func","init").Send()
 	
 	zerolog.SetGlobalLevel(logLevel)
+}
+
+// About load the application configuration
+func loadAppServer(){
+	childLogger.Info().Str("func","loadAppServer").Send()
 
 	infoPod, server := configuration.GetInfoPod()
 	configOTEL 		:= configuration.GetOtelEnv()
 	databaseConfig 	:= configuration.GetDatabaseEnv() 
-	apiService 		:= configuration.GetEndpointEnv() 
+
+	apiService, err := configuration.GetEndpointEnv()
+	if err != nil {
+		childLogger.Error().Err(err).Msg("fatal error invalid configuration")
+		panic(err)
+	}
+	rateLimit, err := configuration.GetRateLimitEnv()
+	if err != nil {
+		childLogger.Error().Err(err).Msg("fatal error invalid configuration")
+		panic(err)
+	}
+	featureFlags, err := configuration.GetFeatureFlagEnv()
+	if err != nil {
+		childLogger.Error().Err(err).Msg("fatal error invalid configuration")
+		panic(err)
+	}
+	accountCache, err := configuration.GetAccountCacheEnv()
+	if err != nil {
+		childLogger.Error().Err(err).Msg("fatal error invalid configuration")
+		panic(err)
+	}
+	expiryJob, err := configuration.GetExpiryJobEnv()
+	if err != nil {
+		childLogger.Error().Err(err).Msg("fatal error invalid configuration")
+		panic(err)
+	}
+	isoSpec, err = configuration.GetIso8583SpecEnv()
+	if err != nil {
+		childLogger.Error().Err(err).Msg("fatal error invalid configuration")
+		panic(err)
+	}
+
+	// without master key or key file (not mounted yet) the pod starts with the card keys disabled,
+	// a key store present but invalid is still fatal
+	keyStore, masterKey, err := configuration.GetKeyStoreEnv()
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		childLogger.Warn().Err(err).Msg("key store not found, the card issuance, cvv, pin, dcvv and tokens are disabled")
+		cardHSM = &hsm.UnavailableHSM{Reason: err}
+	case err != nil:
+		childLogger.Error().Err(err).Msg("fatal error invalid key store")
+		panic(err)
+	default:
+		// the derived keys are encrypted under the master key, like the keys of the key file
+		cardHSM, err = hsm.NewSoftwareHSM(keyStore, masterKey)
+		clear(masterKey)
+		if err != nil {
+			childLogger.Error().Err(err).Msg("fatal error invalid key store")
+			panic(err)
+		}
+	}
 
 	appServer.InfoPod = &infoPod
 	appServer.Server = &server
 	appServer.ConfigOTEL = &configOTEL
 	appServer.DatabaseConfig = &databaseConfig
 	appServer.ApiService = apiService
-}
+	appServer.RateLimit = &rateLimit
+	appServer.FeatureFlags = featureFlags
+	appServer.AccountCache = &accountCache
+	appServer.ExpiryJob = &expiryJob
 
-// Above main
-func main (){
-	childLogger.Info().Str("func","main").Interface("appServer",appServer).Send()
+	err = configuration.ValidateAppServer(appServer)
+	if err != nil {
+		childLogger.Error().Err(err).Msg("fatal error invalid configuration")
+		panic(err)
+	}
 
-	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
+	logLevel, _ = zerolog.ParseLevel(server.LogLevel)
+	zerolog.SetGlobalLevel(logLevel)
+}
+
+// About open the database, 3 attempts before aborting
+func openDatabase(ctx context.Context) {
+	childLogger.Info().Str("func","openDatabase").Send()
 
-	// Open Database
 	count := 1
 	var err error
 	for {
@@ -69,6 +142,11 @@ func main (){
 		}
 		break
 	}
+}
+
+// About wire the repository and the worker service
+func newWorkerService() *service.WorkerService {
+	childLogger.Info().Str("func","newWorkerService").Send()
 
 	// Create a go-core api service for client http
 	coreRestApiService := go_core_api.NewRestApiService()
@@ -77,17 +155,58 @@ func main (){
 	database := database.NewWorkerRepository(&databasePGServer)
 	workerService := service.NewWorkerService(	*coreRestApiService,
 												database, 
-												appServer.ApiService)
+												appServer.ApiService,
+												*appServer.AccountCache,
+												cardHSM)
+	workerService.SetFeatureFlags(appServer.FeatureFlags)
+
+	return workerService
+}
+
+// Above main
+func main (){
+	// commands (ex: go-card config validate)
+	if len(os.Args) > 1 {
+		os.Exit(runCommand(os.Args[1:]))
+	}
+
+	loadAppServer()
+	childLogger.Info().Str("func","main").Interface("appServer",appServer).Send()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Open Database
+	openDatabase(ctx)
+
+	// wire
+	workerService := newWorkerService()
 	httpRouters := api.NewHttpRouters(workerService, time.Duration(appServer.Server.CtxTimeout))
 
 	// Services Health Check
-	err = workerService.HealthCheck(ctx)
+	err := workerService.HealthCheck(ctx)
 	if err != nil {
 		childLogger.Error().Err(err).Msg("fatal error health check aborting")
 	} else {
 		childLogger.Info().Msg("SERVICES HEALTH CHECK OK")
 	}
 	
+	// start grpc server (internal callers)
+	grpcServer := server.NewGrpcAppServer(appServer.Server, card_grpc.NewCardServer(workerService, httpRouters.CtxTimeout))
+	go grpcServer.StartGrpcAppServer()
+	defer grpcServer.StopGrpcAppServer()
+
+	// start iso 8583 server (acquirer, optional)
+	isoServer := server.NewIsoAppServer(appServer.Server, isoSpec, iso8583_adapter.NewAuthorizationHandler(workerService, httpRouters.CtxTimeout, isoSpec))
+	go isoServer.StartIsoAppServer()
+	defer isoServer.StopIsoAppServer()
+
+	// start expiry job scheduler (every pod, the chunks are locked with SKIP LOCKED)
+	go workerService.ScheduleExpiryJob(ctx, *appServer.ExpiryJob)
+
+	// start authorization partitions scheduler (every pod, the function is serialized by an advisory lock)
+	go workerService.ScheduleAuthorizationPartitions(ctx)
+
 	// start server
 	httpServer := server.NewHttpAppServer(appServer.Server)
 	httpServer.StartHttpAppServer(ctx, &httpRouters, &appServer)
@@ -0,0 +1,592 @@
+package api
+
+import (
+	"sort"
+	"sync"
+	"strconv"
+	"net/http"
+	"encoding/json"
+
+	_ "embed"
+)
+
+//go:embed docs.html
+var docsPage []byte
+
+// About a route registered in the router
+type Route struct {
+	Method	string
+	Path	string
+}
+
+func (r Route) String() string {
+	return r.Method + " " + r.Path
+}
+
+type object = map[string]interface{}
+
+// About the OpenAPI 3 document, built from the routes of the router
+type OpenAPI struct {
+	mutex	sync.RWMutex
+	spec	[]byte
+}
+
+// About build the document, only the registered routes are documented
+// Returns the routes without an entry in operations (they must be documented)
+func (o *OpenAPI) Build(routes []Route, version string) []string {
+	missing := []string{}
+	paths := object{}
+
+	for _, route := range routes {
+		operation, ok := operations[route.String()]
+		if !ok {
+			missing = append(missing, route.String())
+			continue
+		}
+		path, ok := paths[route.Path].(object)
+		if !ok {
+			path = object{}
+			paths[route.Path] = path
+		}
+		path[lowerMethod(route.Method)] = operation
+	}
+	sort.Strings(missing)
+
+	spec, err := json.Marshal(object{
+		"openapi": "3.0.3",
+		"info": object{
+			"title":		"go-card",
+			"description":	"Card issuing, ATC and tokenization",
+			"version":		version,
+		},
+		"paths": paths,
+		"components": object{
+			"schemas": schemas,
+		},
+	})
+	if err != nil {
+		childLogger.Error().Err(err).Msg("error build openapi")
+		return missing
+	}
+
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+	o.spec = spec
+
+	return missing
+}
+
+// About serve the document at /openapi.json
+func (o *OpenAPI) Handler(rw http.ResponseWriter, req *http.Request) {
+	o.mutex.RLock()
+	defer o.mutex.RUnlock()
+
+	rw.Header().Set("Content-Type", "application/json")
+	rw.Write(o.spec)
+}
+
+// About serve the docs page, it renders /openapi.json
+func DocsHandler(rw http.ResponseWriter, req *http.Request) {
+	rw.Header().Set("Content-Type", "text/html; charset=utf-8")
+	rw.Write(docsPage)
+}
+
+func lowerMethod(method string) string {
+	switch method {
+	case http.MethodGet:
+		return "get"
+	case http.MethodPost:
+		return "post"
+	case http.MethodPut:
+		return "put"
+	case http.MethodPatch:
+		return "patch"
+	case http.MethodDelete:
+		return "delete"
+	default:
+		return method
+	}
+}
+
+func ref(schema string) object {
+	return object{"$ref": "#/components/schemas/" + schema}
+}
+
+func jsonContent(schema object) object {
+	return object{"application/json": object{"schema": schema}}
+}
+
+func jsonResponse(description string, schema object) object {
+	return object{"description": description, "content": jsonContent(schema)}
+}
+
+func requestBody(schema string) object {
+	return object{"required": true, "content": jsonContent(ref(schema))}
+}
+
+func pathParameter(name string, description string) object {
+	return object{"name": name, "in": "path", "required": true, "description": description, "schema": object{"type": "string"}}
+}
+
+func queryParameter(name string, description string, schema object) object {
+	return object{"name": name, "in": "query", "description": description, "schema": schema}
+}
+
+// About the success response plus the problem responses of the status codes
+func responses(status string, success object, problems ...string) object {
+	res := object{status: success}
+	for _, problem := range problems {
+		code, _ := strconv.Atoi(problem)
+		res[problem] = object{
+			"description":	http.StatusText(code),
+			"content":		object{problemContentType: object{"schema": ref("Problem")}},
+		}
+	}
+	return res
+}
+
+// About the operations of every route, keyed by "METHOD path"
+var operations = map[string]object{
+	"GET /": {
+		"tags": []string{"infra"}, "summary": "Show the running configuration",
+		"responses": responses("200", jsonResponse("running configuration", object{"type": "object"})),
+	},
+	"GET /info": {
+		"tags": []string{"infra"}, "summary": "Show the running configuration",
+		"responses": responses("200", jsonResponse("running configuration", object{"type": "object"})),
+	},
+	"GET /metrics": {
+		"tags": []string{"infra"}, "summary": "Prometheus metrics",
+		"responses": object{"200": object{"description": "metrics in the prometheus text format", "content": object{"text/plain": object{"schema": object{"type": "string"}}}}},
+	},
+	"GET /openapi.json": {
+		"tags": []string{"infra"}, "summary": "This OpenAPI document",
+		"responses": responses("200", jsonResponse("OpenAPI 3 document", object{"type": "object"})),
+	},
+	"GET /docs": {
+		"tags": []string{"infra"}, "summary": "Docs page of this OpenAPI document",
+		"responses": object{"200": object{"description": "html page", "content": object{"text/html": object{"schema": object{"type": "string"}}}}},
+	},
+	"GET /health": {
+		"tags": []string{"infra"}, "summary": "Health",
+		"responses": responses("200", jsonResponse("healthy", ref("MessageRouter"))),
+	},
+	"GET /live": {
+		"tags": []string{"infra"}, "summary": "Liveness",
+		"responses": responses("200", jsonResponse("alive", ref("MessageRouter"))),
+	},
+	"GET /ready": {
+		"tags": []string{"infra"}, "summary": "Readiness of the dependencies",
+		"responses": object{
+			"200": jsonResponse("ready (UP or DEGRADED)", ref("Readiness")),
+			"503": jsonResponse("a critical dependency is DOWN", ref("Readiness")),
+		},
+	},
+	"GET /header": {
+		"tags": []string{"infra"}, "summary": "Show the received headers",
+		"responses": responses("200", jsonResponse("headers", object{"type": "object", "additionalProperties": object{"type": "array", "items": object{"type": "string"}}})),
+	},
+	"GET /stat": {
+		"tags": []string{"infra"}, "summary": "Database pool stats",
+		"responses": responses("200", jsonResponse("pool stats", ref("PoolStats"))),
+	},
+	"GET /context": {
+		"tags": []string{"infra"}, "summary": "Show the request context values",
+		"responses": responses("200", jsonResponse("context values", object{"type": "string"})),
+	},
+	"POST /card": {
+		"tags": []string{"legacy"}, "summary": "Issue a card (use POST /v1/cards)", "deprecated": true,
+		"requestBody": requestBody("CardRequest"),
+		"responses": responses("200", jsonResponse("card issued", ref("Card")), "400", "404", "429", "502", "503", "504", "500"),
+	},
+	"GET /card/{id}": {
+		"tags": []string{"legacy"}, "summary": "Get a card by its card number (use GET /v1/cards/{id})", "deprecated": true,
+		"parameters": []object{pathParameter("id", "card number")},
+		"responses": responses("200", jsonResponse("card", ref("Card")), "404", "429", "502", "503", "504", "500"),
+	},
+	"POST /atc": {
+		"tags": []string{"legacy"}, "summary": "Increment the ATC of a card (use POST /v1/cards/{id}/atc)", "deprecated": true,
+		"requestBody": requestBody("CardNumberRequest"),
+		"responses": responses("200", jsonResponse("card with the new ATC", ref("Card")), "400", "404", "409", "429", "504", "500"),
+	},
+	"GET /cardToken/{token}": {
+		"tags": []string{"legacy"}, "summary": "Get the cards of a token (use GET /v1/tokens/{token})", "deprecated": true,
+		"parameters": []object{pathParameter("token", "token")},
+		"responses": responses("200", jsonResponse("cards", object{"type": "array", "items": ref("Card")}), "429", "504", "500"),
+	},
+	"POST /cardToken": {
+		"tags": []string{"legacy"}, "summary": "Create a token of a card (use POST /v1/cards/{id}/tokens)", "deprecated": true,
+		"requestBody": requestBody("CardNumberRequest"),
+		"responses": responses("200", jsonResponse("card with the token", ref("Card")), "400", "404", "429", "504", "500"),
+	},
+	"POST /v1/cards/{id}/reissue": {
+		"tags": []string{"card"}, "summary": "Reissue a card, the card is canceled and replaced by a new one", "operationId": "reissueCard",
+		"description": "LOST and STOLEN get a new PAN, DAMAGED and RENEWAL keep the PAN with a new expiry. The active tokens move to the new card, except for STOLEN (canceled).",
+		"parameters": []object{pathParameter("id", "card number")},
+		"requestBody": requestBody("CardReissueRequest"),
+		"responses": responses("200", jsonResponse("new card", ref("Card")), "400", "404", "409", "429", "504", "500"),
+	},
+	"POST /v1/cards/{id}/cvv/verify": {
+		"tags": []string{"card"}, "summary": "Verify the cvv2 of a card", "operationId": "verifyCvv",
+		"description": "A failed verification increments the lockout counter of the card, the card is locked (423 CARD_LOCKED) after 3 failures. A success resets the counter.",
+		"parameters": []object{pathParameter("id", "card number")},
+		"requestBody": requestBody("CvvVerifyRequest"),
+		"responses": responses("200", jsonResponse("verification result", ref("CvvVerification")), "400", "404", "409", "423", "429", "504", "500"),
+	},
+	"GET /v1/cards/{id}/dcvv": {
+		"tags": []string{"card"}, "summary": "Get the dynamic cvv of a virtual card", "operationId": "getDcvv",
+		"description": "The code changes every 5 minutes (expires_at), only the VIRTUAL cards have a dynamic cvv (409 CARD_NOT_VIRTUAL).",
+		"parameters": []object{pathParameter("id", "card number")},
+		"responses": responses("200", jsonResponse("dynamic cvv of the current window", ref("DynamicCvv")), "400", "404", "409", "429", "504", "500"),
+	},
+	"POST /v1/cards/{id}/dcvv/verify": {
+		"tags": []string{"card"}, "summary": "Verify the dynamic cvv of a virtual card", "operationId": "verifyDcvv",
+		"description": "The current and the previous windows are accepted, the failures share the lockout counter of the cvv2 (423 CARD_LOCKED).",
+		"parameters": []object{pathParameter("id", "card number")},
+		"requestBody": requestBody("DcvvVerifyRequest"),
+		"responses": responses("200", jsonResponse("verification result", ref("CvvVerification")), "400", "404", "409", "423", "429", "504", "500"),
+	},
+	"POST /v1/cards/{id}/pin": {
+		"tags": []string{"pin"}, "summary": "Set the first pin of a card", "operationId": "setPin",
+		"description": "The pin is sent in an ISO 9564 format 0 (TDES) or format 4 (AES) pin block encrypted under the zone pin key, only the Visa PVV is stored.",
+		"parameters": []object{pathParameter("id", "card number")},
+		"requestBody": requestBody("PinRequest"),
+		"responses": responses("200", jsonResponse("pin set", ref("MessageRouter")), "400", "404", "409", "423", "429", "504", "500"),
+	},
+	"PUT /v1/cards/{id}/pin": {
+		"tags": []string{"pin"}, "summary": "Change the pin of a card", "operationId": "changePin",
+		"description": "The current pin must be verified, a wrong pin (403 PIN_INVALID) counts as a failure, the card is blocked (423 CARD_BLOCKED) after 3 wrong pins.",
+		"parameters": []object{pathParameter("id", "card number")},
+		"requestBody": requestBody("PinChangeRequest"),
+		"responses": responses("200", jsonResponse("pin changed", ref("MessageRouter")), "400", "403", "404", "409", "423", "429", "504", "500"),
+	},
+	"POST /v1/cards/{id}/pin/verify": {
+		"tags": []string{"pin"}, "summary": "Verify the pin of a card", "operationId": "verifyPin",
+		"description": "A wrong pin increments the counter of the card, the card is blocked (423 CARD_BLOCKED) after 3 wrong pins. A right pin resets the counter.",
+		"parameters": []object{pathParameter("id", "card number")},
+		"requestBody": requestBody("PinRequest"),
+		"responses": responses("200", jsonResponse("verification result", ref("PinVerification")), "400", "404", "409", "423", "429", "504", "500"),
+	},
+	"GET /v1/cards/{id}/controls": {
+		"tags": []string{"control"}, "summary": "Get the spending controls of a card", "operationId": "getControls",
+		"description": "A card without controls has no limit and every channel enabled.",
+		"parameters": []object{pathParameter("id", "card number")},
+		"responses": responses("200", jsonResponse("controls with the usage of the day and month", ref("CardControl")), "400", "404", "429", "504", "500"),
+	},
+	"PUT /v1/cards/{id}/controls": {
+		"tags": []string{"control"}, "summary": "Replace the spending controls of a card", "operationId": "updateControls",
+		"description": "Every control is replaced, a missing limit is no limit. The usage counters are kept.",
+		"parameters": []object{pathParameter("id", "card number")},
+		"requestBody": requestBody("CardControl"),
+		"responses": responses("200", jsonResponse("controls", ref("CardControl")), "400", "404", "409", "429", "504", "500"),
+	},
+	"POST /v1/cards/{id}/controls/evaluate": {
+		"tags": []string{"control"}, "summary": "Evaluate a transaction against the spending controls of a card", "operationId": "evaluateControls",
+		"description": "Nothing is recorded. The decision is DECLINE with every failed control as a reason, or APPROVE.",
+		"parameters": []object{pathParameter("id", "card number")},
+		"requestBody": requestBody("CardTransaction"),
+		"responses": responses("200", jsonResponse("decision", ref("ControlEvaluation")), "400", "404", "429", "504", "500"),
+	},
+	"POST /v1/cards/{id}/authorize": {
+		"tags": []string{"authorization"}, "summary": "Authorize a transaction of a card", "operationId": "authorize",
+		"description": "In one transaction: the status and expiry of the card, the atc (tag 9F36 of the emv data, or the next atc without emv data), the spending controls. Every attempt is recorded, a decline is a 200 with its ISO 8583 response code.",
+		"parameters": []object{pathParameter("id", "card number")},
+		"requestBody": requestBody("AuthorizeRequest"),
+		"responses": responses("200", jsonResponse("authorization, approved or declined", ref("Authorization")), "400", "429", "504", "500"),
+	},
+	"GET /v1/cards/{id}/authorizations": {
+		"tags": []string{"authorization"}, "summary": "List the authorizations of a card", "operationId": "listAuthorizations",
+		"description": "Every authorization attempt of the card (approved or declined), from the newest. The next page is read with the next_cursor of the page.",
+		"parameters": []object{
+			pathParameter("id", "card number"),
+			queryParameter("from", "date (start of the day, UTC) or date-time, default 30 days before to", object{"type": "string"}),
+			queryParameter("to", "date (included) or date-time (excluded), default now, at most 92 days after from", object{"type": "string"}),
+			queryParameter("limit", "authorizations by page", object{"type": "integer", "minimum": 1, "maximum": 200, "default": 50}),
+			queryParameter("cursor", "next_cursor of the previous page, with the same from and to", object{"type": "string"}),
+		},
+		"responses": responses("200", jsonResponse("a page of authorizations, from the newest", ref("AuthorizationPage")), "400", "404", "429", "504", "500"),
+	},
+	"POST /v1/cards": {
+		"tags": []string{"card"}, "summary": "Issue a card", "operationId": "addCard",
+		"requestBody": requestBody("CardRequest"),
+		"responses": responses("200", jsonResponse("card issued", ref("Card")), "400", "404", "429", "502", "503", "504", "500"),
+	},
+	"GET /v1/cards/{id}": {
+		"tags": []string{"card"}, "summary": "Get a card by its card number", "operationId": "getCard",
+		"parameters": []object{pathParameter("id", "card number")},
+		"responses": responses("200", jsonResponse("card", ref("Card")), "404", "429", "502", "503", "504", "500"),
+	},
+	"POST /v1/cards/{id}/atc": {
+		"tags": []string{"card"}, "summary": "Increment the ATC of a card", "operationId": "incrementAtc",
+		"parameters": []object{pathParameter("id", "card number")},
+		"responses": responses("200", jsonResponse("card with the new ATC", ref("Card")), "400", "404", "409", "429", "504", "500"),
+	},
+	"POST /v1/cards/{id}/tokens": {
+		"tags": []string{"token"}, "summary": "Create a token of a card", "operationId": "createToken",
+		"parameters": []object{pathParameter("id", "card number")},
+		"responses": responses("200", jsonResponse("card with the token", ref("Card")), "400", "404", "429", "504", "500"),
+	},
+	"GET /v1/tokens/{token}": {
+		"tags": []string{"token"}, "summary": "Get the cards of a token", "operationId": "getToken",
+		"parameters": []object{pathParameter("token", "token")},
+		"responses": responses("200", jsonResponse("cards", object{"type": "array", "items": ref("Card")}), "429", "504", "500"),
+	},
+	"DELETE /admin/cache/account": {
+		"tags": []string{"admin"}, "summary": "Purge the account cache",
+		"responses": responses("200", jsonResponse("purged entries", ref("Purged")), "401", "403", "429"),
+	},
+	"DELETE /admin/cache/account/{id}": {
+		"tags": []string{"admin"}, "summary": "Purge an account_id from the account cache",
+		"parameters": []object{pathParameter("id", "account_id")},
+		"responses": responses("200", jsonResponse("purged entries", ref("Purged")), "401", "403", "429"),
+	},
+}
+
+// About the schemas of model.Card, the problem and the infra payloads
+var schemas = object{
+	"Card": object{
+		"type": "object",
+		"properties": object{
+			"id":				object{"type": "integer"},
+			"fk_account_id":	object{"type": "integer"},
+			"account_id":		object{"type": "string"},
+			"card_number":		object{"type": "string"},
+			"token_data":		object{"type": "string"},
+			"holder":			object{"type": "string"},
+			"type":				object{"type": "string", "enum": []string{"CREDIT", "DEBIT", "PREPAID"}},
+			"model":			object{"type": "string", "enum": []string{"CHIP", "CONTACTLESS", "VIRTUAL"}},
+			"atc":				object{"type": "integer"},
+			"status":			object{"type": "string"},
+			"expired_at":		object{"type": "string", "format": "date-time"},
+			"created_at":		object{"type": "string", "format": "date-time"},
+			"updated_at":		object{"type": "string", "format": "date-time", "nullable": true},
+			"tenant_id":		object{"type": "string"},
+			"predecessor_id":	object{"type": "integer", "description": "id of the card replaced by this one"},
+			"reissue_reason":	object{"type": "string", "enum": []string{"LOST", "STOLEN", "DAMAGED", "RENEWAL"}},
+			"cvv2":				object{"type": "string", "readOnly": true, "description": "only returned when the card is issued or reissued, it is never stored. Missing when the pan is not eligible or the HSM is unavailable (cvv2 pending)"},
+		},
+	},
+	"CvvVerifyRequest": object{
+		"type": "object",
+		"required": []string{"cvv2"},
+		"properties": object{
+			"cvv2":	object{"type": "string", "pattern": "^[0-9]{3}$"},
+		},
+	},
+	"DcvvVerifyRequest": object{
+		"type": "object",
+		"required": []string{"dcvv"},
+		"properties": object{
+			"dcvv":	object{"type": "string", "pattern": "^[0-9]{3}$"},
+		},
+	},
+	"DynamicCvv": object{
+		"type": "object",
+		"properties": object{
+			"dcvv":			object{"type": "string"},
+			"expires_at":	object{"type": "string", "format": "date-time"},
+		},
+	},
+	"PinRequest": object{
+		"type": "object",
+		"required": []string{"pin_block"},
+		"properties": object{
+			"pin_block":	object{"type": "string", "pattern": "^[0-9A-Fa-f]{16}([0-9A-Fa-f]{16})?$", "description": "encrypted pin block, 8 bytes (format 0) or 16 bytes (format 4) hex encoded"},
+			"format":		object{"type": "integer", "enum": []int{0, 4}, "default": 0},
+		},
+	},
+	"PinChangeRequest": object{
+		"type": "object",
+		"required": []string{"pin_block", "new_pin_block"},
+		"properties": object{
+			"pin_block":		object{"type": "string", "pattern": "^[0-9A-Fa-f]{16}([0-9A-Fa-f]{16})?$", "description": "current pin"},
+			"new_pin_block":	object{"type": "string", "pattern": "^[0-9A-Fa-f]{16}([0-9A-Fa-f]{16})?$", "description": "new pin"},
+			"format":			object{"type": "integer", "enum": []int{0, 4}, "default": 0},
+		},
+	},
+	"PinVerification": object{
+		"type": "object",
+		"properties": object{
+			"verified":			object{"type": "boolean"},
+			"attempts_left":	object{"type": "integer"},
+		},
+	},
+	"CvvVerification": object{
+		"type": "object",
+		"properties": object{
+			"verified":			object{"type": "boolean"},
+			"attempts_left":	object{"type": "integer"},
+		},
+	},
+	"CardControl": object{
+		"type": "object",
+		"required": []string{"currency", "ecommerce_enabled", "international_enabled"},
+		"properties": object{
+			"currency":					object{"type": "string", "pattern": "^[A-Z]{3}$", "description": "ISO 4217 currency of the limits, a transaction in another currency is international"},
+			"transaction_limit":		object{"type": "integer", "format": "int64", "minimum": 0, "description": "minor units of the currency (ex: 12050 is 120.50), no limit when missing"},
+			"daily_limit":				object{"type": "integer", "format": "int64", "minimum": 0},
+			"monthly_limit":			object{"type": "integer", "format": "int64", "minimum": 0},
+			"mcc_allow":				object{"type": "array", "items": object{"type": "string", "pattern": "^[0-9]{4}$"}, "description": "every mcc is allowed when empty"},
+			"mcc_deny":					object{"type": "array", "items": object{"type": "string", "pattern": "^[0-9]{4}$"}},
+			"ecommerce_enabled":		object{"type": "boolean"},
+			"international_enabled":	object{"type": "boolean"},
+			"atm_transaction_limit":	object{"type": "integer", "format": "int64", "minimum": 0},
+			"atm_daily_limit":			object{"type": "integer", "format": "int64", "minimum": 0},
+			"usage":					object{"allOf": []object{ref("ControlUsage")}, "readOnly": true},
+			"updated_at":				object{"type": "string", "format": "date-time", "readOnly": true},
+		},
+	},
+	"ControlUsage": object{
+		"type": "object",
+		"description": "amounts spent in the day (UTC) and in its month, in minor units",
+		"properties": object{
+			"date":				object{"type": "string", "format": "date-time"},
+			"daily_spent":		object{"type": "integer", "format": "int64"},
+			"monthly_spent":	object{"type": "integer", "format": "int64"},
+			"atm_daily_spent":	object{"type": "integer", "format": "int64"},
+		},
+	},
+	"CardTransaction": object{
+		"type": "object",
+		"required": []string{"amount", "currency", "mcc", "channel"},
+		"properties": object{
+			"amount":	object{"type": "integer", "format": "int64", "minimum": 1, "description": "minor units of the currency (ex: 12050 is 120.50), compared with the limits without currency conversion"},
+			"currency":	object{"type": "string", "pattern": "^[A-Z]{3}$"},
+			"mcc":		object{"type": "string", "pattern": "^[0-9]{4}$"},
+			"channel":	object{"type": "string", "enum": []string{"POS", "ECOMMERCE", "ATM"}},
+		},
+	},
+	"ControlEvaluation": object{
+		"type": "object",
+		"properties": object{
+			"decision":	object{"type": "string", "enum": []string{"APPROVE", "DECLINE"}},
+			"reasons":	object{"type": "array", "items": object{"type": "string", "enum": []string{
+				"TRANSACTION_LIMIT_EXCEEDED", "DAILY_LIMIT_EXCEEDED", "MONTHLY_LIMIT_EXCEEDED", "MCC_NOT_ALLOWED", "MCC_DENIED",
+				"ECOMMERCE_DISABLED", "INTERNATIONAL_DISABLED", "ATM_TRANSACTION_LIMIT_EXCEEDED", "ATM_DAILY_LIMIT_EXCEEDED"}}},
+		},
+	},
+	"AuthorizeRequest": object{
+		"type": "object",
+		"required": []string{"amount", "currency", "mcc", "channel"},
+		"properties": object{
+			"amount":	object{"type": "integer", "format": "int64", "minimum": 1, "description": "minor units of the currency (ex: 12050 is 120.50)"},
+			"currency":	object{"type": "string", "pattern": "^[A-Z]{3}$"},
+			"mcc":		object{"type": "string", "pattern": "^[0-9]{4}$"},
+			"channel":	object{"type": "string", "enum": []string{"POS", "ECOMMERCE", "ATM"}},
+			"emv":		object{"type": "string", "pattern": "^[0-9A-Fa-f]*$", "maxLength": 512, "description": "BER-TLV chip data (DE55) hex encoded, the atc is the tag 9F36"},
+			"merchant_id":		object{"type": "string", "maxLength": 15},
+			"merchant_name":	object{"type": "string", "maxLength": 40},
+		},
+	},
+	"Authorization": object{
+		"type": "object",
+		"properties": object{
+			"id":				object{"type": "integer", "description": "missing when the card is not found"},
+			"amount":			object{"type": "integer", "format": "int64", "description": "minor units of the currency (ex: 12050 is 120.50)"},
+			"currency":			object{"type": "string"},
+			"mcc":				object{"type": "string"},
+			"channel":			object{"type": "string"},
+			"merchant_id":		object{"type": "string"},
+			"merchant_name":	object{"type": "string"},
+			"atc":				object{"type": "integer", "description": "atc of the transaction, missing when it is not verified"},
+			"response_code":	object{"type": "string", "description": "ISO 8583 DE39: 00 approved, 05 do not honor (atc), 14 invalid card, 30 format error (emv), 54 expired card, 57 not permitted, 61 exceeds limit, 62 restricted card"},
+			"decision":			object{"type": "string", "enum": []string{"APPROVE", "DECLINE"}},
+			"reasons":			object{"type": "array", "items": object{"type": "string"}},
+			"trace_id":			object{"type": "string"},
+			"created_at":		object{"type": "string", "format": "date-time"},
+		},
+	},
+	"AuthorizationPage": object{
+		"type": "object",
+		"properties": object{
+			"authorizations":	object{"type": "array", "items": ref("Authorization")},
+			"next_cursor":		object{"type": "string", "description": "missing on the last page"},
+		},
+	},
+	"CardReissueRequest": object{
+		"type": "object",
+		"required": []string{"reason"},
+		"properties": object{
+			"reason":	object{"type": "string", "enum": []string{"LOST", "STOLEN", "DAMAGED", "RENEWAL"}},
+		},
+	},
+	"CardRequest": object{
+		"type": "object",
+		"required": []string{"account_id", "card_number", "holder", "type", "model"},
+		"properties": object{
+			"account_id":	object{"type": "string"},
+			"card_number":	object{"type": "string", "pattern": `^[0-9][0-9 .\-]*[0-9]$`, "description": "13 to 19 digits with a valid luhn check digit"},
+			"holder":		object{"type": "string", "minLength": 2, "maxLength": 26, "pattern": `^[A-Za-z][A-Za-z0-9 .'\-/]*$`},
+			"type":			object{"type": "string", "enum": []string{"CREDIT", "DEBIT", "PREPAID"}},
+			"model":		object{"type": "string", "enum": []string{"CHIP", "CONTACTLESS", "VIRTUAL"}},
+			"status":		object{"type": "string"},
+			"tenant_id":	object{"type": "string"},
+		},
+	},
+	"CardNumberRequest": object{
+		"type": "object",
+		"required": []string{"card_number"},
+		"properties": object{
+			"card_number":	object{"type": "string", "maxLength": 23, "pattern": `^[0-9][0-9 .\-]*[0-9]$`},
+			"tenant_id":	object{"type": "string"},
+		},
+	},
+	"Problem": object{
+		"type": "object",
+		"description": "RFC 7807 problem details (application/problem+json)",
+		"required": []string{"type", "title", "status", "code"},
+		"properties": object{
+			"type":		object{"type": "string"},
+			"title":	object{"type": "string"},
+			"status":	object{"type": "integer"},
+			"detail":	object{"type": "string"},
+			"instance":	object{"type": "string"},
+			"code":		object{"type": "string", "description": "stable error code (ex: NOT_FOUND, VALIDATION_FAILED)"},
+			"trace_id":	object{"type": "string"},
+			"errors":	object{"type": "array", "items": ref("FieldError")},
+		},
+	},
+	"FieldError": object{
+		"type": "object",
+		"properties": object{
+			"field":	object{"type": "string"},
+			"code":		object{"type": "string"},
+			"message":	object{"type": "string"},
+		},
+	},
+	"MessageRouter": object{
+		"type": "object",
+		"properties": object{
+			"message":	object{"type": "string"},
+		},
+	},
+	"Readiness": object{
+		"type": "object",
+		"properties": object{
+			"status":		object{"type": "string", "enum": []string{"UP", "DEGRADED", "DOWN"}},
+			"checked_at":	object{"type": "string", "format": "date-time"},
+			"dependencies":	object{"type": "array", "items": ref("DependencyStatus")},
+		},
+	},
+	"DependencyStatus": object{
+		"type": "object",
+		"properties": object{
+			"name":			object{"type": "string"},
+			"status":		object{"type": "string", "enum": []string{"UP", "DEGRADED", "DOWN"}},
+			"critical":		object{"type": "boolean"},
+			"latency_ms":	object{"type": "integer"},
+			"error":		object{"type": "string"},
+			"details":		object{"type": "object", "additionalProperties": object{"type": "string"}},
+		},
+	},
+	"PoolStats": object{
+		"type": "object",
+		"additionalProperties": object{"type": "integer"},
+	},
+	"Purged": object{
+		"type": "object",
+		"properties": object{
+			"purged":	object{"type": "integer"},
+		},
+	},
+}
@@ -0,0 +1,125 @@
+package api
+
+import (
+	"fmt"
+	"errors"
+	"context"
+	"strings"
+	"net/http"
+	"encoding/json"
+
+	"github.com/go-card/internal/core/erro"
+)
+
+const problemContentType = "application/problem+json"
+
+// About a RFC 7807 problem details body
+type Problem struct {
+	Type		string 				`json:"type"`
+	Title		string 				`json:"title"`
+	Status		int 				`json:"status"`
+	Detail		string 				`json:"detail,omitempty"`
+	Instance	string 				`json:"instance,omitempty"`
+	Code		string 				`json:"code"`
+	TraceID		string 				`json:"trace_id,omitempty"`
+	Errors		[]erro.FieldError 	`json:"errors,omitempty"`
+}
+
+func (p *Problem) Error() string {
+	return p.Detail
+}
+
+// About the http status of each error of the taxonomy, err is the taxonomy error (see erro.Taxonomy)
+func problemStatus(err error) int {
+	switch {
+	case errors.Is(err, erro.ErrValidation), errors.Is(err, erro.ErrBadRequest):
+		return http.StatusBadRequest
+	case errors.Is(err, erro.ErrUnauthorized):
+		return http.StatusUnauthorized
+	case errors.Is(err, erro.ErrHTTPForbiden), errors.Is(err, erro.ErrPinInvalid):
+		return http.StatusForbidden
+	case errors.Is(err, erro.ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, erro.ErrUpdate), errors.Is(err, erro.ErrUpdateRows), errors.Is(err, erro.ErrCardCanceled), errors.Is(err, erro.ErrCardNotVirtual),
+		errors.Is(err, erro.ErrPinAlreadySet), errors.Is(err, erro.ErrPinNotSet):
+		return http.StatusConflict
+	case errors.Is(err, erro.ErrCardLocked), errors.Is(err, erro.ErrCardBlocked):
+		return http.StatusLocked
+	case errors.Is(err, erro.ErrTooManyRequests):
+		return http.StatusTooManyRequests
+	case errors.Is(err, erro.ErrDownstream):
+		return http.StatusBadGateway
+	case errors.Is(err, erro.ErrCircuitOpen), errors.Is(err, erro.ErrHealthCheck), errors.Is(err, erro.ErrServiceNotConfigured),
+		errors.Is(err, erro.ErrHSMUnavailable):
+		return http.StatusServiceUnavailable
+	case errors.Is(err, erro.ErrTimeout):
+		return http.StatusGatewayTimeout
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// About create the problem of an error
+// The detail is the fixed text of the taxonomy error, the wrapped text (ex: the cause of a downstream error)
+// is only logged, the detail of an error outside the taxonomy is not exposed
+func NewProblem(trace_id string, err error) *Problem {
+	if errors.Is(err, context.DeadlineExceeded) || strings.Contains(err.Error(), "context deadline exceeded") {
+		err = erro.ErrTimeout
+	}
+
+	taxonomy := erro.Taxonomy(err)
+	status := problemStatus(taxonomy)
+	code := erro.Code(err)
+
+	problem := &Problem{
+		Type:		"urn:go-card:problem:" + strings.ToLower(code),
+		Title:		http.StatusText(status),
+		Status:		status,
+		Code:		code,
+		TraceID:	trace_id,
+	}
+	if taxonomy == nil {
+		childLogger.Error().Err(err).Str("trace_id", trace_id).Msg("unmapped error")
+		problem.Detail = "internal server error"
+	} else {
+		if err != taxonomy {
+			childLogger.Warn().Err(err).Str("trace_id", trace_id).Str("code", code).Msg("problem")
+		}
+		problem.Detail = taxonomy.Error()
+	}
+
+	var validationError *erro.ValidationError
+	if errors.As(err, &validationError) {
+		problem.Errors = validationError.Fields
+	}
+
+	return problem
+}
+
+// About write a problem as application/problem+json
+func WriteProblem(rw http.ResponseWriter, req *http.Request, problem *Problem) {
+	if problem.Instance == "" {
+		problem.Instance = req.URL.Path
+	}
+	rw.Header().Set("Content-Type", problemContentType)
+	rw.WriteHeader(problem.Status)
+	if err := json.NewEncoder(rw).Encode(problem); err != nil {
+		childLogger.Error().Err(err).Msg("error encode problem")
+	}
+}
+
+// About adapt a handler returning an error, the error is written as a problem
+func MiddleWareErrorHandler(handler func(rw http.ResponseWriter, req *http.Request) error) http.HandlerFunc {
+	return func(rw http.ResponseWriter, req *http.Request) {
+		err := handler(rw, req)
+		if err == nil {
+			return
+		}
+
+		var problem *Problem
+		if !errors.As(err, &problem) {
+			problem = NewProblem(fmt.Sprintf("%v", req.Context().Value("trace-request-id")), err)
+		}
+		WriteProblem(rw, req, problem)
+	}
+}
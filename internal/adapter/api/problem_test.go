@@ -0,0 +1,85 @@
+package api
+
+import (
+	"fmt"
+	"errors"
+	"context"
+	"testing"
+	"net/http"
+	"encoding/json"
+	"net/http/httptest"
+
+	"github.com/go-card/internal/core/erro"
+)
+
+func TestNewProblem(t *testing.T) {
+	tests := []struct {
+		name	string
+		err		error
+		status	int
+		code	string
+		detail	string
+	}{
+		{"not found", erro.ErrNotFound, http.StatusNotFound, "NOT_FOUND", "item not found"},
+		{"bad request", fmt.Errorf("%w: the card has no cvv2", erro.ErrBadRequest), http.StatusBadRequest, "BAD_REQUEST", "bad request ! check parameters"},
+		{"unauthorized", erro.ErrUnauthorized, http.StatusUnauthorized, "UNAUTHORIZED", "not authorized"},
+		{"wrong pin", fmt.Errorf("%w: 2 attempts left", erro.ErrPinInvalid), http.StatusForbidden, "PIN_INVALID", "wrong pin"},
+		{"card canceled", erro.ErrCardCanceled, http.StatusConflict, "CARD_CANCELED", "card is canceled"},
+		{"card locked", erro.ErrCardLocked, http.StatusLocked, "CARD_LOCKED", "card is locked, too many failed verifications"},
+		{"too many requests", erro.ErrTooManyRequests, http.StatusTooManyRequests, "TOO_MANY_REQUESTS", "too many requests"},
+		{"downstream cause not exposed", fmt.Errorf("%w: service go-account in outage => cause error: dial tcp 10.0.0.1:5000", erro.ErrDownstream),
+			http.StatusBadGateway, "DOWNSTREAM_ERROR", "downstream service error"},
+		{"circuit open", erro.ErrCircuitOpen, http.StatusServiceUnavailable, "CIRCUIT_OPEN", "downstream service unavailable: circuit breaker open"},
+		{"hsm unavailable", erro.ErrHSMUnavailable, http.StatusServiceUnavailable, "HSM_UNAVAILABLE", "card keys unavailable, the hsm has no key store"},
+		{"deadline", fmt.Errorf("query: %w", context.DeadlineExceeded), http.StatusGatewayTimeout, "TIMEOUT", "timeout: context deadline exceeded"},
+		{"specific error first", fmt.Errorf("%w: %w", erro.ErrNotFound, erro.ErrCardCanceled), http.StatusConflict, "CARD_CANCELED", "card is canceled"},
+		{"outside the taxonomy", errors.New("pq: password authentication failed"), http.StatusInternalServerError, "INTERNAL_ERROR", "internal server error"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// the code must not depend on an iteration order
+			for i := 0; i < 10; i++ {
+				problem := NewProblem("trace-1", test.err)
+				if problem.Status != test.status || problem.Code != test.code || problem.Detail != test.detail {
+					t.Fatalf("problem %d %s %q, want %d %s %q", problem.Status, problem.Code, problem.Detail, test.status, test.code, test.detail)
+				}
+			}
+		})
+	}
+}
+
+func TestNewProblemValidation(t *testing.T) {
+	fields := []erro.FieldError{{Field: "holder", Code: "required", Message: "is required"}}
+	problem := NewProblem("trace-1", &erro.ValidationError{Fields: fields})
+
+	if problem.Status != http.StatusBadRequest || problem.Code != "VALIDATION_FAILED" || problem.Detail != erro.ErrValidation.Error() {
+		t.Errorf("problem %d %s %q, want 400 VALIDATION_FAILED", problem.Status, problem.Code, problem.Detail)
+	}
+	if len(problem.Errors) != 1 || problem.Errors[0] != fields[0] {
+		t.Errorf("errors %v, want %v", problem.Errors, fields)
+	}
+}
+
+func TestMiddleWareErrorHandler(t *testing.T) {
+	handler := MiddleWareErrorHandler(func(rw http.ResponseWriter, req *http.Request) error {
+		return erro.ErrNotFound
+	})
+
+	rec := httptest.NewRecorder()
+	handler(rec, httptest.NewRequest(http.MethodGet, "/v1/cards/4444000011112222", nil))
+
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("status %d, want %d", rec.Code, http.StatusNotFound)
+	}
+	if contentType := rec.Header().Get("Content-Type"); contentType != problemContentType {
+		t.Errorf("content type %s, want %s", contentType, problemContentType)
+	}
+	problem := Problem{}
+	if err := json.NewDecoder(rec.Body).Decode(&problem); err != nil {
+		t.Fatalf("decode problem: %v", err)
+	}
+	if problem.Type != "urn:go-card:problem:not_found" || problem.Instance != "/v1/cards/4444000011112222" {
+		t.Errorf("problem type %s instance %s", problem.Type, problem.Instance)
+	}
+}
@@ -7,13 +7,11 @@ import (
 	"encoding/json"
 	"reflect"
 	"net/http"
-	"strings"
-
-	"github.com/rs/zerolog/log"
+	"sync/atomic"
 
 	"github.com/go-card/internal/core/service"
 	"github.com/go-card/internal/core/model"
-	"github.com/go-card/internal/core/erro"
+	"github.com/go-card/internal/infra/logger"
 
 	"github.com/gorilla/mux"
 
@@ -22,15 +20,14 @@ import (
 )
 
 var (
-	childLogger = log.With().Str("component", "go-card").Str("package", "internal.adapter.api").Logger()
+	childLogger = logger.With().Str("component", "go-card").Str("package", "internal.adapter.api").Logger()
 	core_json		go_core_json.CoreJson
-	core_apiError 	go_core_json.APIError
 	tracerProvider 	go_core_observ.TracerProvider
 )
 
 type HttpRouters struct {
 	workerService 	*service.WorkerService
-	ctxTimeout		time.Duration
+	ctxTimeout		*atomic.Int64
 }
 
 // Above create routers
@@ -38,10 +35,29 @@ func NewHttpRouters(workerService *service.WorkerService,
 					ctxTimeout	time.Duration) HttpRouters {
 	childLogger.Info().Str("func","NewHttpRouters").Send()
 
-	return HttpRouters{
+	httpRouters := HttpRouters{
 		workerService: workerService,
-		ctxTimeout: ctxTimeout,
+		ctxTimeout: &atomic.Int64{},
 	}
+	httpRouters.ctxTimeout.Store(int64(ctxTimeout))
+
+	return httpRouters
+}
+
+// About get the context timeout
+func (h *HttpRouters) CtxTimeout() time.Duration {
+	return time.Duration(h.ctxTimeout.Load()) * time.Second
+}
+
+// About apply a reloaded runtime configuration
+func (h *HttpRouters) SetRuntimeConfig(	ctxTimeout 		time.Duration,
+										apiService 		map[string]model.ApiService,
+										featureFlags 	map[string]bool) {
+	childLogger.Info().Str("func","SetRuntimeConfig").Send()
+
+	h.ctxTimeout.Store(int64(ctxTimeout))
+	h.workerService.SetApiService(apiService)
+	h.workerService.SetFeatureFlags(featureFlags)
 }
 
 // About return a health
@@ -58,16 +74,32 @@ func (h *HttpRouters) Live(rw http.ResponseWriter, req *http.Request) {
 	json.NewEncoder(rw).Encode(model.MessageRouter{Message: "true"})
 }
 
+// About return the readiness of all dependencies (503 when a critical dependency is down)
+func (h *HttpRouters) Ready(rw http.ResponseWriter, req *http.Request) {
+	childLogger.Info().Str("func","Ready").Send()
+
+	res := h.workerService.Readiness(req.Context())
+
+	statusCode := http.StatusOK
+	if res.Status == "DOWN" {
+		statusCode = http.StatusServiceUnavailable
+	}
+
+	rw.Header().Set("Content-Type", "application/json")
+	rw.WriteHeader(statusCode)
+	json.NewEncoder(rw).Encode(res)
+}
+
 // About show all header received
 func (h *HttpRouters) Header(rw http.ResponseWriter, req *http.Request) {
-	childLogger.Info().Str("func","Header").Interface("trace-resquest-id", req.Context().Value("trace-request-id")).Send()
+	childLogger.Info().Str("func","Header").Ctx(req.Context()).Interface("trace-resquest-id", req.Context().Value("trace-request-id")).Send()
 	
 	json.NewEncoder(rw).Encode(req.Header)
 }
 
 // About show all context values
 func (h *HttpRouters) Context(rw http.ResponseWriter, req *http.Request) {
-	childLogger.Info().Str("func","Context").Interface("trace-resquest-id", req.Context().Value("trace-request-id")).Send()
+	childLogger.Info().Str("func","Context").Ctx(req.Context()).Interface("trace-resquest-id", req.Context().Value("trace-request-id")).Send()
 	
 	contextValues := reflect.ValueOf(req.Context()).Elem()
 	json.NewEncoder(rw).Encode(fmt.Sprintf("%v",contextValues))
@@ -75,7 +107,7 @@ func (h *HttpRouters) Context(rw http.ResponseWriter, req *http.Request) {
 
 // About show pgx stats
 func (h *HttpRouters) Stat(rw http.ResponseWriter, req *http.Request) {
-	childLogger.Info().Str("func","Stat").Interface("trace-resquest-id", req.Context().Value("trace-request-id")).Send()
+	childLogger.Info().Str("func","Stat").Ctx(req.Context()).Interface("trace-resquest-id", req.Context().Value("trace-request-id")).Send()
 	
 	res := h.workerService.Stat(req.Context())
 
@@ -83,28 +115,15 @@ func (h *HttpRouters) Stat(rw http.ResponseWriter, req *http.Request) {
 }
 
 // About handle error
-func (h *HttpRouters) ErrorHandler(trace_id string, err error) *go_core_json.APIError {
-	if strings.Contains(err.Error(), "context deadline exceeded") {
-    	err = erro.ErrTimeout
-	} 
-	switch err {
-	case erro.ErrBadRequest:
-		core_apiError = core_apiError.NewAPIError(err, trace_id, http.StatusBadRequest)
-	case erro.ErrNotFound:
-		core_apiError = core_apiError.NewAPIError(err, trace_id, http.StatusNotFound)
-	case erro.ErrTimeout:
-		core_apiError = core_apiError.NewAPIError(err, trace_id, http.StatusGatewayTimeout)
-	default:
-		core_apiError = core_apiError.NewAPIError(err, trace_id, http.StatusInternalServerError)
-	}
-	return &core_apiError
+func (h *HttpRouters) ErrorHandler(trace_id string, err error) *Problem {
+	return NewProblem(trace_id, err)
 }
 
 // About add card
 func (h *HttpRouters) AddCard(rw http.ResponseWriter, req *http.Request) error {
-	childLogger.Info().Str("func","AddCard").Interface("trace-resquest-id", req.Context().Value("trace-request-id")).Send()
+	childLogger.Info().Str("func","AddCard").Ctx(req.Context()).Interface("trace-resquest-id", req.Context().Value("trace-request-id")).Send()
 
-	ctx, cancel := context.WithTimeout(req.Context(), h.ctxTimeout * time.Second)
+	ctx, cancel := context.WithTimeout(req.Context(), h.CtxTimeout())
     defer cancel()
 
 	ctx, span := tracerProvider.SpanCtx(ctx, "adapter.api.AddCard")
@@ -113,11 +132,14 @@ func (h *HttpRouters) AddCard(rw http.ResponseWriter, req *http.Request) error {
 	trace_id := fmt.Sprintf("%v",ctx.Value("trace-request-id"))
 
 	card := model.Card{}
-	err := json.NewDecoder(req.Body).Decode(&card)
+	err := decodeJSON(req, &card)
     if err != nil {
-		return h.ErrorHandler(trace_id, erro.ErrBadRequest)
+		return h.ErrorHandler(trace_id, err)
     }
-	defer req.Body.Close()
+	err = validateStruct(card)
+	if err != nil {
+		return h.ErrorHandler(trace_id, err)
+	}
 
 	res, err := h.workerService.AddCard(ctx, card)
 	if err != nil {
@@ -129,9 +151,9 @@ func (h *HttpRouters) AddCard(rw http.ResponseWriter, req *http.Request) error {
 
 // About get card
 func (h *HttpRouters) GetCard(rw http.ResponseWriter, req *http.Request) error {
-	childLogger.Info().Str("func","GetCard").Interface("trace-resquest-id", req.Context().Value("trace-request-id")).Send()
+	childLogger.Info().Str("func","GetCard").Ctx(req.Context()).Interface("trace-resquest-id", req.Context().Value("trace-request-id")).Send()
 
-	ctx, cancel := context.WithTimeout(req.Context(), h.ctxTimeout * time.Second)
+	ctx, cancel := context.WithTimeout(req.Context(), h.CtxTimeout())
     defer cancel()
 
 	ctx, span := tracerProvider.SpanCtx(ctx, "adapter.api.GetCard")
@@ -155,9 +177,9 @@ func (h *HttpRouters) GetCard(rw http.ResponseWriter, req *http.Request) error {
 
 // About update card
 func (h *HttpRouters) UpdateCard(rw http.ResponseWriter, req *http.Request) error {
-	childLogger.Info().Str("func","UpdateCard").Interface("trace-resquest-id", req.Context().Value("trace-request-id")).Send()
+	childLogger.Info().Str("func","UpdateCard").Ctx(req.Context()).Interface("trace-resquest-id", req.Context().Value("trace-request-id")).Send()
 
-    ctx, cancel := context.WithTimeout(req.Context(), h.ctxTimeout * time.Second)
+    ctx, cancel := context.WithTimeout(req.Context(), h.CtxTimeout())
     defer cancel()
 
 	ctx, span := tracerProvider.SpanCtx(ctx, "adapter.api.UpdateCard")
@@ -166,11 +188,14 @@ func (h *HttpRouters) UpdateCard(rw http.ResponseWriter, req *http.Request) erro
 	trace_id := fmt.Sprintf("%v",ctx.Value("trace-request-id"))
 
 	card := model.Card{}
-	err := json.NewDecoder(req.Body).Decode(&card)
+	err := decodeJSON(req, &card)
     if err != nil {
-		return h.ErrorHandler(trace_id, erro.ErrBadRequest)
+		return h.ErrorHandler(trace_id, err)
     }
-	defer req.Body.Close()
+	err = ValidateCardNumber(card.CardNumber)
+	if err != nil {
+		return h.ErrorHandler(trace_id, err)
+	}
 
 	res, err := h.workerService.UpdateCard(ctx, card)
 	if err != nil {
@@ -182,9 +207,9 @@ func (h *HttpRouters) UpdateCard(rw http.ResponseWriter, req *http.Request) erro
 
 // About add card
 func (h *HttpRouters) CreateCardToken(rw http.ResponseWriter, req *http.Request) error {
-	childLogger.Info().Str("func","CreateCardToken").Interface("trace-resquest-id", req.Context().Value("trace-request-id")).Send()
+	childLogger.Info().Str("func","CreateCardToken").Ctx(req.Context()).Interface("trace-resquest-id", req.Context().Value("trace-request-id")).Send()
 
-	ctx, cancel := context.WithTimeout(req.Context(), h.ctxTimeout * time.Second)
+	ctx, cancel := context.WithTimeout(req.Context(), h.CtxTimeout())
     defer cancel()
 
 	ctx, span := tracerProvider.SpanCtx(ctx, "adapter.api.CreateCardToken")
@@ -193,11 +218,14 @@ func (h *HttpRouters) CreateCardToken(rw http.ResponseWriter, req *http.Request)
 	trace_id := fmt.Sprintf("%v",ctx.Value("trace-request-id"))
 
 	card := model.Card{}
-	err := json.NewDecoder(req.Body).Decode(&card)
+	err := decodeJSON(req, &card)
     if err != nil {
-		return h.ErrorHandler(trace_id, erro.ErrBadRequest)
+		return h.ErrorHandler(trace_id, err)
     }
-	defer req.Body.Close()
+	err = ValidateCardNumber(card.CardNumber)
+	if err != nil {
+		return h.ErrorHandler(trace_id, err)
+	}
 
 	res, err := h.workerService.CreateCardToken(ctx, card)
 	if err != nil {
@@ -209,9 +237,9 @@ func (h *HttpRouters) CreateCardToken(rw http.ResponseWriter, req *http.Request)
 
 // About get card
 func (h *HttpRouters) GetCardToken(rw http.ResponseWriter, req *http.Request) error {
-	childLogger.Info().Str("func","GetCardToken").Interface("trace-resquest-id", req.Context().Value("trace-request-id")).Send()
+	childLogger.Info().Str("func","GetCardToken").Ctx(req.Context()).Interface("trace-resquest-id", req.Context().Value("trace-request-id")).Send()
 
-	ctx, cancel := context.WithTimeout(req.Context(), h.ctxTimeout * time.Second)
+	ctx, cancel := context.WithTimeout(req.Context(), h.CtxTimeout())
     defer cancel()
 
 	ctx, span := tracerProvider.SpanCtx(ctx, "adapter.api.GetCardToken")
@@ -220,10 +248,10 @@ func (h *HttpRouters) GetCardToken(rw http.ResponseWriter, req *http.Request) er
 	trace_id := fmt.Sprintf("%v",ctx.Value("trace-request-id"))
 
 	vars := mux.Vars(req)
-	varID := vars["id"]
+	varToken := vars["token"]
 
 	card := model.Card{}
-	card.TokenData = varID
+	card.TokenData = varToken
 
 	res, err := h.workerService.GetCardToken(ctx, card)
 	if err != nil {
@@ -232,3 +260,546 @@ func (h *HttpRouters) GetCardToken(rw http.ResponseWriter, req *http.Request) er
 	
 	return core_json.WriteJSON(rw, http.StatusOK, res)
 }
+
+// About purge the account cache (all entries or one account_id)
+func (h *HttpRouters) PurgeAccountCache(rw http.ResponseWriter, req *http.Request) error {
+	childLogger.Info().Str("func","PurgeAccountCache").Ctx(req.Context()).Interface("trace-resquest-id", req.Context().Value("trace-request-id")).Send()
+
+	vars := mux.Vars(req)
+	varID := vars["id"]
+
+	count := h.workerService.PurgeAccountCache(req.Context(), varID)
+
+	return core_json.WriteJSON(rw, http.StatusOK, map[string]int{"purged": count})
+}
+
+// About increment the atc of the card in the path
+func (h *HttpRouters) IncrementAtc(rw http.ResponseWriter, req *http.Request) error {
+	childLogger.Info().Str("func","IncrementAtc").Ctx(req.Context()).Interface("trace-resquest-id", req.Context().Value("trace-request-id")).Send()
+
+	ctx, cancel := context.WithTimeout(req.Context(), h.CtxTimeout())
+    defer cancel()
+
+	ctx, span := tracerProvider.SpanCtx(ctx, "adapter.api.IncrementAtc")
+	defer span.End()
+
+	trace_id := fmt.Sprintf("%v",ctx.Value("trace-request-id"))
+
+	vars := mux.Vars(req)
+	varID := vars["id"]
+
+	err := ValidateCardNumber(varID)
+	if err != nil {
+		return h.ErrorHandler(trace_id, err)
+	}
+
+	card := model.Card{}
+	card.CardNumber = varID
+
+	res, err := h.workerService.UpdateCard(ctx, card)
+	if err != nil {
+		return h.ErrorHandler(trace_id, err)
+	}
+	
+	return core_json.WriteJSON(rw, http.StatusOK, res)
+}
+
+// About create a token of the card in the path
+func (h *HttpRouters) CreateToken(rw http.ResponseWriter, req *http.Request) error {
+	childLogger.Info().Str("func","CreateToken").Ctx(req.Context()).Interface("trace-resquest-id", req.Context().Value("trace-request-id")).Send()
+
+	ctx, cancel := context.WithTimeout(req.Context(), h.CtxTimeout())
+    defer cancel()
+
+	ctx, span := tracerProvider.SpanCtx(ctx, "adapter.api.CreateToken")
+	defer span.End()
+
+	trace_id := fmt.Sprintf("%v",ctx.Value("trace-request-id"))
+
+	vars := mux.Vars(req)
+	varID := vars["id"]
+
+	err := ValidateCardNumber(varID)
+	if err != nil {
+		return h.ErrorHandler(trace_id, err)
+	}
+
+	card := model.Card{}
+	card.CardNumber = varID
+
+	res, err := h.workerService.CreateCardToken(ctx, card)
+	if err != nil {
+		return h.ErrorHandler(trace_id, err)
+	}
+	
+	return core_json.WriteJSON(rw, http.StatusOK, res)
+}
+
+// About reissue the card in the path (LOST, STOLEN, DAMAGED or RENEWAL)
+func (h *HttpRouters) ReissueCard(rw http.ResponseWriter, req *http.Request) error {
+	childLogger.Info().Str("func","ReissueCard").Ctx(req.Context()).Interface("trace-resquest-id", req.Context().Value("trace-request-id")).Send()
+
+	ctx, cancel := context.WithTimeout(req.Context(), h.CtxTimeout())
+    defer cancel()
+
+	ctx, span := tracerProvider.SpanCtx(ctx, "adapter.api.ReissueCard")
+	defer span.End()
+
+	trace_id := fmt.Sprintf("%v",ctx.Value("trace-request-id"))
+
+	vars := mux.Vars(req)
+	varID := vars["id"]
+
+	err := ValidateCardNumber(varID)
+	if err != nil {
+		return h.ErrorHandler(trace_id, err)
+	}
+
+	reissue := model.CardReissue{}
+	err = decodeJSON(req, &reissue)
+    if err != nil {
+		return h.ErrorHandler(trace_id, err)
+    }
+	err = validateStruct(reissue)
+	if err != nil {
+		return h.ErrorHandler(trace_id, err)
+	}
+
+	card := model.Card{}
+	card.CardNumber = varID
+
+	res, err := h.workerService.ReissueCard(ctx, card, reissue.Reason)
+	if err != nil {
+		return h.ErrorHandler(trace_id, err)
+	}
+	
+	return core_json.WriteJSON(rw, http.StatusOK, res)
+}
+
+// About verify the cvv2 of the card in the path
+func (h *HttpRouters) VerifyCvv(rw http.ResponseWriter, req *http.Request) error {
+	childLogger.Info().Str("func","VerifyCvv").Ctx(req.Context()).Interface("trace-resquest-id", req.Context().Value("trace-request-id")).Send()
+
+	ctx, cancel := context.WithTimeout(req.Context(), h.CtxTimeout())
+    defer cancel()
+
+	ctx, span := tracerProvider.SpanCtx(ctx, "adapter.api.VerifyCvv")
+	defer span.End()
+
+	trace_id := fmt.Sprintf("%v",ctx.Value("trace-request-id"))
+
+	vars := mux.Vars(req)
+	varID := vars["id"]
+
+	err := ValidateCardNumber(varID)
+	if err != nil {
+		return h.ErrorHandler(trace_id, err)
+	}
+
+	cvvVerify := model.CardCvvVerify{}
+	err = decodeJSON(req, &cvvVerify)
+    if err != nil {
+		return h.ErrorHandler(trace_id, err)
+    }
+	err = validateStruct(cvvVerify)
+	if err != nil {
+		return h.ErrorHandler(trace_id, err)
+	}
+
+	card := model.Card{}
+	card.CardNumber = varID
+
+	res, err := h.workerService.VerifyCVV2(ctx, card, cvvVerify.Cvv2)
+	if err != nil {
+		return h.ErrorHandler(trace_id, err)
+	}
+	
+	return core_json.WriteJSON(rw, http.StatusOK, res)
+}
+
+// About get the dynamic cvv of the virtual card in the path
+func (h *HttpRouters) GetDcvv(rw http.ResponseWriter, req *http.Request) error {
+	childLogger.Info().Str("func","GetDcvv").Ctx(req.Context()).Interface("trace-resquest-id", req.Context().Value("trace-request-id")).Send()
+
+	ctx, cancel := context.WithTimeout(req.Context(), h.CtxTimeout())
+    defer cancel()
+
+	ctx, span := tracerProvider.SpanCtx(ctx, "adapter.api.GetDcvv")
+	defer span.End()
+
+	trace_id := fmt.Sprintf("%v",ctx.Value("trace-request-id"))
+
+	vars := mux.Vars(req)
+	varID := vars["id"]
+
+	err := ValidateCardNumber(varID)
+	if err != nil {
+		return h.ErrorHandler(trace_id, err)
+	}
+
+	card := model.Card{}
+	card.CardNumber = varID
+
+	res, err := h.workerService.GetDCVV(ctx, card)
+	if err != nil {
+		return h.ErrorHandler(trace_id, err)
+	}
+
+	// the code changes every window, it must not be cached
+	rw.Header().Set("Cache-Control", "no-store")
+	return core_json.WriteJSON(rw, http.StatusOK, res)
+}
+
+// About verify the dynamic cvv of the virtual card in the path
+func (h *HttpRouters) VerifyDcvv(rw http.ResponseWriter, req *http.Request) error {
+	childLogger.Info().Str("func","VerifyDcvv").Ctx(req.Context()).Interface("trace-resquest-id", req.Context().Value("trace-request-id")).Send()
+
+	ctx, cancel := context.WithTimeout(req.Context(), h.CtxTimeout())
+    defer cancel()
+
+	ctx, span := tracerProvider.SpanCtx(ctx, "adapter.api.VerifyDcvv")
+	defer span.End()
+
+	trace_id := fmt.Sprintf("%v",ctx.Value("trace-request-id"))
+
+	vars := mux.Vars(req)
+	varID := vars["id"]
+
+	err := ValidateCardNumber(varID)
+	if err != nil {
+		return h.ErrorHandler(trace_id, err)
+	}
+
+	dcvvVerify := model.CardDcvvVerify{}
+	err = decodeJSON(req, &dcvvVerify)
+    if err != nil {
+		return h.ErrorHandler(trace_id, err)
+    }
+	err = validateStruct(dcvvVerify)
+	if err != nil {
+		return h.ErrorHandler(trace_id, err)
+	}
+
+	card := model.Card{}
+	card.CardNumber = varID
+
+	res, err := h.workerService.VerifyDCVV(ctx, card, dcvvVerify.Dcvv)
+	if err != nil {
+		return h.ErrorHandler(trace_id, err)
+	}
+	
+	return core_json.WriteJSON(rw, http.StatusOK, res)
+}
+
+// About set the first pin of the card in the path (encrypted pin block)
+func (h *HttpRouters) SetPin(rw http.ResponseWriter, req *http.Request) error {
+	childLogger.Info().Str("func","SetPin").Ctx(req.Context()).Interface("trace-resquest-id", req.Context().Value("trace-request-id")).Send()
+
+	ctx, cancel := context.WithTimeout(req.Context(), h.CtxTimeout())
+    defer cancel()
+
+	ctx, span := tracerProvider.SpanCtx(ctx, "adapter.api.SetPin")
+	defer span.End()
+
+	trace_id := fmt.Sprintf("%v",ctx.Value("trace-request-id"))
+
+	vars := mux.Vars(req)
+	varID := vars["id"]
+
+	err := ValidateCardNumber(varID)
+	if err != nil {
+		return h.ErrorHandler(trace_id, err)
+	}
+
+	cardPin := model.CardPin{}
+	err = decodeJSON(req, &cardPin)
+    if err != nil {
+		return h.ErrorHandler(trace_id, err)
+    }
+	err = validateStruct(cardPin)
+	if err != nil {
+		return h.ErrorHandler(trace_id, err)
+	}
+
+	card := model.Card{}
+	card.CardNumber = varID
+
+	res, err := h.workerService.SetPIN(ctx, card, cardPin)
+	if err != nil {
+		return h.ErrorHandler(trace_id, err)
+	}
+	
+	return core_json.WriteJSON(rw, http.StatusOK, res)
+}
+
+// About change the pin of the card in the path (encrypted pin blocks)
+func (h *HttpRouters) ChangePin(rw http.ResponseWriter, req *http.Request) error {
+	childLogger.Info().Str("func","ChangePin").Ctx(req.Context()).Interface("trace-resquest-id", req.Context().Value("trace-request-id")).Send()
+
+	ctx, cancel := context.WithTimeout(req.Context(), h.CtxTimeout())
+    defer cancel()
+
+	ctx, span := tracerProvider.SpanCtx(ctx, "adapter.api.ChangePin")
+	defer span.End()
+
+	trace_id := fmt.Sprintf("%v",ctx.Value("trace-request-id"))
+
+	vars := mux.Vars(req)
+	varID := vars["id"]
+
+	err := ValidateCardNumber(varID)
+	if err != nil {
+		return h.ErrorHandler(trace_id, err)
+	}
+
+	cardPinChange := model.CardPinChange{}
+	err = decodeJSON(req, &cardPinChange)
+    if err != nil {
+		return h.ErrorHandler(trace_id, err)
+    }
+	err = validateStruct(cardPinChange)
+	if err != nil {
+		return h.ErrorHandler(trace_id, err)
+	}
+
+	card := model.Card{}
+	card.CardNumber = varID
+
+	res, err := h.workerService.ChangePIN(ctx, card, cardPinChange)
+	if err != nil {
+		return h.ErrorHandler(trace_id, err)
+	}
+	
+	return core_json.WriteJSON(rw, http.StatusOK, res)
+}
+
+// About verify the pin of the card in the path (encrypted pin block)
+func (h *HttpRouters) VerifyPin(rw http.ResponseWriter, req *http.Request) error {
+	childLogger.Info().Str("func","VerifyPin").Ctx(req.Context()).Interface("trace-resquest-id", req.Context().Value("trace-request-id")).Send()
+
+	ctx, cancel := context.WithTimeout(req.Context(), h.CtxTimeout())
+    defer cancel()
+
+	ctx, span := tracerProvider.SpanCtx(ctx, "adapter.api.VerifyPin")
+	defer span.End()
+
+	trace_id := fmt.Sprintf("%v",ctx.Value("trace-request-id"))
+
+	vars := mux.Vars(req)
+	varID := vars["id"]
+
+	err := ValidateCardNumber(varID)
+	if err != nil {
+		return h.ErrorHandler(trace_id, err)
+	}
+
+	cardPin := model.CardPin{}
+	err = decodeJSON(req, &cardPin)
+    if err != nil {
+		return h.ErrorHandler(trace_id, err)
+    }
+	err = validateStruct(cardPin)
+	if err != nil {
+		return h.ErrorHandler(trace_id, err)
+	}
+
+	card := model.Card{}
+	card.CardNumber = varID
+
+	res, err := h.workerService.VerifyPIN(ctx, card, cardPin)
+	if err != nil {
+		return h.ErrorHandler(trace_id, err)
+	}
+	
+	return core_json.WriteJSON(rw, http.StatusOK, res)
+}
+
+// About get the spending controls of the card in the path
+func (h *HttpRouters) GetControls(rw http.ResponseWriter, req *http.Request) error {
+	childLogger.Info().Str("func","GetControls").Ctx(req.Context()).Interface("trace-resquest-id", req.Context().Value("trace-request-id")).Send()
+
+	ctx, cancel := context.WithTimeout(req.Context(), h.CtxTimeout())
+    defer cancel()
+
+	ctx, span := tracerProvider.SpanCtx(ctx, "adapter.api.GetControls")
+	defer span.End()
+
+	trace_id := fmt.Sprintf("%v",ctx.Value("trace-request-id"))
+
+	vars := mux.Vars(req)
+	varID := vars["id"]
+
+	err := ValidateCardNumber(varID)
+	if err != nil {
+		return h.ErrorHandler(trace_id, err)
+	}
+
+	card := model.Card{}
+	card.CardNumber = varID
+
+	res, err := h.workerService.GetCardControl(ctx, card)
+	if err != nil {
+		return h.ErrorHandler(trace_id, err)
+	}
+	
+	return core_json.WriteJSON(rw, http.StatusOK, res)
+}
+
+// About replace the spending controls of the card in the path
+func (h *HttpRouters) UpdateControls(rw http.ResponseWriter, req *http.Request) error {
+	childLogger.Info().Str("func","UpdateControls").Ctx(req.Context()).Interface("trace-resquest-id", req.Context().Value("trace-request-id")).Send()
+
+	ctx, cancel := context.WithTimeout(req.Context(), h.CtxTimeout())
+    defer cancel()
+
+	ctx, span := tracerProvider.SpanCtx(ctx, "adapter.api.UpdateControls")
+	defer span.End()
+
+	trace_id := fmt.Sprintf("%v",ctx.Value("trace-request-id"))
+
+	vars := mux.Vars(req)
+	varID := vars["id"]
+
+	err := ValidateCardNumber(varID)
+	if err != nil {
+		return h.ErrorHandler(trace_id, err)
+	}
+
+	control := model.CardControl{}
+	err = decodeJSON(req, &control)
+    if err != nil {
+		return h.ErrorHandler(trace_id, err)
+    }
+	err = validateStruct(control)
+	if err != nil {
+		return h.ErrorHandler(trace_id, err)
+	}
+
+	card := model.Card{}
+	card.CardNumber = varID
+
+	res, err := h.workerService.UpdateCardControl(ctx, card, control)
+	if err != nil {
+		return h.ErrorHandler(trace_id, err)
+	}
+	
+	return core_json.WriteJSON(rw, http.StatusOK, res)
+}
+
+// About evaluate a proposed transaction against the spending controls of the card in the path
+func (h *HttpRouters) EvaluateControls(rw http.ResponseWriter, req *http.Request) error {
+	childLogger.Info().Str("func","EvaluateControls").Ctx(req.Context()).Interface("trace-resquest-id", req.Context().Value("trace-request-id")).Send()
+
+	ctx, cancel := context.WithTimeout(req.Context(), h.CtxTimeout())
+    defer cancel()
+
+	ctx, span := tracerProvider.SpanCtx(ctx, "adapter.api.EvaluateControls")
+	defer span.End()
+
+	trace_id := fmt.Sprintf("%v",ctx.Value("trace-request-id"))
+
+	vars := mux.Vars(req)
+	varID := vars["id"]
+
+	err := ValidateCardNumber(varID)
+	if err != nil {
+		return h.ErrorHandler(trace_id, err)
+	}
+
+	transaction := model.CardTransaction{}
+	err = decodeJSON(req, &transaction)
+    if err != nil {
+		return h.ErrorHandler(trace_id, err)
+    }
+	err = validateStruct(transaction)
+	if err != nil {
+		return h.ErrorHandler(trace_id, err)
+	}
+
+	card := model.Card{}
+	card.CardNumber = varID
+
+	res, err := h.workerService.EvaluateCardControl(ctx, card, transaction)
+	if err != nil {
+		return h.ErrorHandler(trace_id, err)
+	}
+	
+	return core_json.WriteJSON(rw, http.StatusOK, res)
+}
+
+// About authorize a transaction of the card in the path, a decline is a 200 with its ISO 8583 response code
+func (h *HttpRouters) Authorize(rw http.ResponseWriter, req *http.Request) error {
+	childLogger.Info().Str("func","Authorize").Ctx(req.Context()).Interface("trace-resquest-id", req.Context().Value("trace-request-id")).Send()
+
+	ctx, cancel := context.WithTimeout(req.Context(), h.CtxTimeout())
+    defer cancel()
+
+	ctx, span := tracerProvider.SpanCtx(ctx, "adapter.api.Authorize")
+	defer span.End()
+
+	trace_id := fmt.Sprintf("%v",ctx.Value("trace-request-id"))
+
+	vars := mux.Vars(req)
+	varID := vars["id"]
+
+	err := ValidateCardNumber(varID)
+	if err != nil {
+		return h.ErrorHandler(trace_id, err)
+	}
+
+	authorize := model.CardAuthorize{}
+	err = decodeJSON(req, &authorize)
+    if err != nil {
+		return h.ErrorHandler(trace_id, err)
+    }
+	err = validateStruct(authorize)
+	if err != nil {
+		return h.ErrorHandler(trace_id, err)
+	}
+
+	card := model.Card{}
+	card.CardNumber = varID
+
+	res, err := h.workerService.Authorize(ctx, card, authorize)
+	if err != nil {
+		return h.ErrorHandler(trace_id, err)
+	}
+	
+	return core_json.WriteJSON(rw, http.StatusOK, res)
+}
+
+// About list the authorizations of the card in the path, from the newest
+func (h *HttpRouters) ListAuthorizations(rw http.ResponseWriter, req *http.Request) error {
+	childLogger.Info().Str("func","ListAuthorizations").Ctx(req.Context()).Interface("trace-resquest-id", req.Context().Value("trace-request-id")).Send()
+
+	ctx, cancel := context.WithTimeout(req.Context(), h.CtxTimeout())
+    defer cancel()
+
+	ctx, span := tracerProvider.SpanCtx(ctx, "adapter.api.ListAuthorizations")
+	defer span.End()
+
+	trace_id := fmt.Sprintf("%v",ctx.Value("trace-request-id"))
+
+	vars := mux.Vars(req)
+	varID := vars["id"]
+
+	err := ValidateCardNumber(varID)
+	if err != nil {
+		return h.ErrorHandler(trace_id, err)
+	}
+
+	filter, err := parseAuthorizationFilter(req)
+	if err != nil {
+		return h.ErrorHandler(trace_id, err)
+	}
+
+	card := model.Card{}
+	card.CardNumber = varID
+
+	res, err := h.workerService.ListAuthorizations(ctx, card, filter)
+	if err != nil {
+		return h.ErrorHandler(trace_id, err)
+	}
+	
+	return core_json.WriteJSON(rw, http.StatusOK, res)
+}
@@ -0,0 +1,188 @@
+package api
+
+import (
+	"io"
+	"time"
+	"errors"
+	"strconv"
+	"regexp"
+	"reflect"
+	"strings"
+	"net/http"
+	"encoding/json"
+
+	"github.com/go-card/internal/core/erro"
+	"github.com/go-card/internal/core/model"
+	"github.com/go-card/internal/core/pan"
+
+	"github.com/go-playground/validator/v10"
+)
+
+var (
+	// the card number may be grouped by '.', '-' or ' ' (ex: 5550.0000.0000.0004)
+	panCharsRegex	= regexp.MustCompile(`^[0-9][0-9 .\-]*[0-9]$`)
+	holderRegex		= regexp.MustCompile(`^[A-Za-z][A-Za-z0-9 .'\-/]*$`)
+	pinBlockRegex	= regexp.MustCompile(`^[0-9A-Fa-f]{16}([0-9A-Fa-f]{16})?$`)
+	validate		= newValidator()
+)
+
+// About the code and message of each validation tag
+var validationMessages = map[string]struct{ code, message string }{
+	"required":		{"required", "is required"},
+	"pan":			{"invalid_format", "must have 13 to 19 digits"},
+	"pan_chars":	{"invalid_format", "must have only digits and the separators '.', '-' or ' '"},
+	"luhn":			{"invalid_check_digit", "fails the luhn check"},
+	"holder":		{"invalid_charset", "must have only letters, digits, spaces and . ' - /"},
+	"min":			{"invalid_length", "is too short"},
+	"max":			{"invalid_length", "is too long"},
+	"oneof":		{"invalid_value", "must be one of "},
+	"numeric":		{"invalid_format", "must have only digits"},
+	"len":			{"invalid_length", "has an invalid length"},
+	"isdefault":	{"not_allowed", "is not allowed"},
+	"pin_block":	{"invalid_format", "must have 16 (format 0) or 32 (format 4) hex digits"},
+	"gt":			{"invalid_value", "must be greater than "},
+	"gte":			{"invalid_value", "must be greater than or equal to "},
+	"alpha":		{"invalid_format", "must have only letters"},
+	"uppercase":	{"invalid_format", "must be uppercase"},
+	"hexadecimal":	{"invalid_format", "must have only hex digits"},
+}
+
+// About create the validator with the card rules, the field errors use the json names
+func newValidator() *validator.Validate {
+	v := validator.New(validator.WithRequiredStructEnabled())
+
+	v.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name := strings.SplitN(field.Tag.Get("json"), ",", 2)[0]
+		if name == "-" {
+			return ""
+		}
+		return name
+	})
+	v.RegisterValidation("pan_chars", func(fl validator.FieldLevel) bool {
+		return panCharsRegex.MatchString(fl.Field().String())
+	})
+	v.RegisterValidation("pan", func(fl validator.FieldLevel) bool {
+		digits := pan.Digits(fl.Field().String())
+		return panCharsRegex.MatchString(fl.Field().String()) && len(digits) >= 13 && len(digits) <= 19
+	})
+	v.RegisterValidation("luhn", func(fl validator.FieldLevel) bool {
+		return pan.Valid(pan.Digits(fl.Field().String()))
+	})
+	v.RegisterValidation("holder", func(fl validator.FieldLevel) bool {
+		return holderRegex.MatchString(fl.Field().String())
+	})
+	v.RegisterValidation("pin_block", func(fl validator.FieldLevel) bool {
+		return pinBlockRegex.MatchString(fl.Field().String())
+	})
+
+	return v
+}
+
+// About convert the validator errors into the field errors of the problem
+func toValidationError(err error) error {
+	var validationErrors validator.ValidationErrors
+	if !errors.As(err, &validationErrors) {
+		return err
+	}
+
+	fields := make([]erro.FieldError, 0, len(validationErrors))
+	for _, fieldError := range validationErrors {
+		message, ok := validationMessages[fieldError.Tag()]
+		if !ok {
+			message = struct{ code, message string }{"invalid", "is invalid"}
+		}
+		text := message.message
+		switch fieldError.Tag() {
+		case "oneof":
+			text = text + strings.ReplaceAll(fieldError.Param(), " ", ", ")
+		case "gt", "gte":
+			text = text + fieldError.Param()
+		}
+		fields = append(fields, erro.FieldError{Field: fieldError.Field(), Code: message.code, Message: text})
+	}
+	return &erro.ValidationError{Fields: fields}
+}
+
+// About validate a struct with its validate tags
+func validateStruct(v interface{}) error {
+	if err := validate.Struct(v); err != nil {
+		return toValidationError(err)
+	}
+	return nil
+}
+
+// About validate the card number used as a lookup key, the existing cards may not pass the luhn check
+func ValidateCardNumber(cardNumber string) error {
+	if err := validate.Var(cardNumber, "required,max=23,pan_chars"); err != nil {
+		var validationErrors validator.ValidationErrors
+		if errors.As(err, &validationErrors) {
+			message := validationMessages[validationErrors[0].Tag()]
+			return &erro.ValidationError{Fields: []erro.FieldError{{Field: "card_number", Code: message.code, Message: message.message}}}
+		}
+		return err
+	}
+	return nil
+}
+
+// About parse a date-time (RFC 3339) or a date of the query, a date is the start of the day (UTC) or its end with endOfDay
+func parseQueryTime(value string, endOfDay bool) (time.Time, error) {
+	if date, err := time.Parse(time.DateOnly, value); err == nil {
+		if endOfDay {
+			return date.AddDate(0, 0, 1), nil
+		}
+		return date, nil
+	}
+	return time.Parse(time.RFC3339, value)
+}
+
+// About parse the filter of the authorizations (from, to, limit, cursor), to is excluded
+func parseAuthorizationFilter(req *http.Request) (model.AuthorizationFilter, error) {
+	query := req.URL.Query()
+	filter := model.AuthorizationFilter{Cursor: query.Get("cursor")}
+
+	var fields []erro.FieldError
+	var err error
+	if value := query.Get("from"); value != "" {
+		if filter.From, err = parseQueryTime(value, false); err != nil {
+			fields = append(fields, erro.FieldError{Field: "from", Code: "invalid_format", Message: "must be a date (2006-01-02) or a date-time (RFC 3339)"})
+		}
+	}
+	if value := query.Get("to"); value != "" {
+		if filter.To, err = parseQueryTime(value, true); err != nil {
+			fields = append(fields, erro.FieldError{Field: "to", Code: "invalid_format", Message: "must be a date (2006-01-02) or a date-time (RFC 3339)"})
+		}
+	}
+	if value := query.Get("limit"); value != "" {
+		if filter.Limit, err = strconv.Atoi(value); err != nil || filter.Limit <= 0 {
+			fields = append(fields, erro.FieldError{Field: "limit", Code: "invalid_value", Message: "must be greater than 0"})
+		}
+	}
+	if len(fields) > 0 {
+		return filter, &erro.ValidationError{Fields: fields}
+	}
+	return filter, nil
+}
+
+// About decode the json body, the unknown fields are rejected
+func decodeJSON(req *http.Request, v interface{}) error {
+	defer req.Body.Close()
+
+	decoder := json.NewDecoder(req.Body)
+	decoder.DisallowUnknownFields()
+
+	err := decoder.Decode(v)
+	if err == nil {
+		return nil
+	}
+	if errors.Is(err, io.EOF) {
+		return &erro.ValidationError{Fields: []erro.FieldError{{Field: "body", Code: "required", Message: "is required"}}}
+	}
+	if field, ok := strings.CutPrefix(err.Error(), "json: unknown field "); ok {
+		return &erro.ValidationError{Fields: []erro.FieldError{{Field: strings.Trim(field, `"`), Code: "unknown_field", Message: "is not allowed"}}}
+	}
+	var typeError *json.UnmarshalTypeError
+	if errors.As(err, &typeError) {
+		return &erro.ValidationError{Fields: []erro.FieldError{{Field: typeError.Field, Code: "invalid_type", Message: "must be a " + typeError.Type.String()}}}
+	}
+	return erro.ErrBadRequest
+}
@@ -0,0 +1,166 @@
+package api
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"net/http"
+	"encoding/json"
+	"net/http/httptest"
+
+	"github.com/go-card/internal/core/erro"
+	"github.com/go-card/internal/core/model"
+)
+
+func validCard() model.Card {
+	return model.Card{
+		AccountID:	"ACC-001",
+		CardNumber:	"4111.1111.1111.1111",
+		Holder:		"Mary O'Neil",
+		Type:		"CREDIT",
+		Model:		"CHIP",
+	}
+}
+
+// About the field and code of the field errors of a validation error
+func fieldCodes(t *testing.T, err error) []string {
+	t.Helper()
+	if err == nil {
+		return nil
+	}
+	var validationError *erro.ValidationError
+	if !errors.As(err, &validationError) {
+		t.Fatalf("err %v, want a validation error", err)
+	}
+	codes := []string{}
+	for _, field := range validationError.Fields {
+		codes = append(codes, field.Field + ":" + field.Code)
+	}
+	return codes
+}
+
+func TestValidateCard(t *testing.T) {
+	tests := []struct {
+		name	string
+		card	func(card *model.Card)
+		fields	[]string
+	}{
+		{"valid", func(card *model.Card) {}, nil},
+		{"required", func(card *model.Card) { *card = model.Card{} }, []string{
+			"account_id:required", "card_number:required", "holder:required", "type:required", "model:required",
+		}},
+		{"pan too short", func(card *model.Card) { card.CardNumber = "411111111111" }, []string{"card_number:invalid_format"}},
+		{"pan with letters", func(card *model.Card) { card.CardNumber = "4111A11111111111" }, []string{"card_number:invalid_format"}},
+		{"luhn", func(card *model.Card) { card.CardNumber = "4111111111111112" }, []string{"card_number:invalid_check_digit"}},
+		{"holder charset", func(card *model.Card) { card.Holder = "Mary <script>" }, []string{"holder:invalid_charset"}},
+		{"holder too long", func(card *model.Card) { card.Holder = strings.Repeat("A", 27) }, []string{"holder:invalid_length"}},
+		{"type", func(card *model.Card) { card.Type = "GOLD" }, []string{"type:invalid_value"}},
+		{"cvv2 not allowed", func(card *model.Card) { card.Cvv2 = "123" }, []string{"cvv2:not_allowed"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			card := validCard()
+			test.card(&card)
+			fields := fieldCodes(t, validateStruct(card))
+			if strings.Join(fields, ",") != strings.Join(test.fields, ",") {
+				t.Errorf("fields %v, want %v", fields, test.fields)
+			}
+		})
+	}
+}
+
+func TestValidateMessages(t *testing.T) {
+	tests := []struct {
+		name	string
+		value	interface{}
+		message	string
+	}{
+		{"oneof lists the values", model.CardReissue{Reason: "LATE"}, "must be one of LOST, STOLEN, DAMAGED, RENEWAL"},
+		{"gt has its param", model.CardTransaction{Amount: -1, Currency: "BRL", Mcc: "5411", Channel: "POS"}, "must be greater than 0"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var validationError *erro.ValidationError
+			if err := validateStruct(test.value); !errors.As(err, &validationError) {
+				t.Fatalf("err %v, want a validation error", err)
+			}
+			if validationError.Fields[0].Message != test.message {
+				t.Errorf("message %q, want %q", validationError.Fields[0].Message, test.message)
+			}
+		})
+	}
+}
+
+func TestValidateCardNumber(t *testing.T) {
+	tests := []struct {
+		cardNumber	string
+		fields		[]string
+	}{
+		{"4111111111111111", nil},
+		{"4111111111111112", nil}, // a lookup key doesn't need the luhn check
+		{"5550-0000 0000.0004", nil},
+		{"", []string{"card_number:required"}},
+		{"4111x1111", []string{"card_number:invalid_format"}},
+		{strings.Repeat("4", 24), []string{"card_number:invalid_length"}},
+	}
+
+	for _, test := range tests {
+		fields := fieldCodes(t, ValidateCardNumber(test.cardNumber))
+		if strings.Join(fields, ",") != strings.Join(test.fields, ",") {
+			t.Errorf("card number %q fields %v, want %v", test.cardNumber, fields, test.fields)
+		}
+	}
+}
+
+func TestDecodeJSON(t *testing.T) {
+	tests := []struct {
+		name	string
+		body	string
+		fields	[]string
+		err		error
+	}{
+		{"valid", `{"account_id":"ACC-001"}`, nil, nil},
+		{"empty body", ``, []string{"body:required"}, erro.ErrValidation},
+		{"unknown field", `{"account":"ACC-001"}`, []string{"account:unknown_field"}, erro.ErrValidation},
+		{"invalid type", `{"atc":"1"}`, []string{"atc:invalid_type"}, erro.ErrValidation},
+		{"malformed", `{"account_id":`, nil, erro.ErrBadRequest},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/v1/cards", strings.NewReader(test.body))
+			err := decodeJSON(req, &model.Card{})
+			if !errors.Is(err, test.err) {
+				t.Fatalf("err %v, want %v", err, test.err)
+			}
+			if test.fields == nil {
+				return
+			}
+			if fields := fieldCodes(t, err); strings.Join(fields, ",") != strings.Join(test.fields, ",") {
+				t.Errorf("fields %v, want %v", fields, test.fields)
+			}
+		})
+	}
+}
+
+func TestAddCardValidationProblem(t *testing.T) {
+	httpRouters := NewHttpRouters(nil, 5)
+	handler := MiddleWareErrorHandler(httpRouters.AddCard)
+
+	// the payload is rejected before the service
+	rec := httptest.NewRecorder()
+	handler(rec, httptest.NewRequest(http.MethodPost, "/v1/cards", strings.NewReader(`{"account_id":"ACC-001","card_number":"4111111111111112","holder":"Mary","type":"CREDIT","model":"CHIP"}`)))
+
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("status %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+	problem := Problem{}
+	if err := json.NewDecoder(rec.Body).Decode(&problem); err != nil {
+		t.Fatalf("decode problem: %v", err)
+	}
+	if problem.Code != "VALIDATION_FAILED" || len(problem.Errors) != 1 || problem.Errors[0].Field != "card_number" || problem.Errors[0].Code != "invalid_check_digit" {
+		t.Errorf("problem %s %v, want VALIDATION_FAILED card_number:invalid_check_digit", problem.Code, problem.Errors)
+	}
+}
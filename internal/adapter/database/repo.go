@@ -7,17 +7,21 @@ import (
 	
 	"github.com/go-card/internal/core/model"
 	"github.com/go-card/internal/core/erro"
+	"github.com/go-card/internal/infra/logger"
 
 	go_core_observ "github.com/eliezerraj/go-core/observability"
 	go_core_pg "github.com/eliezerraj/go-core/database/pg"
 
 	"github.com/jackc/pgx/v5"
-	"github.com/rs/zerolog/log"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 )
 
 var (
 	tracerProvider go_core_observ.TracerProvider
-	childLogger = log.With().Str("component","go-card").Str("package","internal.adapter.database").Logger()
+	childLogger = logger.With().Str("component","go-card").Str("package","internal.adapter.database").Logger()
 )
 
 type WorkerRepository struct {
@@ -35,7 +39,7 @@ func NewWorkerRepository(databasePGServer *go_core_pg.DatabasePGServer) *WorkerR
 
 // Above get stats from database
 func (w WorkerRepository) Stat(ctx context.Context) (go_core_pg.PoolStats){
-	childLogger.Info().Str("func","Stat").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Send()
+	childLogger.Info().Str("func","Stat").Ctx(ctx).Interface("trace-resquest-id", ctx.Value("trace-request-id")).Send()
 	
 	stats := w.DatabasePGServer.Stat()
 
@@ -53,12 +57,51 @@ func (w WorkerRepository) Stat(ctx context.Context) (go_core_pg.PoolStats){
 	return resPoolStats
 }
 
+// About run fn in a transaction (unit of work)
+// The transaction is committed when fn succeeds and rolled back when fn fails or panics
+// Do not call remote services inside fn, it keeps a pooled connection pinned
+func (w WorkerRepository) WithTx(ctx context.Context, fn func(tx pgx.Tx) error) (err error){
+	childLogger.Info().Str("func","WithTx").Ctx(ctx).Interface("trace-resquest-id", ctx.Value("trace-request-id")).Send()
+
+	// trace
+	ctx, span := startQuerySpan(ctx, "database.WithTx", "transaction")
+	defer span.End()
+
+	start := time.Now()
+	tx, conn, err := w.DatabasePGServer.StartTx(ctx)
+	span.SetAttributes(attribute.Int64("db.pool.wait_ms", time.Since(start).Milliseconds()))
+	if err != nil {
+		return queryError(span, err)
+	}
+	defer w.DatabasePGServer.ReleaseTx(conn)
+
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback(ctx)
+			panic(p)
+		}
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			if errRollback := tx.Rollback(ctx); errRollback != nil {
+				childLogger.Error().Err(errRollback).Msg("error rollback")
+			}
+			return
+		}
+		if errCommit := tx.Commit(ctx); errCommit != nil {
+			err = queryError(span, errCommit)
+		}
+	}()
+
+	return fn(tx)
+}
+
 // Above add card
 func (w WorkerRepository) AddCard(ctx context.Context, tx pgx.Tx, card model.Card) (*model.Card, error){
-	childLogger.Info().Str("func","AddCard").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Send()
+	childLogger.Info().Str("func","AddCard").Ctx(ctx).Interface("trace-resquest-id", ctx.Value("trace-request-id")).Send()
 
 	// trace
-	ctx, span := tracerProvider.SpanCtx(ctx, "database.AddCard")
+	ctx, span := startQuerySpan(ctx, "database.AddCard", "card.insert")
 	defer span.End()
 
 	// prepare
@@ -76,8 +119,10 @@ func (w WorkerRepository) AddCard(ctx context.Context, tx pgx.Tx, card model.Car
 								atc, 
 								expired_at, 
 								created_at, 
-								tenant_id) 
-								VALUES($1, $2, $3, $4, $5, $6, $7, $8, $9, $10) RETURNING id`
+								tenant_id,
+								fk_predecessor_id,
+								reissue_reason) 
+								VALUES($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12) RETURNING id`
 	
 	// execute	
 	row := tx.QueryRow(ctx, query,  card.FkAccountID,  
@@ -90,14 +135,16 @@ func (w WorkerRepository) AddCard(ctx context.Context, tx pgx.Tx, card model.Car
 									card.ExpiredAt,
 									card.CreatedAt,
 									card.TenantID,
+									nullableID(card.PredecessorID),
+									nullableString(card.ReissueReason),
 									)
 
 	var id int
 	
 	if err := row.Scan(&id); err != nil {
-		childLogger.Error().Err(err).Send()			
-		return nil, errors.New(err.Error())
+		return nil, queryError(span, err)
 	}
+	setRowsAffected(span, 1)
 
 	card.ID = id
 	
@@ -106,17 +153,16 @@ func (w WorkerRepository) AddCard(ctx context.Context, tx pgx.Tx, card model.Car
 
 // Above get card
 func (w WorkerRepository) GetCard(ctx context.Context, card model.Card) (*model.Card, error){
-	childLogger.Info().Str("func","GetCard").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Send()
+	childLogger.Info().Str("func","GetCard").Ctx(ctx).Interface("trace-resquest-id", ctx.Value("trace-request-id")).Send()
 
 	// trace
-	span := tracerProvider.Span(ctx, "database.GetCard")
+	ctx, span := startQuerySpan(ctx, "database.GetCard", "card.select_by_card_number")
 	defer span.End()
 
 	// prepare database
-	conn, err := w.DatabasePGServer.Acquire(ctx)
+	conn, err := w.acquire(ctx, span)
 	if err != nil {
-		childLogger.Error().Err(err).Send()	
-		return nil, errors.New(err.Error())
+		return nil, err
 	}
 	defer w.DatabasePGServer.Release(conn)
 
@@ -134,20 +180,22 @@ func (w WorkerRepository) GetCard(ctx context.Context, card model.Card) (*model.
 						cc.expired_at, 
 						cc.created_at,
 						cc.updated_at, 
-						cc.tenant_id
+						cc.tenant_id,
+						COALESCE(cc.fk_predecessor_id, 0),
+						COALESCE(cc.reissue_reason, '')
 				FROM card cc
-				WHERE card_number = $1`
+				WHERE card_number = $1
+				ORDER BY cc.id DESC
+				LIMIT 1`
 
 	// execute			
 	rows, err := conn.Query(ctx, query, card.CardNumber)
 	if err != nil {
-		childLogger.Error().Err(err).Send()	
-		return nil, errors.New(err.Error())
+		return nil, queryError(span, err)
 	}
 	defer rows.Close()
     if err := rows.Err(); err != nil {
-		childLogger.Error().Err(err).Msg("fatal error closing rows")
-        return nil, errors.New(err.Error())
+        return nil, queryError(span, err)
     }
 
 	for rows.Next() {
@@ -163,23 +211,26 @@ func (w WorkerRepository) GetCard(ctx context.Context, card model.Card) (*model.
 							&res_card.CreatedAt,
 							&res_card.UpdatedAt,
 							&res_card.TenantID,
+							&res_card.PredecessorID,
+							&res_card.ReissueReason,
 						)
 		if err != nil {
-			childLogger.Error().Err(err).Send()	
-			return nil, errors.New(err.Error())
+			return nil, queryError(span, err)
         }
+		setRowsAffected(span, 1)
 		return &res_card, nil
 	}
+	setRowsAffected(span, 0)
 	
 	return nil, erro.ErrNotFound
 }
 
 // Above update atc
 func (w WorkerRepository) UpdateCard(ctx context.Context, tx pgx.Tx, card model.Card) (int64, error){
-	childLogger.Info().Str("func","UpdateCard").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Send()
+	childLogger.Info().Str("func","UpdateCard").Ctx(ctx).Interface("trace-resquest-id", ctx.Value("trace-request-id")).Send()
 
 	// trace
-	span := tracerProvider.Span(ctx, "database.UpdateCard")
+	ctx, span := startQuerySpan(ctx, "database.UpdateCard", "card.update_atc")
 	defer span.End()
 
 	// prepare query
@@ -189,15 +240,15 @@ func (w WorkerRepository) UpdateCard(ctx context.Context, tx pgx.Tx, card model.
 	query := `Update public.card
 				set atc = atc + 1, 
 					updated_at = $2
-				where card_number = $1`
+				where id = $1`
 
 	// execute
-	row, err := tx.Exec(ctx, query, card.CardNumber,  
+	row, err := tx.Exec(ctx, query, card.ID,  
 									card.UpdatedAt)
 	if err != nil {
-		childLogger.Error().Err(err).Send()			
-		return 0, errors.New(err.Error())
+		return 0, queryError(span, err)
 	}
+	setRowsAffected(span, row.RowsAffected())
 
 	if int(row.RowsAffected()) == 0 {
 		return 0, erro.ErrUpdateRows
@@ -209,10 +260,10 @@ func (w WorkerRepository) UpdateCard(ctx context.Context, tx pgx.Tx, card model.
 
 // About add token card 
 func (w *WorkerRepository) CreateCardToken(ctx context.Context, tx pgx.Tx, card model.Card) (*model.Card, error){
-	childLogger.Info().Str("func","CreateCardToken").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Send()
+	childLogger.Info().Str("func","CreateCardToken").Ctx(ctx).Interface("trace-resquest-id", ctx.Value("trace-request-id")).Send()
 
 	//trace
-	span := tracerProvider.Span(ctx, "database.CreateCardToken")
+	ctx, span := startQuerySpan(ctx, "database.CreateCardToken", "card_token.insert")
 	defer span.End()
 
 	// Query e Execute
@@ -234,9 +285,9 @@ func (w *WorkerRepository) CreateCardToken(ctx context.Context, tx pgx.Tx, card
 						card.TenantID)								
 	var id int
 	if err := row.Scan(&id); err != nil {
-		childLogger.Error().Err(err).Send()	
-		return nil, errors.New(err.Error())
+		return nil, queryError(span, err)
 	}
+	setRowsAffected(span, 1)
 
 	card.ID = id
 
@@ -245,17 +296,16 @@ func (w *WorkerRepository) CreateCardToken(ctx context.Context, tx pgx.Tx, card
 
 // About add token card 
 func (w *WorkerRepository) GetCardToken(ctx context.Context, card model.Card) (*[]model.Card, error){
-	childLogger.Info().Str("func","GetCardToken").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Send()
+	childLogger.Info().Str("func","GetCardToken").Ctx(ctx).Interface("trace-resquest-id", ctx.Value("trace-request-id")).Send()
 
 	//trace
-	span := tracerProvider.Span(ctx, "database.GetCardToken")
+	ctx, span := startQuerySpan(ctx, "database.GetCardToken", "card_token.select_by_token")
 	defer span.End()
 
 	// Prepare
-	conn, err := w.DatabasePGServer.Acquire(ctx)
+	conn, err := w.acquire(ctx, span)
 	if err != nil {
-		childLogger.Error().Err(err).Send()	
-		return nil, errors.New(err.Error())
+		return nil, err
 	}
 	defer w.DatabasePGServer.Release(conn)
 
@@ -280,13 +330,11 @@ func (w *WorkerRepository) GetCardToken(ctx context.Context, card model.Card) (*
 
 	rows, err := conn.Query(ctx, query, string(card.TokenData))
 	if err != nil {
-		childLogger.Error().Err(err).Send()	
-		return nil, errors.New(err.Error())
+		return nil, queryError(span, err)
 	}
 	defer rows.Close()
     if err := rows.Err(); err != nil {
-		childLogger.Error().Err(err).Msg("fatal error closing rows")
-        return nil, errors.New(err.Error())
+        return nil, queryError(span, err)
     }
 	
 	for rows.Next() {
@@ -300,11 +348,731 @@ func (w *WorkerRepository) GetCardToken(ctx context.Context, card model.Card) (*
 							&res_card.UpdatedAt,
 							&res_card.TenantID)
 		if err != nil {
-			childLogger.Error().Err(err).Send()	
-			return nil, errors.New(err.Error())
+			return nil, queryError(span, err)
         }
 		res_card_list = append(res_card_list, res_card)
 	}
+	setRowsAffected(span, int64(len(res_card_list)))
 
 	return &res_card_list , nil
+}
+
+// About check if a card has a token
+func (w *WorkerRepository) HasCardToken(ctx context.Context, cardID int, token string) (bool, error){
+	childLogger.Info().Str("func","HasCardToken").Ctx(ctx).Interface("trace-resquest-id", ctx.Value("trace-request-id")).Send()
+
+	//trace
+	ctx, span := startQuerySpan(ctx, "database.HasCardToken", "card_token.select_exists")
+	defer span.End()
+
+	// Prepare
+	conn, err := w.acquire(ctx, span)
+	if err != nil {
+		return false, err
+	}
+	defer w.DatabasePGServer.Release(conn)
+
+	query := `SELECT EXISTS (SELECT 1
+							FROM card_token
+							WHERE fk_id_card = $1
+							and token = $2)`
+
+	var exists bool
+	if err := conn.QueryRow(ctx, query, cardID, token).Scan(&exists); err != nil {
+		return false, queryError(span, err)
+	}
+
+	return exists, nil
+}
+
+// About get a card by its id (PK) and lock it until the end of the transaction
+func (w WorkerRepository) GetCardForUpdate(ctx context.Context, tx pgx.Tx, id int) (*model.Card, error){
+	childLogger.Info().Str("func","GetCardForUpdate").Ctx(ctx).Interface("trace-resquest-id", ctx.Value("trace-request-id")).Send()
+
+	// trace
+	ctx, span := startQuerySpan(ctx, "database.GetCardForUpdate", "card.select_for_update")
+	defer span.End()
+
+	res_card := model.Card{}
+
+	query := `SELECT  	cc.id,
+						cc.fk_account_id,
+						cc.card_number, 
+						cc.card_type,
+						cc.holder,
+						cc.card_model, 
+						cc.status,
+						cc.atc, 
+						cc.expired_at, 
+						cc.created_at,
+						cc.updated_at, 
+						cc.tenant_id,
+						COALESCE(cc.fk_predecessor_id, 0),
+						COALESCE(cc.reissue_reason, ''),
+						cc.cvv_failures,
+						COALESCE(cc.pin_pvv, ''),
+						COALESCE(cc.pin_pvki, 0),
+						cc.pin_failures
+				FROM card cc
+				WHERE cc.id = $1
+				FOR UPDATE`
+
+	// execute
+	err := tx.QueryRow(ctx, query, id).Scan(&res_card.ID,
+											&res_card.FkAccountID,
+											&res_card.CardNumber, 
+											&res_card.Type,
+											&res_card.Holder,
+											&res_card.Model,
+											&res_card.Status,	
+											&res_card.Atc,
+											&res_card.ExpiredAt,
+											&res_card.CreatedAt,
+											&res_card.UpdatedAt,
+											&res_card.TenantID,
+											&res_card.PredecessorID,
+											&res_card.ReissueReason,
+											&res_card.CvvFailures,
+											&res_card.PinPvv,
+											&res_card.PinPvki,
+											&res_card.PinFailures)
+	if errors.Is(err, pgx.ErrNoRows) {
+		setRowsAffected(span, 0)
+		return nil, erro.ErrNotFound
+	}
+	if err != nil {
+		return nil, queryError(span, err)
+	}
+	setRowsAffected(span, 1)
+
+	return &res_card, nil
+}
+
+// About update the status of a card
+func (w WorkerRepository) UpdateCardStatus(ctx context.Context, tx pgx.Tx, card model.Card) (int64, error){
+	childLogger.Info().Str("func","UpdateCardStatus").Ctx(ctx).Interface("trace-resquest-id", ctx.Value("trace-request-id")).Send()
+
+	// trace
+	ctx, span := startQuerySpan(ctx, "database.UpdateCardStatus", "card.update_status")
+	defer span.End()
+
+	query := `Update public.card
+				set status = $2, 
+					updated_at = $3
+				where id = $1`
+
+	// execute
+	row, err := tx.Exec(ctx, query, card.ID, card.Status, time.Now())
+	if err != nil {
+		return 0, queryError(span, err)
+	}
+	setRowsAffected(span, row.RowsAffected())
+
+	return row.RowsAffected(), nil
+}
+
+// About set the failed cvv verifications counter of a card
+func (w WorkerRepository) UpdateCardCvvFailures(ctx context.Context, tx pgx.Tx, card model.Card) (int64, error){
+	childLogger.Info().Str("func","UpdateCardCvvFailures").Ctx(ctx).Interface("trace-resquest-id", ctx.Value("trace-request-id")).Send()
+
+	// trace
+	ctx, span := startQuerySpan(ctx, "database.UpdateCardCvvFailures", "card.update_cvv_failures")
+	defer span.End()
+
+	query := `Update public.card
+				set cvv_failures = $2, 
+					updated_at = $3
+				where id = $1`
+
+	// execute
+	row, err := tx.Exec(ctx, query, card.ID, card.CvvFailures, time.Now())
+	if err != nil {
+		return 0, queryError(span, err)
+	}
+	setRowsAffected(span, row.RowsAffected())
+
+	return row.RowsAffected(), nil
+}
+
+// About set the pin verification value, the wrong pins counter and the status of a card
+func (w WorkerRepository) UpdateCardPin(ctx context.Context, tx pgx.Tx, card model.Card) (int64, error){
+	childLogger.Info().Str("func","UpdateCardPin").Ctx(ctx).Interface("trace-resquest-id", ctx.Value("trace-request-id")).Send()
+
+	// trace
+	ctx, span := startQuerySpan(ctx, "database.UpdateCardPin", "card.update_pin")
+	defer span.End()
+
+	query := `Update public.card
+				set pin_pvv = $2,
+					pin_pvki = $3,
+					pin_failures = $4,
+					status = $5,
+					updated_at = $6
+				where id = $1`
+
+	// execute
+	row, err := tx.Exec(ctx, query, card.ID, nullableString(card.PinPvv), card.PinPvki, card.PinFailures, card.Status, time.Now())
+	if err != nil {
+		return 0, queryError(span, err)
+	}
+	setRowsAffected(span, row.RowsAffected())
+
+	return row.RowsAffected(), nil
+}
+
+// About move the active tokens of a card to another card
+func (w WorkerRepository) MigrateCardToken(ctx context.Context, tx pgx.Tx, fromCardID int, toCardID int) (int64, error){
+	childLogger.Info().Str("func","MigrateCardToken").Ctx(ctx).Interface("trace-resquest-id", ctx.Value("trace-request-id")).Send()
+
+	// trace
+	ctx, span := startQuerySpan(ctx, "database.MigrateCardToken", "card_token.update_card")
+	defer span.End()
+
+	query := `Update public.card_token
+				set fk_id_card = $2, 
+					updated_at = $3
+				where fk_id_card = $1
+				and status = 'ACTIVE'`
+
+	// execute
+	row, err := tx.Exec(ctx, query, fromCardID, toCardID, time.Now())
+	if err != nil {
+		return 0, queryError(span, err)
+	}
+	setRowsAffected(span, row.RowsAffected())
+
+	return row.RowsAffected(), nil
+}
+
+// About change the status of the active tokens of a card
+func (w WorkerRepository) UpdateCardTokenStatus(ctx context.Context, tx pgx.Tx, cardID int, status string) (int64, error){
+	childLogger.Info().Str("func","UpdateCardTokenStatus").Ctx(ctx).Interface("trace-resquest-id", ctx.Value("trace-request-id")).Send()
+
+	// trace
+	ctx, span := startQuerySpan(ctx, "database.UpdateCardTokenStatus", "card_token.update_status")
+	defer span.End()
+
+	query := `Update public.card_token
+				set status = $2, 
+					updated_at = $3
+				where fk_id_card = $1
+				and status = 'ACTIVE'`
+
+	// execute
+	row, err := tx.Exec(ctx, query, cardID, status, time.Now())
+	if err != nil {
+		return 0, queryError(span, err)
+	}
+	setRowsAffected(span, row.RowsAffected())
+
+	return row.RowsAffected(), nil
+}
+
+// About expire a chunk of the cards past their expired_at
+// The rows locked by another pod are skipped (SKIP LOCKED), so several pods can run the job at once
+func (w WorkerRepository) ExpireCards(ctx context.Context, tx pgx.Tx, now time.Time, limit int) ([]model.Card, error){
+	childLogger.Info().Str("func","ExpireCards").Ctx(ctx).Interface("trace-resquest-id", ctx.Value("trace-request-id")).Send()
+
+	// trace
+	ctx, span := startQuerySpan(ctx, "database.ExpireCards", "card.update_expired")
+	defer span.End()
+
+	query := `Update public.card
+				set status = 'EXPIRED', 
+					updated_at = $1
+				where id in (SELECT id
+							FROM public.card
+							WHERE expired_at < $1
+							AND status not in ('EXPIRED', 'CANCELED')
+							ORDER BY id
+							LIMIT $2
+							FOR UPDATE SKIP LOCKED)
+				RETURNING id, fk_account_id, card_type, status, expired_at, tenant_id`
+
+	// execute
+	return w.scanJobCards(ctx, tx, span, query, now, limit)
+}
+
+// About flag a chunk of the cards expiring before until as renewal candidates
+// A card is flagged once (renewal_candidate_at), the rows locked by another pod are skipped
+func (w WorkerRepository) MarkRenewalCandidates(ctx context.Context, tx pgx.Tx, now time.Time, until time.Time, limit int) ([]model.Card, error){
+	childLogger.Info().Str("func","MarkRenewalCandidates").Ctx(ctx).Interface("trace-resquest-id", ctx.Value("trace-request-id")).Send()
+
+	// trace
+	ctx, span := startQuerySpan(ctx, "database.MarkRenewalCandidates", "card.update_renewal_candidate")
+	defer span.End()
+
+	query := `Update public.card
+				set renewal_candidate_at = $1
+				where id in (SELECT id
+							FROM public.card
+							WHERE expired_at >= $1
+							AND expired_at < $3
+							AND renewal_candidate_at is null
+							AND status not in ('EXPIRED', 'CANCELED')
+							ORDER BY id
+							LIMIT $2
+							FOR UPDATE SKIP LOCKED)
+				RETURNING id, fk_account_id, card_type, status, expired_at, tenant_id`
+
+	// execute
+	return w.scanJobCards(ctx, tx, span, query, now, limit, until)
+}
+
+// About scan the cards returned by a job query
+func (w WorkerRepository) scanJobCards(ctx context.Context, tx pgx.Tx, span trace.Span, query string, args ...interface{}) ([]model.Card, error){
+	rows, err := tx.Query(ctx, query, args...)
+	if err != nil {
+		return nil, queryError(span, err)
+	}
+	defer rows.Close()
+
+	res_card_list := []model.Card{}
+	for rows.Next() {
+		res_card := model.Card{}
+		err := rows.Scan(	&res_card.ID,
+							&res_card.FkAccountID,
+							&res_card.Type,
+							&res_card.Status,
+							&res_card.ExpiredAt,
+							&res_card.TenantID)
+		if err != nil {
+			return nil, queryError(span, err)
+		}
+		res_card_list = append(res_card_list, res_card)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, queryError(span, err)
+	}
+	setRowsAffected(span, int64(len(res_card_list)))
+
+	return res_card_list, nil
+}
+
+// About get the controls of a card, erro.ErrNotFound when the card has no controls
+func (w WorkerRepository) GetCardControl(ctx context.Context, cardID int) (*model.CardControl, error){
+	childLogger.Info().Str("func","GetCardControl").Ctx(ctx).Interface("trace-resquest-id", ctx.Value("trace-request-id")).Send()
+
+	// trace
+	ctx, span := startQuerySpan(ctx, "database.GetCardControl", "card_control.select")
+	defer span.End()
+
+	// prepare database
+	conn, err := w.acquire(ctx, span)
+	if err != nil {
+		return nil, err
+	}
+	defer w.DatabasePGServer.Release(conn)
+
+	return scanCardControl(conn.QueryRow(ctx, cardControlQuery, cardID), span)
+}
+
+// About get the controls of a card and lock them until the end of the transaction
+func (w WorkerRepository) GetCardControlForUpdate(ctx context.Context, tx pgx.Tx, cardID int) (*model.CardControl, error){
+	childLogger.Info().Str("func","GetCardControlForUpdate").Ctx(ctx).Interface("trace-resquest-id", ctx.Value("trace-request-id")).Send()
+
+	// trace
+	ctx, span := startQuerySpan(ctx, "database.GetCardControlForUpdate", "card_control.select_for_update")
+	defer span.End()
+
+	return scanCardControl(tx.QueryRow(ctx, cardControlQuery + ` FOR UPDATE`, cardID), span)
+}
+
+const cardControlQuery = `SELECT 	currency,
+									transaction_limit,
+									daily_limit,
+									monthly_limit,
+									mcc_allow,
+									mcc_deny,
+									ecommerce_enabled,
+									international_enabled,
+									atm_transaction_limit,
+									atm_daily_limit,
+									usage_date,
+									daily_spent,
+									monthly_spent,
+									atm_daily_spent,
+									updated_at
+							FROM card_control
+							WHERE fk_card_id = $1`
+
+func scanCardControl(row pgx.Row, span trace.Span) (*model.CardControl, error){
+	res_control := model.CardControl{}
+
+	err := row.Scan(&res_control.Currency,
+					&res_control.TransactionLimit,
+					&res_control.DailyLimit,
+					&res_control.MonthlyLimit,
+					&res_control.MccAllow,
+					&res_control.MccDeny,
+					&res_control.EcommerceEnabled,
+					&res_control.InternationalEnabled,
+					&res_control.AtmTransactionLimit,
+					&res_control.AtmDailyLimit,
+					&res_control.Usage.Date,
+					&res_control.Usage.DailySpent,
+					&res_control.Usage.MonthlySpent,
+					&res_control.Usage.AtmDailySpent,
+					&res_control.UpdatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		setRowsAffected(span, 0)
+		return nil, erro.ErrNotFound
+	}
+	if err != nil {
+		return nil, queryError(span, err)
+	}
+	setRowsAffected(span, 1)
+
+	return &res_control, nil
+}
+
+// About create or replace the controls of a card, the usage counters are kept
+func (w WorkerRepository) UpsertCardControl(ctx context.Context, tx pgx.Tx, cardID int, control model.CardControl) (*model.CardControl, error){
+	childLogger.Info().Str("func","UpsertCardControl").Ctx(ctx).Interface("trace-resquest-id", ctx.Value("trace-request-id")).Send()
+
+	// trace
+	ctx, span := startQuerySpan(ctx, "database.UpsertCardControl", "card_control.upsert")
+	defer span.End()
+
+	t_updateAt := time.Now()
+	control.UpdatedAt = &t_updateAt
+
+	query := `INSERT INTO card_control (fk_card_id,
+										currency,
+										transaction_limit,
+										daily_limit,
+										monthly_limit,
+										mcc_allow,
+										mcc_deny,
+										ecommerce_enabled,
+										international_enabled,
+										atm_transaction_limit,
+										atm_daily_limit,
+										updated_at)
+				VALUES($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+				ON CONFLICT (fk_card_id) DO UPDATE
+				SET currency = EXCLUDED.currency,
+					transaction_limit = EXCLUDED.transaction_limit,
+					daily_limit = EXCLUDED.daily_limit,
+					monthly_limit = EXCLUDED.monthly_limit,
+					mcc_allow = EXCLUDED.mcc_allow,
+					mcc_deny = EXCLUDED.mcc_deny,
+					ecommerce_enabled = EXCLUDED.ecommerce_enabled,
+					international_enabled = EXCLUDED.international_enabled,
+					atm_transaction_limit = EXCLUDED.atm_transaction_limit,
+					atm_daily_limit = EXCLUDED.atm_daily_limit,
+					updated_at = EXCLUDED.updated_at
+				RETURNING usage_date, daily_spent, monthly_spent, atm_daily_spent`
+
+	// execute
+	err := tx.QueryRow(ctx, query,	cardID,
+									control.Currency,
+									control.TransactionLimit,
+									control.DailyLimit,
+									control.MonthlyLimit,
+									nonNilStrings(control.MccAllow),
+									nonNilStrings(control.MccDeny),
+									control.EcommerceEnabled,
+									control.InternationalEnabled,
+									control.AtmTransactionLimit,
+									control.AtmDailyLimit,
+									control.UpdatedAt,
+									).Scan(	&control.Usage.Date,
+											&control.Usage.DailySpent,
+											&control.Usage.MonthlySpent,
+											&control.Usage.AtmDailySpent)
+	if err != nil {
+		return nil, queryError(span, err)
+	}
+	setRowsAffected(span, 1)
+
+	return &control, nil
+}
+
+// About copy the controls (and usage counters) of a card to another card (reissue)
+func (w WorkerRepository) CopyCardControl(ctx context.Context, tx pgx.Tx, fromCardID int, toCardID int) (int64, error){
+	childLogger.Info().Str("func","CopyCardControl").Ctx(ctx).Interface("trace-resquest-id", ctx.Value("trace-request-id")).Send()
+
+	// trace
+	ctx, span := startQuerySpan(ctx, "database.CopyCardControl", "card_control.copy")
+	defer span.End()
+
+	query := `INSERT INTO card_control (fk_card_id,
+										currency,
+										transaction_limit,
+										daily_limit,
+										monthly_limit,
+										mcc_allow,
+										mcc_deny,
+										ecommerce_enabled,
+										international_enabled,
+										atm_transaction_limit,
+										atm_daily_limit,
+										usage_date,
+										daily_spent,
+										monthly_spent,
+										atm_daily_spent,
+										updated_at)
+				SELECT $2,
+						currency,
+						transaction_limit,
+						daily_limit,
+						monthly_limit,
+						mcc_allow,
+						mcc_deny,
+						ecommerce_enabled,
+						international_enabled,
+						atm_transaction_limit,
+						atm_daily_limit,
+						usage_date,
+						daily_spent,
+						monthly_spent,
+						atm_daily_spent,
+						$3
+				FROM card_control
+				WHERE fk_card_id = $1`
+
+	// execute
+	row, err := tx.Exec(ctx, query, fromCardID, toCardID, time.Now())
+	if err != nil {
+		return 0, queryError(span, err)
+	}
+	setRowsAffected(span, row.RowsAffected())
+
+	return row.RowsAffected(), nil
+}
+
+// About set the usage counters of a card, the default controls are created when the card has none
+func (w WorkerRepository) UpdateCardControlUsage(ctx context.Context, tx pgx.Tx, cardID int, currency string, usage model.ControlUsage) (int64, error){
+	childLogger.Info().Str("func","UpdateCardControlUsage").Ctx(ctx).Interface("trace-resquest-id", ctx.Value("trace-request-id")).Send()
+
+	// trace
+	ctx, span := startQuerySpan(ctx, "database.UpdateCardControlUsage", "card_control.upsert_usage")
+	defer span.End()
+
+	query := `INSERT INTO card_control (fk_card_id,
+										currency,
+										usage_date,
+										daily_spent,
+										monthly_spent,
+										atm_daily_spent)
+				VALUES($1, $2, $3, $4, $5, $6)
+				ON CONFLICT (fk_card_id) DO UPDATE
+				SET usage_date = EXCLUDED.usage_date,
+					daily_spent = EXCLUDED.daily_spent,
+					monthly_spent = EXCLUDED.monthly_spent,
+					atm_daily_spent = EXCLUDED.atm_daily_spent`
+
+	// execute
+	row, err := tx.Exec(ctx, query, cardID,
+									currency,
+									usage.Date,
+									usage.DailySpent,
+									usage.MonthlySpent,
+									usage.AtmDailySpent)
+	if err != nil {
+		return 0, queryError(span, err)
+	}
+	setRowsAffected(span, row.RowsAffected())
+
+	return row.RowsAffected(), nil
+}
+
+// About set the atc of a card (atc of the chip)
+func (w WorkerRepository) UpdateCardAtc(ctx context.Context, tx pgx.Tx, card model.Card) (int64, error){
+	childLogger.Info().Str("func","UpdateCardAtc").Ctx(ctx).Interface("trace-resquest-id", ctx.Value("trace-request-id")).Send()
+
+	// trace
+	ctx, span := startQuerySpan(ctx, "database.UpdateCardAtc", "card.set_atc")
+	defer span.End()
+
+	query := `Update public.card
+				set atc = $2, 
+					updated_at = $3
+				where id = $1`
+
+	// execute
+	row, err := tx.Exec(ctx, query, card.ID, card.Atc, time.Now())
+	if err != nil {
+		return 0, queryError(span, err)
+	}
+	setRowsAffected(span, row.RowsAffected())
+
+	if row.RowsAffected() == 0 {
+		return 0, erro.ErrUpdateRows
+	}
+
+	return row.RowsAffected(), nil
+}
+
+// About record an authorization attempt
+func (w WorkerRepository) AddAuthorization(ctx context.Context, tx pgx.Tx, authorization model.Authorization) (*model.Authorization, error){
+	childLogger.Info().Str("func","AddAuthorization").Ctx(ctx).Interface("trace-resquest-id", ctx.Value("trace-request-id")).Send()
+
+	// trace
+	ctx, span := startQuerySpan(ctx, "database.AddAuthorization", "authorization.insert")
+	defer span.End()
+
+	authorization.CreatedAt = time.Now()
+
+	query := `INSERT INTO "authorization" (fk_card_id,
+										amount,
+										currency,
+										mcc,
+										channel,
+										merchant_id,
+										merchant_name,
+										atc,
+										response_code,
+										decision,
+										reasons,
+										trace_id,
+										created_at)
+				VALUES($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13) RETURNING id`
+
+	// execute
+	err := tx.QueryRow(ctx, query,	authorization.CardID,
+									authorization.Amount,
+									authorization.Currency,
+									authorization.Mcc,
+									authorization.Channel,
+									nullableString(authorization.MerchantID),
+									nullableString(authorization.MerchantName),
+									nullableID(authorization.Atc),
+									authorization.ResponseCode,
+									authorization.Decision,
+									nonNilStrings(authorization.Reasons),
+									nullableString(authorization.TraceID),
+									authorization.CreatedAt,
+									).Scan(&authorization.ID)
+	if err != nil {
+		return nil, queryError(span, err)
+	}
+	setRowsAffected(span, 1)
+
+	return &authorization, nil
+}
+
+// About list the authorizations of a card from the newest, created_at from from and before the position
+// of the after authorization (its created_at and id, the cursor of the previous page)
+func (w WorkerRepository) ListAuthorizations(ctx context.Context, cardID int, from time.Time, after model.Authorization, limit int) ([]model.Authorization, error){
+	childLogger.Info().Str("func","ListAuthorizations").Ctx(ctx).Interface("trace-resquest-id", ctx.Value("trace-request-id")).Send()
+
+	// trace
+	ctx, span := startQuerySpan(ctx, "database.ListAuthorizations", "authorization.select_by_card")
+	defer span.End()
+
+	// Prepare
+	conn, err := w.acquire(ctx, span)
+	if err != nil {
+		return nil, err
+	}
+	defer w.DatabasePGServer.Release(conn)
+
+	// the bounds of created_at prune the partitions, the row comparison is the position in the index
+	query := `SELECT	id,
+						fk_card_id,
+						amount,
+						currency,
+						mcc,
+						channel,
+						COALESCE(merchant_id, ''),
+						COALESCE(merchant_name, ''),
+						COALESCE(atc, 0),
+						response_code,
+						decision,
+						reasons,
+						COALESCE(trace_id, ''),
+						created_at
+				FROM "authorization"
+				WHERE fk_card_id = $1
+				and created_at >= $2
+				and created_at <= $3
+				and (created_at, id) < ($3, $4)
+				ORDER BY created_at DESC, id DESC
+				LIMIT $5`
+
+	rows, err := conn.Query(ctx, query, cardID, from, after.CreatedAt, after.ID, limit)
+	if err != nil {
+		return nil, queryError(span, err)
+	}
+	defer rows.Close()
+
+	res_authorization_list := []model.Authorization{}
+	for rows.Next() {
+		res_authorization := model.Authorization{}
+		err := rows.Scan(	&res_authorization.ID,
+							&res_authorization.CardID,
+							&res_authorization.Amount,
+							&res_authorization.Currency,
+							&res_authorization.Mcc,
+							&res_authorization.Channel,
+							&res_authorization.MerchantID,
+							&res_authorization.MerchantName,
+							&res_authorization.Atc,
+							&res_authorization.ResponseCode,
+							&res_authorization.Decision,
+							&res_authorization.Reasons,
+							&res_authorization.TraceID,
+							&res_authorization.CreatedAt)
+		if err != nil {
+			return nil, queryError(span, err)
+		}
+		res_authorization_list = append(res_authorization_list, res_authorization)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, queryError(span, err)
+	}
+	setRowsAffected(span, int64(len(res_authorization_list)))
+
+	return res_authorization_list, nil
+}
+
+// About create the missing monthly partitions of the authorizations from the month of from, returns the created count
+func (w WorkerRepository) CreateAuthorizationPartitions(ctx context.Context, from time.Time, months int) (int, error){
+	childLogger.Info().Str("func","CreateAuthorizationPartitions").Ctx(ctx).Send()
+
+	// trace
+	ctx, span := startQuerySpan(ctx, "database.CreateAuthorizationPartitions", "authorization.create_partitions")
+	defer span.End()
+
+	// Prepare
+	conn, err := w.acquire(ctx, span)
+	if err != nil {
+		return 0, err
+	}
+	defer w.DatabasePGServer.Release(conn)
+
+	created := 0
+	err = conn.QueryRow(ctx, `SELECT public.create_authorization_partitions($1, $2)`, from, months).Scan(&created)
+	if err != nil {
+		return 0, queryError(span, err)
+	}
+	setRowsAffected(span, int64(created))
+
+	return created, nil
+}
+
+// About an empty array for a nil slice (NOT NULL array columns)
+func nonNilStrings(s []string) []string {
+	if s == nil {
+		return []string{}
+	}
+	return s
+}
+
+// About a NULL for the zero id (no foreign key)
+func nullableID(id int) interface{} {
+	if id == 0 {
+		return nil
+	}
+	return id
+}
+
+// About a NULL for the empty string
+func nullableString(s string) interface{} {
+	if s == "" {
+		return nil
+	}
+	return s
 }
\ No newline at end of file
@@ -0,0 +1,47 @@
+package database
+
+import (
+	"time"
+	"context"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// About start a child span of a repository method, the returned context must be used by the query
+func startQuerySpan(ctx context.Context, spanName string, statementName string) (context.Context, trace.Span) {
+	ctx, span := tracerProvider.SpanCtx(ctx, spanName)
+	span.SetAttributes(
+		attribute.String("db.system", "postgresql"),
+		attribute.String("db.operation.name", statementName),
+	)
+	return ctx, span
+}
+
+// About acquire a connection from the pool, the wait time is recorded on the span
+func (w WorkerRepository) acquire(ctx context.Context, span trace.Span) (*pgxpool.Conn, error) {
+	start := time.Now()
+	conn, err := w.DatabasePGServer.Acquire(ctx)
+	span.SetAttributes(attribute.Int64("db.pool.wait_ms", time.Since(start).Milliseconds()))
+	if err != nil {
+		return nil, queryError(span, err)
+	}
+	return conn, nil
+}
+
+// About record the rows affected (or returned) by the query
+func setRowsAffected(span trace.Span, rows int64) {
+	span.SetAttributes(attribute.Int64("db.rows_affected", rows))
+}
+
+// About record the error on the span and log it
+// The error is returned as is, the callers match it (pgx.ErrNoRows, *pgconn.PgError)
+func queryError(span trace.Span, err error) error {
+	childLogger.Error().Err(err).Send()
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+	return err
+}
@@ -0,0 +1,28 @@
+package database
+
+import (
+	"fmt"
+	"errors"
+	"context"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestQueryErrorKeepsChain(t *testing.T) {
+	span := trace.SpanFromContext(context.Background())
+
+	err := queryError(span, fmt.Errorf("scan: %w", pgx.ErrNoRows))
+	if !errors.Is(err, pgx.ErrNoRows) {
+		t.Errorf("err %v, want pgx.ErrNoRows in the chain", err)
+	}
+
+	err = queryError(span, &pgconn.PgError{Code: "23505", Message: "duplicate key value"})
+	var pgError *pgconn.PgError
+	if !errors.As(err, &pgError) || pgError.Code != "23505" {
+		t.Errorf("err %v, want the *pgconn.PgError 23505", err)
+	}
+}
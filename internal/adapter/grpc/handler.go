@@ -0,0 +1,204 @@
+package grpc
+
+import (
+	"time"
+	"context"
+	"net/http"
+
+	"github.com/go-card/internal/adapter/api"
+	"github.com/go-card/internal/core/model"
+	"github.com/go-card/internal/core/service"
+	"github.com/go-card/internal/infra/logger"
+
+	cardv1 "github.com/go-card/proto/card/v1"
+
+	go_core_observ "github.com/eliezerraj/go-core/observability"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+var (
+	childLogger = logger.With().Str("component", "go-card").Str("package", "internal.adapter.grpc").Logger()
+	tracerProvider 	go_core_observ.TracerProvider
+)
+
+// About the grpc CardService, it calls the same WorkerService methods than the http api
+type CardServer struct {
+	cardv1.UnimplementedCardServiceServer
+	workerService 	*service.WorkerService
+	ctxTimeout		func() time.Duration
+}
+
+// About create the grpc CardService, the context timeout is shared with the http api (config reload)
+func NewCardServer(	workerService *service.WorkerService,
+					ctxTimeout func() time.Duration) *CardServer {
+	childLogger.Info().Str("func","NewCardServer").Send()
+
+	return &CardServer{
+		workerService: workerService,
+		ctxTimeout: ctxTimeout,
+	}
+}
+
+// About the grpc code of each http status of the problem
+var grpcCodes = map[int]codes.Code{
+	http.StatusBadRequest:			codes.InvalidArgument,
+	http.StatusUnauthorized:		codes.Unauthenticated,
+	http.StatusForbidden:			codes.PermissionDenied,
+	http.StatusNotFound:			codes.NotFound,
+	http.StatusConflict:			codes.Aborted,
+	http.StatusLocked:				codes.FailedPrecondition,
+	http.StatusTooManyRequests:		codes.ResourceExhausted,
+	http.StatusBadGateway:			codes.Unavailable,
+	http.StatusServiceUnavailable:	codes.Unavailable,
+	http.StatusGatewayTimeout:		codes.DeadlineExceeded,
+}
+
+// About convert an error to a grpc status, with the same mapping than the http ErrorHandler
+// The stable code is sent in an ErrorInfo detail, the field errors in a BadRequest detail
+func ErrorHandler(trace_id string, err error) error {
+	problem := api.NewProblem(trace_id, err)
+
+	code, ok := grpcCodes[problem.Status]
+	if !ok {
+		code = codes.Internal
+	}
+
+	st := status.New(code, problem.Detail)
+
+	errorInfo := &errdetails.ErrorInfo{
+		Reason: problem.Code,
+		Domain: "go-card",
+		Metadata: map[string]string{"trace_id": trace_id},
+	}
+	badRequest := &errdetails.BadRequest{}
+	for _, field := range problem.Errors {
+		badRequest.FieldViolations = append(badRequest.FieldViolations, &errdetails.BadRequest_FieldViolation{
+			Field: field.Field,
+			Description: field.Message,
+			Reason: field.Code,
+		})
+	}
+
+	var withDetails *status.Status
+	if len(badRequest.FieldViolations) > 0 {
+		withDetails, err = st.WithDetails(errorInfo, badRequest)
+	} else {
+		withDetails, err = st.WithDetails(errorInfo)
+	}
+	if err != nil {
+		childLogger.Error().Err(err).Msg("error add status details")
+		return st.Err()
+	}
+	return withDetails.Err()
+}
+
+// About convert a card to its protobuf message
+func toProto(card *model.Card) *cardv1.Card {
+	res := &cardv1.Card{
+		Id:				int64(card.ID),
+		FkAccountId:	int64(card.FkAccountID),
+		AccountId:		card.AccountID,
+		CardNumber:		card.CardNumber,
+		TokenData:		card.TokenData,
+		Holder:			card.Holder,
+		Type:			card.Type,
+		Model:			card.Model,
+		Atc:			int64(card.Atc),
+		Status:			card.Status,
+		ExpiredAt:		timestamppb.New(card.ExpiredAt),
+		CreatedAt:		timestamppb.New(card.CreatedAt),
+		TenantId:		card.TenantID,
+	}
+	if card.UpdatedAt != nil {
+		res.UpdatedAt = timestamppb.New(*card.UpdatedAt)
+	}
+	return res
+}
+
+// About get a card
+func (c *CardServer) GetCard(ctx context.Context, req *cardv1.GetCardRequest) (*cardv1.Card, error) {
+	childLogger.Info().Str("func","GetCard").Ctx(ctx).Interface("trace-resquest-id", ctx.Value("trace-request-id")).Send()
+
+	ctx, cancel := context.WithTimeout(ctx, c.ctxTimeout())
+	defer cancel()
+
+	ctx, span := tracerProvider.SpanCtx(ctx, "adapter.grpc.GetCard")
+	defer span.End()
+
+	trace_id := traceID(ctx)
+
+	err := api.ValidateCardNumber(req.GetCardNumber())
+	if err != nil {
+		return nil, ErrorHandler(trace_id, err)
+	}
+
+	card := model.Card{}
+	card.CardNumber = req.GetCardNumber()
+
+	res, err := c.workerService.GetCard(ctx, card)
+	if err != nil {
+		return nil, ErrorHandler(trace_id, err)
+	}
+
+	return toProto(res), nil
+}
+
+// About increment the atc of a card
+func (c *CardServer) IncrementAtc(ctx context.Context, req *cardv1.IncrementAtcRequest) (*cardv1.Card, error) {
+	childLogger.Info().Str("func","IncrementAtc").Ctx(ctx).Interface("trace-resquest-id", ctx.Value("trace-request-id")).Send()
+
+	ctx, cancel := context.WithTimeout(ctx, c.ctxTimeout())
+	defer cancel()
+
+	ctx, span := tracerProvider.SpanCtx(ctx, "adapter.grpc.IncrementAtc")
+	defer span.End()
+
+	trace_id := traceID(ctx)
+
+	err := api.ValidateCardNumber(req.GetCardNumber())
+	if err != nil {
+		return nil, ErrorHandler(trace_id, err)
+	}
+
+	card := model.Card{}
+	card.CardNumber = req.GetCardNumber()
+
+	res, err := c.workerService.UpdateCard(ctx, card)
+	if err != nil {
+		return nil, ErrorHandler(trace_id, err)
+	}
+
+	return toProto(res), nil
+}
+
+// About get the cards of a token
+func (c *CardServer) GetCardToken(ctx context.Context, req *cardv1.GetCardTokenRequest) (*cardv1.GetCardTokenResponse, error) {
+	childLogger.Info().Str("func","GetCardToken").Ctx(ctx).Interface("trace-resquest-id", ctx.Value("trace-request-id")).Send()
+
+	ctx, cancel := context.WithTimeout(ctx, c.ctxTimeout())
+	defer cancel()
+
+	ctx, span := tracerProvider.SpanCtx(ctx, "adapter.grpc.GetCardToken")
+	defer span.End()
+
+	trace_id := traceID(ctx)
+
+	card := model.Card{}
+	card.TokenData = req.GetToken()
+
+	res, err := c.workerService.GetCardToken(ctx, card)
+	if err != nil {
+		return nil, ErrorHandler(trace_id, err)
+	}
+
+	cards := make([]*cardv1.Card, 0, len(*res))
+	for i := range *res {
+		cards = append(cards, toProto(&(*res)[i]))
+	}
+
+	return &cardv1.GetCardTokenResponse{Cards: cards}, nil
+}
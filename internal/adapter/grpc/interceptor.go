@@ -0,0 +1,39 @@
+package grpc
+
+import (
+	"fmt"
+	"context"
+
+	"github.com/google/uuid"
+
+	google_grpc "google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+const requestIDHeader = "x-request-id"
+
+// About set the trace-request-id of the context (as the http header middleware), from x-request-id or a new uuid
+// The request id is sent back in the response header
+func RequestIDInterceptor(	ctx context.Context,
+							req interface{},
+							info *google_grpc.UnaryServerInfo,
+							handler google_grpc.UnaryHandler) (interface{}, error) {
+	requestID := ""
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(requestIDHeader); len(values) > 0 {
+			requestID = values[0]
+		}
+	}
+	if requestID == "" {
+		requestID = uuid.New().String()
+	}
+
+	google_grpc.SetHeader(ctx, metadata.Pairs(requestIDHeader, requestID))
+
+	ctx = context.WithValue(ctx, "trace-request-id", requestID)
+	return handler(ctx, req)
+}
+
+func traceID(ctx context.Context) string {
+	return fmt.Sprintf("%v", ctx.Value("trace-request-id"))
+}
@@ -0,0 +1,210 @@
+package iso8583
+
+import (
+	"fmt"
+	"time"
+	"errors"
+	"context"
+	"strconv"
+	"strings"
+	"encoding/hex"
+
+	"github.com/google/uuid"
+
+	"github.com/go-card/internal/adapter/api"
+	"github.com/go-card/internal/core/model"
+	"github.com/go-card/internal/core/service"
+	"github.com/go-card/internal/infra/logger"
+
+	core_iso8583 "github.com/go-card/internal/core/iso8583"
+	go_core_observ "github.com/eliezerraj/go-core/observability"
+)
+
+var (
+	childLogger = logger.With().Str("component", "go-card").Str("package", "internal.adapter.iso8583").Logger()
+	tracerProvider 	go_core_observ.TracerProvider
+
+	ErrUnsupportedMTI = errors.New("iso 8583 mti not supported")
+)
+
+const (
+	defaultCurrency	= "986" // BRL, when the DE49 is missing
+	defaultMcc		= "0000" // when the DE18 is missing
+)
+
+// About the response MTI of each request MTI
+var responseMTIs = map[string]string{
+	"0100":	"0110", // authorization
+	"0200":	"0210", // financial
+}
+
+// About the alpha code of the numeric currency codes (DE49, ISO 4217)
+var currencyCodes = map[string]string{
+	"986":	"BRL",
+	"840":	"USD",
+	"978":	"EUR",
+	"826":	"GBP",
+	"032":	"ARS",
+	"152":	"CLP",
+	"170":	"COP",
+	"484":	"MXN",
+	"604":	"PEN",
+	"858":	"UYU",
+}
+
+// the request fields sent back in the response
+var echoFields = []int{2, 3, 4, 7, 11, 12, 13, 32, 37, 41, 42, 49}
+
+// About the authorization of the iso 8583 messages, it calls the same Authorize than the http api
+type AuthorizationHandler struct {
+	workerService 	*service.WorkerService
+	ctxTimeout		func() time.Duration
+	spec			*core_iso8583.Spec
+}
+
+// About create the authorization handler, the context timeout is shared with the http api (config reload)
+func NewAuthorizationHandler(	workerService *service.WorkerService,
+								ctxTimeout func() time.Duration,
+								spec *core_iso8583.Spec) *AuthorizationHandler {
+	childLogger.Info().Str("func","NewAuthorizationHandler").Send()
+
+	return &AuthorizationHandler{
+		workerService: workerService,
+		ctxTimeout: ctxTimeout,
+		spec: spec,
+	}
+}
+
+// About authorize a 0100/0200 message and build its 0110/0210 response with the DE39
+// A message that can't be mapped is declined with 30 (format error), a failure of the authorization with 96
+func (h *AuthorizationHandler) Authorize(ctx context.Context, request *core_iso8583.Message) (*core_iso8583.Message, error) {
+	responseMTI, ok := responseMTIs[request.MTI]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedMTI, request.MTI)
+	}
+
+	ctx = context.WithValue(ctx, "trace-request-id", uuid.New().String())
+	childLogger.Info().Str("func","Authorize").Ctx(ctx).Interface("trace-resquest-id", ctx.Value("trace-request-id")).Str("mti", request.MTI).Send()
+
+	ctx, cancel := context.WithTimeout(ctx, h.ctxTimeout())
+	defer cancel()
+
+	ctx, span := tracerProvider.SpanCtx(ctx, "adapter.iso8583.Authorize")
+	defer span.End()
+
+	response := core_iso8583.NewMessage(responseMTI)
+	for _, number := range echoFields {
+		if value, ok := request.Get(number); ok {
+			if _, ok := h.spec.Fields[number]; ok {
+				response.Set(number, value)
+			}
+		}
+	}
+
+	card, authorize, err := toAuthorize(request)
+	if err != nil {
+		childLogger.Info().Ctx(ctx).Err(err).Msg("iso 8583 format error")
+		response.Set(core_iso8583.FieldResponseCode, []byte(service.ResponseFormatError))
+		return response, nil
+	}
+
+	res, err := h.workerService.Authorize(ctx, card, authorize)
+	if err != nil {
+		childLogger.Error().Ctx(ctx).Err(err).Msg("error authorize iso 8583 message")
+		response.Set(core_iso8583.FieldResponseCode, []byte(service.ResponseSystemError))
+		return response, nil
+	}
+
+	response.Set(core_iso8583.FieldResponseCode, []byte(res.ResponseCode))
+	if _, ok := h.spec.Fields[core_iso8583.FieldApprovalCode]; ok && res.ResponseCode == service.ResponseApproved {
+		response.Set(core_iso8583.FieldApprovalCode, []byte(fmt.Sprintf("%06d", res.ID % 1000000)))
+	}
+
+	return response, nil
+}
+
+// About map a message to the card and the transaction to authorize
+// DE2 card number, DE4 amount (minor units), DE18 mcc, DE42/DE43 merchant, DE49 currency, DE55 emv data,
+// the channel is ATM for a cash processing code (DE3 01), ECOMMERCE for a manual or e-commerce entry mode (DE22 01, 81)
+func toAuthorize(request *core_iso8583.Message) (model.Card, model.CardAuthorize, error) {
+	card := model.Card{}
+	authorize := model.CardAuthorize{}
+
+	pan, ok := request.Get(core_iso8583.FieldPAN)
+	if !ok {
+		return card, authorize, errors.New("missing DE2")
+	}
+	if err := api.ValidateCardNumber(string(pan)); err != nil {
+		return card, authorize, fmt.Errorf("DE2: %w", err)
+	}
+	card.CardNumber = string(pan)
+
+	amount, ok := request.Get(core_iso8583.FieldAmount)
+	if !ok || !isDigits(amount) {
+		return card, authorize, errors.New("missing or invalid DE4")
+	}
+	minorUnits, err := strconv.ParseInt(string(amount), 10, 64)
+	if err != nil || minorUnits <= 0 {
+		return card, authorize, errors.New("DE4 must be greater than 0")
+	}
+	authorize.Amount = minorUnits
+
+	currency := []byte(defaultCurrency)
+	if value, ok := request.Get(core_iso8583.FieldCurrency); ok {
+		currency = value
+	}
+	authorize.Currency, ok = currencyCodes[string(currency)]
+	if !ok {
+		return card, authorize, fmt.Errorf("DE49 %q is not supported", currency)
+	}
+
+	authorize.Mcc = defaultMcc
+	if value, ok := request.Get(core_iso8583.FieldMcc); ok {
+		if len(value) != 4 || !isDigits(value) {
+			return card, authorize, fmt.Errorf("DE18 %q is invalid", value)
+		}
+		authorize.Mcc = string(value)
+	}
+
+	processingCode, _ := request.Get(core_iso8583.FieldProcessingCode)
+	entryMode, _ := request.Get(core_iso8583.FieldPosEntryMode)
+	switch {
+	case strings.HasPrefix(string(processingCode), "01"):
+		authorize.Channel = "ATM"
+	case strings.HasPrefix(string(entryMode), "01") || strings.HasPrefix(string(entryMode), "81"):
+		authorize.Channel = "ECOMMERCE"
+	default:
+		authorize.Channel = "POS"
+	}
+
+	if value, ok := request.Get(core_iso8583.FieldMerchantID); ok {
+		authorize.MerchantID = truncate(strings.TrimSpace(string(value)), 15)
+	}
+	if value, ok := request.Get(core_iso8583.FieldMerchantName); ok {
+		authorize.MerchantName = truncate(strings.Join(strings.Fields(string(value)), " "), 40)
+	}
+
+	if value, ok := request.Get(core_iso8583.FieldEMV); ok {
+		authorize.Emv = hex.EncodeToString(value)
+	}
+
+	return card, authorize, nil
+}
+
+func isDigits(value []byte) bool {
+	for _, b := range value {
+		if b < '0' || b > '9' {
+			return false
+		}
+	}
+	return len(value) > 0
+}
+
+// the spec may allow longer merchant fields than the authorization, the invalid utf-8 bytes are dropped
+func truncate(value string, length int) string {
+	value = strings.ToValidUTF8(value, "")
+	if len(value) > length {
+		value = strings.ToValidUTF8(value[:length], "")
+	}
+	return value
+}
@@ -0,0 +1,92 @@
+package emv
+
+import (
+	"errors"
+	"strings"
+	"encoding/hex"
+	"encoding/binary"
+)
+
+// the application transaction counter tag, read by the authorization
+const TagATC = "9F36"
+
+var (
+	ErrInvalidTLV	= errors.New("invalid emv data, it must be BER-TLV encoded")
+	ErrMissingTag	= errors.New("emv tag not found")
+)
+
+// About parse the BER-TLV data of the chip (DE55), the tags are uppercase hex (ex: 9F36)
+// The constructed tags (templates) are parsed recursively, their content tags are returned with them
+func Parse(data []byte) (map[string][]byte, error) {
+	tags := map[string][]byte{}
+	if err := parse(data, tags); err != nil {
+		return nil, err
+	}
+	return tags, nil
+}
+
+func parse(data []byte, tags map[string][]byte) error {
+	for i := 0; i < len(data); {
+		// padding between the tags
+		if data[i] == 0x00 || data[i] == 0xff {
+			i++
+			continue
+		}
+
+		// tag, the low 5 bits 11111 announce a multi byte tag, bit 8 of the next bytes announces one more byte
+		start := i
+		constructed := data[i] & 0x20 != 0
+		if data[i] & 0x1f == 0x1f {
+			for i++; i < len(data) && data[i] & 0x80 != 0; i++ {
+			}
+		}
+		i++
+		if i > len(data) {
+			return ErrInvalidTLV
+		}
+		tag := strings.ToUpper(hex.EncodeToString(data[start:i]))
+
+		// length, short form (< 128) or long form (0x81, 0x82)
+		if i >= len(data) {
+			return ErrInvalidTLV
+		}
+		length := int(data[i])
+		i++
+		if length & 0x80 != 0 {
+			size := length & 0x7f
+			if size == 0 || size > 2 || i + size > len(data) {
+				return ErrInvalidTLV
+			}
+			length = 0
+			for _, b := range data[i:i + size] {
+				length = length << 8 | int(b)
+			}
+			i = i + size
+		}
+		if i + length > len(data) {
+			return ErrInvalidTLV
+		}
+
+		value := data[i:i + length]
+		tags[tag] = value
+		if constructed {
+			if err := parse(value, tags); err != nil {
+				return err
+			}
+		}
+		i = i + length
+	}
+	return nil
+}
+
+// About the application transaction counter (9F36, 2 bytes) of the parsed tags
+func ATC(tags map[string][]byte) (int, error) {
+	value, ok := tags[TagATC]
+	if !ok {
+		return 0, ErrMissingTag
+	}
+	if len(value) != 2 {
+		return 0, ErrInvalidTLV
+	}
+	return int(binary.BigEndian.Uint16(value)), nil
+}
@@ -2,6 +2,7 @@ package erro
 
 import (
 	"errors"
+	"strings"
 )
 
 var (
@@ -14,4 +15,92 @@ var (
 	ErrServer		 	= errors.New("server identified error")
 	ErrTimeout			= errors.New("timeout: context deadline exceeded")
 	ErrHealthCheck		= errors.New("health check services required failed")
-)
\ No newline at end of file
+	ErrServiceNotConfigured	= errors.New("downstream service not configured")
+	ErrCircuitOpen		= errors.New("downstream service unavailable: circuit breaker open")
+	ErrDownstream		= errors.New("downstream service error")
+	ErrValidation		= errors.New("validation failed ! check fields")
+	ErrTooManyRequests	= errors.New("too many requests")
+	ErrCardCanceled		= errors.New("card is canceled")
+	ErrCardLocked		= errors.New("card is locked, too many failed verifications")
+	ErrCardNotVirtual	= errors.New("dynamic cvv is only available for VIRTUAL cards")
+	ErrCardBlocked		= errors.New("card is blocked, too many wrong pins")
+	ErrPinAlreadySet	= errors.New("the card already has a pin, use the pin change")
+	ErrPinNotSet		= errors.New("the card has no pin")
+	ErrPinInvalid		= errors.New("wrong pin")
+	ErrHSMUnavailable	= errors.New("card keys unavailable, the hsm has no key store")
+)
+
+// About the stable machine-readable code of each error, it must never change once published
+// An error can wrap more than one error of the taxonomy, the first one of the list wins, so the
+// specific errors come before the generic ones
+var codes = []struct {
+	err		error
+	code	string
+}{
+	{ErrValidation,				"VALIDATION_FAILED"},
+	{ErrCardCanceled,			"CARD_CANCELED"},
+	{ErrCardLocked,				"CARD_LOCKED"},
+	{ErrCardNotVirtual,			"CARD_NOT_VIRTUAL"},
+	{ErrCardBlocked,			"CARD_BLOCKED"},
+	{ErrPinAlreadySet,			"PIN_ALREADY_SET"},
+	{ErrPinNotSet,				"PIN_NOT_SET"},
+	{ErrPinInvalid,				"PIN_INVALID"},
+	{ErrHSMUnavailable,			"HSM_UNAVAILABLE"},
+	{ErrCircuitOpen,			"CIRCUIT_OPEN"},
+	{ErrServiceNotConfigured,	"SERVICE_NOT_CONFIGURED"},
+	{ErrTimeout,				"TIMEOUT"},
+	{ErrTooManyRequests,		"TOO_MANY_REQUESTS"},
+	{ErrHealthCheck,			"HEALTH_CHECK_FAILED"},
+	{ErrDownstream,				"DOWNSTREAM_ERROR"},
+	{ErrNotFound,				"NOT_FOUND"},
+	{ErrBadRequest,				"BAD_REQUEST"},
+	{ErrUpdateRows,				"UPDATE_NO_ROWS"},
+	{ErrUpdate,					"UPDATE_FAILED"},
+	{ErrHTTPForbiden,			"FORBIDDEN"},
+	{ErrUnauthorized,			"UNAUTHORIZED"},
+	{ErrServer,					"SERVER_ERROR"},
+}
+
+// About the error of the taxonomy wrapped by an error, nil when the error is not part of the taxonomy
+func Taxonomy(err error) error {
+	for _, code := range codes {
+		if errors.Is(err, code.err) {
+			return code.err
+		}
+	}
+	return nil
+}
+
+// About the code of the error, INTERNAL_ERROR when the error is not part of the taxonomy
+func Code(err error) string {
+	for _, code := range codes {
+		if errors.Is(err, code.err) {
+			return code.code
+		}
+	}
+	return "INTERNAL_ERROR"
+}
+
+// About an error on a single field of the payload
+type FieldError struct {
+	Field		string `json:"field"`
+	Code		string `json:"code"`
+	Message		string `json:"message"`
+}
+
+// About all the field errors of a payload, it matches ErrValidation with errors.Is
+type ValidationError struct {
+	Fields		[]FieldError
+}
+
+func (e *ValidationError) Error() string {
+	messages := make([]string, 0, len(e.Fields))
+	for _, field := range e.Fields {
+		messages = append(messages, field.Field + ": " + field.Message)
+	}
+	return ErrValidation.Error() + " => " + strings.Join(messages, "; ")
+}
+
+func (e *ValidationError) Unwrap() error {
+	return ErrValidation
+}
\ No newline at end of file
@@ -0,0 +1,81 @@
+package hsm
+
+import (
+	"errors"
+	"strings"
+	"crypto/des"
+	"crypto/subtle"
+	"encoding/hex"
+)
+
+// the service code of a CVV2 (card not present)
+const ServiceCodeCVV2 = "000"
+
+var ErrInvalidCVVData = errors.New("invalid cvv data, the pan must have 13 to 19 digits, the expiry 4 digits (YYMM) and the service code 3 digits")
+
+// About generate a CVV with the Visa method (also used for the CVC2)
+// The PAN, expiry (YYMM) and service code are padded with zeros to two 8 bytes blocks,
+// the first block is DES encrypted with the left half of the CVK, XORed with the second block
+// and triple DES encrypted with the CVK, the result is decimalized and the 3 first digits are the CVV
+func generateCVV(cvk []byte, pan string, expiry string, serviceCode string) (string, error) {
+	if len(cvk) != 16 {
+		return "", ErrInvalidKey
+	}
+	if !isDigits(pan) || len(pan) < 13 || len(pan) > 19 || !isDigits(expiry) || len(expiry) != 4 || !isDigits(serviceCode) || len(serviceCode) != 3 {
+		return "", ErrInvalidCVVData
+	}
+
+	data := pan + expiry + serviceCode
+	data = data + strings.Repeat("0", 32 - len(data))
+	block, err := hex.DecodeString(data)
+	if err != nil {
+		return "", err
+	}
+
+	keyA, err := des.NewCipher(cvk[:8])
+	if err != nil {
+		return "", err
+	}
+	keyB, err := des.NewCipher(cvk[8:])
+	if err != nil {
+		return "", err
+	}
+
+	result := make([]byte, 8)
+	keyA.Encrypt(result, block[:8])
+	subtle.XORBytes(result, result, block[8:])
+	keyA.Encrypt(result, result)
+	keyB.Decrypt(result, result)
+	keyA.Encrypt(result, result)
+
+	return decimalize(hex.EncodeToString(result), 3), nil
+}
+
+// About decimalize an hex string: the digits from left to right, then the letters minus 10
+func decimalize(hexString string, length int) string {
+	hexString = strings.ToUpper(hexString)
+	result := make([]byte, 0, length)
+	for i := 0; i < len(hexString) && len(result) < length; i++ {
+		if hexString[i] <= '9' {
+			result = append(result, hexString[i])
+		}
+	}
+	for i := 0; i < len(hexString) && len(result) < length; i++ {
+		if hexString[i] >= 'A' {
+			result = append(result, hexString[i] - 'A' + '0')
+		}
+	}
+	return string(result)
+}
+
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
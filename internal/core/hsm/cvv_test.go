@@ -0,0 +1,94 @@
+package hsm
+
+import (
+	"time"
+	"errors"
+	"testing"
+)
+
+func TestGenerateCVV(t *testing.T) {
+	tests := []struct {
+		name		string
+		pan			string
+		expiry		string
+		serviceCode	string
+		cvv			string
+	}{
+		// the Visa CVV example (PAN 4123456789012345, expiry 8701, service code 101)
+		{"cvv", "4123456789012345", "8701", "101", "561"},
+		{"cvv2", "4123456789012345", "8701", ServiceCodeCVV2, "636"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cvv, err := generateCVV(mustHex(t, testTDESKey), test.pan, test.expiry, test.serviceCode)
+			if err != nil {
+				t.Fatalf("generate cvv: %v", err)
+			}
+			if cvv != test.cvv {
+				t.Errorf("cvv %s, want %s", cvv, test.cvv)
+			}
+		})
+	}
+}
+
+func TestGenerateCVVInvalid(t *testing.T) {
+	tests := []struct {
+		name		string
+		pan			string
+		expiry		string
+		serviceCode	string
+	}{
+		{"short pan", "412345678901", "8701", "101"},
+		{"long pan", "41234567890123456789", "8701", "101"},
+		{"pan with letters", "41234567890A2345", "8701", "101"},
+		{"expiry", "4123456789012345", "870", "101"},
+		{"service code", "4123456789012345", "8701", "10"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if _, err := generateCVV(mustHex(t, testTDESKey), test.pan, test.expiry, test.serviceCode); !errors.Is(err, ErrInvalidCVVData) {
+				t.Errorf("err %v, want %v", err, ErrInvalidCVVData)
+			}
+		})
+	}
+	if _, err := generateCVV(mustHex(t, testAESKey)[:8], "4123456789012345", "8701", "101"); !errors.Is(err, ErrInvalidKey) {
+		t.Errorf("short key err %v, want %v", err, ErrInvalidKey)
+	}
+}
+
+func TestVerifyCVVRotation(t *testing.T) {
+	softwareHSM, keyStore := newTestHSM(t)
+
+	verify := func(cvv string, want bool) {
+		t.Helper()
+		verified, err := softwareHSM.VerifyCVV("4123456789012345", "8701", "101", cvv)
+		if err != nil {
+			t.Fatalf("verify cvv: %v", err)
+		}
+		if verified != want {
+			t.Errorf("verify cvv %s = %v, want %v", cvv, verified, want)
+		}
+	}
+	verify("561", true)
+	verify("562", false)
+
+	// the cvv of the previous version is verified until it is retired
+	if _, err := keyStore.Rotate(KeyCVK, AlgorithmTDES, nil, time.Now()); err != nil {
+		t.Fatalf("rotate: %v", err)
+	}
+	cvv, err := softwareHSM.GenerateCVV("4123456789012345", "8701", "101")
+	if err != nil {
+		t.Fatalf("generate cvv: %v", err)
+	}
+	verify(cvv, true)
+	verify("561", true)
+
+	if err := keyStore.Retire(KeyCVK, 1, time.Now()); err != nil {
+		t.Fatalf("retire: %v", err)
+	}
+	if cvv != "561" {
+		verify("561", false)
+	}
+}
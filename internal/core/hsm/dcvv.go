@@ -0,0 +1,36 @@
+package hsm
+
+import (
+	"fmt"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+)
+
+// About generate the dynamic cvv of a card for a time window (counter)
+// The card secret is derived from the master key and the PAN (HMAC-SHA256), so it is never stored,
+// the code is the HMAC-SHA256 of the window with the card secret, truncated as RFC 4226 (HOTP) to 3 digits
+func generateDynamicCVV(dcvk []byte, pan string, counter uint64) (string, error) {
+	if len(dcvk) == 0 {
+		return "", ErrInvalidKey
+	}
+	if !isDigits(pan) || len(pan) < 13 || len(pan) > 19 {
+		return "", ErrInvalidCVVData
+	}
+
+	derive := hmac.New(sha256.New, dcvk)
+	derive.Write([]byte(pan))
+	cardSecret := derive.Sum(nil)
+
+	message := make([]byte, 8)
+	binary.BigEndian.PutUint64(message, counter)
+	mac := hmac.New(sha256.New, cardSecret)
+	mac.Write(message)
+	sum := mac.Sum(nil)
+
+	// dynamic truncation
+	offset := sum[len(sum) - 1] & 0x0f
+	code := binary.BigEndian.Uint32(sum[offset:offset + 4]) & 0x7fffffff
+
+	return fmt.Sprintf("%03d", code % 1000), nil
+}
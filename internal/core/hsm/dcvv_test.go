@@ -0,0 +1,64 @@
+package hsm
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestGenerateDynamicCVV(t *testing.T) {
+	// adjacent windows (counters), the code of each window is different
+	tests := []struct {
+		counter	uint64
+		dcvv	string
+	}{
+		{5836799, "466"},
+		{5836800, "547"},
+		{5836801, "602"},
+		{5836802, "142"},
+	}
+
+	for _, test := range tests {
+		dcvv, err := generateDynamicCVV(mustHex(t, testHMACKey), "4123456789012345", test.counter)
+		if err != nil {
+			t.Fatalf("generate dcvv: %v", err)
+		}
+		if dcvv != test.dcvv {
+			t.Errorf("counter %d dcvv %s, want %s", test.counter, dcvv, test.dcvv)
+		}
+	}
+
+	if _, err := generateDynamicCVV(mustHex(t, testHMACKey), "412345678901", 5836800); !errors.Is(err, ErrInvalidCVVData) {
+		t.Errorf("short pan err %v, want %v", err, ErrInvalidCVVData)
+	}
+	if _, err := generateDynamicCVV(nil, "4123456789012345", 5836800); !errors.Is(err, ErrInvalidKey) {
+		t.Errorf("no key err %v, want %v", err, ErrInvalidKey)
+	}
+}
+
+func TestVerifyDynamicCVVWindows(t *testing.T) {
+	softwareHSM, _ := newTestHSM(t)
+
+	// the dcvv of the window 5836800 is accepted in its window and the next one only
+	tests := []struct {
+		name	string
+		counter	uint64
+		want	bool
+	}{
+		{"previous window", 5836799, false},
+		{"same window", 5836800, true},
+		{"next window", 5836801, true},
+		{"2 windows later", 5836802, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			verified, err := softwareHSM.VerifyDynamicCVV("4123456789012345", test.counter, "547")
+			if err != nil {
+				t.Fatalf("verify dcvv: %v", err)
+			}
+			if verified != test.want {
+				t.Errorf("verify dcvv = %v, want %v", verified, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,65 @@
+package hsm
+
+import (
+	"time"
+)
+
+// the algorithms of the keys
+const (
+	AlgorithmTDES	= "TDES" // double or triple length DES
+	AlgorithmAES	= "AES"
+	AlgorithmHMAC	= "HMAC" // HMAC-SHA256
+)
+
+// the status of a key version, a rotation keeps the previous version to verify the existing values
+const (
+	KeyStatusActive		= "ACTIVE" // generate and verify
+	KeyStatusVerifyOnly	= "VERIFY_ONLY" // verify and decrypt only
+	KeyStatusRetired	= "RETIRED" // not usable
+)
+
+// About the metadata of a key version, the value never leaves the HSM
+type KeyInfo struct {
+	Name		string		`json:"name"`
+	Version		int			`json:"version"`
+	Algorithm	string		`json:"algorithm"`
+	KCV			string		`json:"kcv"`
+	Status		string		`json:"status"`
+	CreatedAt	time.Time	`json:"created_at"`
+	RotatedAt	*time.Time	`json:"rotated_at,omitempty"`
+	RetiredAt	*time.Time	`json:"retired_at,omitempty"`
+}
+
+// About a key derived by the HSM, it is returned encrypted under the HSM storage key
+type DerivedKey struct {
+	KeyBlock	[]byte
+	KCV			string
+}
+
+// About the pin operations of a HSM, the clear pin never leaves it
+type PinHSM interface {
+	// the PVV of the pin of an encrypted pin block with the active PVK, the PVKI is the last digit of its version
+	GeneratePVV(pinBlock []byte, format int, pan string) (pvv string, pvki int, err error)
+	// check the pin of an encrypted pin block against the PVV of the PVK version pvki
+	VerifyPIN(pinBlock []byte, format int, pan string, pvki int, pvv string) (bool, error)
+}
+
+// About the cryptographic operations of the card, a real HSM adapter implements the same interface
+// The keys are referenced by name, the HSM picks the active version to generate and tries the
+// active and verify only versions to verify or decrypt (key rotation)
+type HSM interface {
+	PinHSM
+	// derive a key from a master key (EMV option A for the TDES keys, HMAC-SHA256 otherwise)
+	DeriveKey(keyName string, data []byte) (*DerivedKey, error)
+	// encrypt with an AES key (AES-GCM), the key version is part of the ciphertext
+	Encrypt(keyName string, plaintext []byte) ([]byte, error)
+	Decrypt(keyName string, ciphertext []byte) ([]byte, error)
+	// MAC with the active key (HMAC-SHA256, AES-CMAC or ISO 9797-1 algorithm 3 for TDES)
+	MAC(keyName string, data []byte) ([]byte, error)
+	GenerateCVV(pan string, expiry string, serviceCode string) (string, error)
+	VerifyCVV(pan string, expiry string, serviceCode string, cvv string) (bool, error)
+	GenerateDynamicCVV(pan string, counter uint64) (string, error)
+	VerifyDynamicCVV(pan string, counter uint64, dcvv string) (bool, error)
+	// the metadata of all the key versions
+	Keys() []KeyInfo
+}
@@ -0,0 +1,50 @@
+package hsm
+
+import (
+	"time"
+	"testing"
+	"encoding/hex"
+)
+
+// the keys of the tests, the TDES key is the one of the published Visa CVV example
+const (
+	testTDESKey		= "0123456789ABCDEFFEDCBA9876543210"
+	testAESKey		= "000102030405060708090A0B0C0D0E0F"
+	testHMACKey		= "000102030405060708090A0B0C0D0E0F101112131415161718191A1B1C1D1E1F"
+	testMasterKey	= "1F1E1D1C1B1A191817161514131211100F0E0D0C0B0A09080706050403020100"
+)
+
+func mustHex(t *testing.T, value string) []byte {
+	t.Helper()
+	res, err := hex.DecodeString(value)
+	if err != nil {
+		t.Fatalf("invalid hex %s: %v", value, err)
+	}
+	return res
+}
+
+// About a software HSM with a version 1 of every required key
+func newTestHSM(t *testing.T) (*SoftwareHSM, *SoftwareKeyStore) {
+	t.Helper()
+
+	values := map[string]string{
+		AlgorithmTDES:	testTDESKey,
+		AlgorithmAES:	testAESKey,
+		AlgorithmHMAC:	testHMACKey,
+	}
+	keyStore := NewSoftwareKeyStore()
+	for name, algorithm := range RequiredKeys {
+		if _, err := keyStore.Rotate(name, algorithm, mustHex(t, values[algorithm]), time.Now()); err != nil {
+			t.Fatalf("rotate %s: %v", name, err)
+		}
+	}
+	if err := keyStore.Check(RequiredKeys); err != nil {
+		t.Fatalf("check: %v", err)
+	}
+
+	softwareHSM, err := NewSoftwareHSM(keyStore, mustHex(t, testMasterKey))
+	if err != nil {
+		t.Fatalf("new software hsm: %v", err)
+	}
+	return softwareHSM, keyStore
+}
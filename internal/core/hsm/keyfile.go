@@ -0,0 +1,190 @@
+package hsm
+
+import (
+	"os"
+	"fmt"
+	"time"
+	"errors"
+	"strconv"
+	"crypto/aes"
+	"crypto/rand"
+	"crypto/cipher"
+	"encoding/json"
+	"path/filepath"
+)
+
+// the format version of the key file
+const keyFileVersion = 1
+
+var ErrInvalidMasterKey = errors.New("invalid master key, it must be an AES-256 key (32 bytes)")
+
+// About the key file, the values are encrypted (AES-256-GCM) with the master key, the metadata is in clear
+type keyFile struct {
+	Version		int				`json:"version"`
+	Keys		[]keyFileEntry	`json:"keys"`
+}
+
+type keyFileEntry struct {
+	KeyInfo
+	Value		[]byte			`json:"value"` // nonce and encrypted value
+}
+
+// About the AES-GCM of the master key
+func masterAEAD(masterKey []byte) (cipher.AEAD, error) {
+	if len(masterKey) != 32 {
+		return nil, ErrInvalidMasterKey
+	}
+	block, err := aes.NewCipher(masterKey)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// About the additional data of an encrypted value, an entry can't be moved to another key or version
+func keyAAD(info KeyInfo) []byte {
+	return []byte(info.Name + ":" + strconv.Itoa(info.Version) + ":" + info.Algorithm)
+}
+
+// About load and decrypt the key file, every key is checked with its KCV
+func LoadKeyFile(path string, masterKey []byte) (*SoftwareKeyStore, error) {
+	aead, err := masterAEAD(masterKey)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var file keyFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("invalid key file %s: %w", path, err)
+	}
+	if file.Version != keyFileVersion {
+		return nil, fmt.Errorf("invalid key file %s: unsupported version %d", path, file.Version)
+	}
+
+	store := NewSoftwareKeyStore()
+	for _, entry := range file.Keys {
+		if len(entry.Value) < aead.NonceSize() {
+			return nil, fmt.Errorf("%s v%d: invalid encrypted value", entry.Name, entry.Version)
+		}
+		value, err := aead.Open(nil, entry.Value[:aead.NonceSize()], entry.Value[aead.NonceSize():], keyAAD(entry.KeyInfo))
+		if err != nil {
+			return nil, fmt.Errorf("%s v%d: the value can't be decrypted with the master key", entry.Name, entry.Version)
+		}
+		if err := store.add(entry.KeyInfo, value); err != nil {
+			return nil, err
+		}
+		clear(value)
+	}
+	return store, nil
+}
+
+// About encrypt the key store and write the key file, the file is replaced atomically
+func (k *SoftwareKeyStore) SaveKeyFile(path string, masterKey []byte) error {
+	aead, err := masterAEAD(masterKey)
+	if err != nil {
+		return err
+	}
+
+	file := keyFile{Version: keyFileVersion, Keys: []keyFileEntry{}}
+	for _, info := range k.Keys() {
+		key, err := k.version(info.Name, info.Version)
+		if err != nil {
+			return err
+		}
+		nonce := make([]byte, aead.NonceSize())
+		if _, err := rand.Read(nonce); err != nil {
+			return err
+		}
+		file.Keys = append(file.Keys, keyFileEntry{
+			KeyInfo:	key.KeyInfo,
+			Value:		aead.Seal(nonce, nonce, key.value, keyAAD(key.KeyInfo)),
+		})
+	}
+
+	data, err := json.MarshalIndent(file, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".keys-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// About a version of a key, whatever its status
+func (k *SoftwareKeyStore) version(name string, version int) (*Key, error) {
+	for _, key := range k.keys[name] {
+		if key.Version == version {
+			return key, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %s v%d", ErrKeyNotFound, name, version)
+}
+
+// About add a new active version of a key (rotation), the previous active version becomes verify only
+// A nil value generates a random key, the PVK versions are limited to 9 (PVKI)
+func (k *SoftwareKeyStore) Rotate(name string, algorithm string, value []byte, now time.Time) (*KeyInfo, error) {
+	if value == nil {
+		size := 16
+		if algorithm == AlgorithmHMAC || algorithm == AlgorithmAES {
+			size = 32
+		}
+		value = make([]byte, size)
+		if _, err := rand.Read(value); err != nil {
+			return nil, err
+		}
+		defer clear(value)
+	}
+
+	version := 1
+	if len(k.keys[name]) > 0 {
+		version = k.keys[name][len(k.keys[name]) - 1].Version + 1
+	}
+
+	kcv, err := KCV(algorithm, value)
+	if err != nil {
+		return nil, err
+	}
+
+	previous, _ := k.ActiveKey(name)
+	if previous != nil {
+		previous.Status = KeyStatusVerifyOnly
+		previous.RotatedAt = &now
+	}
+	info := KeyInfo{Name: name, Version: version, Algorithm: algorithm, KCV: kcv, Status: KeyStatusActive, CreatedAt: now}
+	if err := k.add(info, value); err != nil {
+		if previous != nil {
+			previous.Status = KeyStatusActive
+			previous.RotatedAt = nil
+		}
+		return nil, err
+	}
+	return &info, nil
+}
+
+// About retire a verify only version, the values generated with it can't be verified anymore
+func (k *SoftwareKeyStore) Retire(name string, version int, now time.Time) error {
+	key, err := k.version(name, version)
+	if err != nil {
+		return err
+	}
+	if key.Status != KeyStatusVerifyOnly {
+		return fmt.Errorf("%s v%d is %s, only a verify only version can be retired", name, version, key.Status)
+	}
+	key.Status = KeyStatusRetired
+	key.RetiredAt = &now
+	return nil
+}
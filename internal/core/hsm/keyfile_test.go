@@ -0,0 +1,108 @@
+package hsm
+
+import (
+	"os"
+	"time"
+	"errors"
+	"strings"
+	"testing"
+	"path/filepath"
+)
+
+func TestKeyFileRoundTrip(t *testing.T) {
+	softwareHSM, keyStore := newTestHSM(t)
+	if _, err := keyStore.Rotate(KeyPVK, AlgorithmTDES, nil, time.Now()); err != nil {
+		t.Fatalf("rotate: %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "keys.json")
+	if err := keyStore.SaveKeyFile(path, mustHex(t, testMasterKey)); err != nil {
+		t.Fatalf("save key file: %v", err)
+	}
+
+	// the values are never written in clear
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read key file: %v", err)
+	}
+	if strings.Contains(strings.ToUpper(string(data)), testTDESKey) {
+		t.Errorf("the key file has a clear key")
+	}
+
+	loaded, err := LoadKeyFile(path, mustHex(t, testMasterKey))
+	if err != nil {
+		t.Fatalf("load key file: %v", err)
+	}
+	if len(loaded.Keys()) != len(keyStore.Keys()) {
+		t.Fatalf("keys %d, want %d", len(loaded.Keys()), len(keyStore.Keys()))
+	}
+	for i, info := range keyStore.Keys() {
+		got := loaded.Keys()[i]
+		if got.Name != info.Name || got.Version != info.Version || got.KCV != info.KCV || got.Status != info.Status {
+			t.Errorf("key %+v, want %+v", got, info)
+		}
+	}
+
+	// the loaded keys give the same values
+	loadedHSM, err := NewSoftwareHSM(loaded, mustHex(t, testMasterKey))
+	if err != nil {
+		t.Fatalf("new software hsm: %v", err)
+	}
+	cvv, _ := softwareHSM.GenerateCVV("4123456789012345", "8701", "101")
+	if loadedCVV, _ := loadedHSM.GenerateCVV("4123456789012345", "8701", "101"); loadedCVV != cvv {
+		t.Errorf("cvv %s, want %s", loadedCVV, cvv)
+	}
+}
+
+func TestKeyFileInvalid(t *testing.T) {
+	_, keyStore := newTestHSM(t)
+	path := filepath.Join(t.TempDir(), "keys.json")
+	if err := keyStore.SaveKeyFile(path, mustHex(t, testMasterKey)); err != nil {
+		t.Fatalf("save key file: %v", err)
+	}
+
+	// wrong master key
+	if _, err := LoadKeyFile(path, mustHex(t, testHMACKey)); err == nil || !strings.Contains(err.Error(), "can't be decrypted") {
+		t.Errorf("wrong master key err %v", err)
+	}
+	if _, err := LoadKeyFile(path, mustHex(t, testAESKey)); !errors.Is(err, ErrInvalidMasterKey) {
+		t.Errorf("short master key err %v, want %v", err, ErrInvalidMasterKey)
+	}
+	if _, err := LoadKeyFile(filepath.Join(t.TempDir(), "missing.json"), mustHex(t, testMasterKey)); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("missing key file err %v, want %v", err, os.ErrNotExist)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read key file: %v", err)
+	}
+	kcv, _ := KCV(AlgorithmTDES, mustHex(t, testTDESKey))
+
+	tests := []struct {
+		name	string
+		data	string
+		err		string
+	}{
+		// the kcv is checked after the decryption
+		{"kcv", strings.Replace(string(data), `"kcv": "` + kcv + `"`, `"kcv": "000000"`, 1), "key check value"},
+		// an entry moved to another version doesn't match its additional data
+		{"version", strings.Replace(string(data), `"version": 1,` + "\n      \"algorithm\"", `"version": 2,` + "\n      \"algorithm\"", 1), "can't be decrypted"},
+		{"format version", strings.Replace(string(data), `"version": 1,`, `"version": 9,`, 1), "unsupported version"},
+		{"json", "{", "invalid key file"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if test.data == string(data) {
+				t.Fatalf("the key file is unchanged")
+			}
+			tampered := filepath.Join(t.TempDir(), "keys.json")
+			if err := os.WriteFile(tampered, []byte(test.data), 0600); err != nil {
+				t.Fatalf("write key file: %v", err)
+			}
+			if _, err := LoadKeyFile(tampered, mustHex(t, testMasterKey)); err == nil || !strings.Contains(err.Error(), test.err) {
+				t.Errorf("err %v, want %q", err, test.err)
+			}
+		})
+	}
+}
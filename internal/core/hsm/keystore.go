@@ -0,0 +1,182 @@
+package hsm
+
+import (
+	"fmt"
+	"sort"
+	"errors"
+)
+
+// the names of the keys in the key store
+const (
+	KeyCVK = "cvk" // card verification key (CVV2), double length DES
+	KeyDCVK = "dcvk" // dynamic cvv master key, the per card secret is derived from it
+	KeyPVK = "pvk" // pin verification key (Visa PVV), double length DES
+	KeyZPK = "zpk" // zone pin key of the ISO 9564 format 0 pin blocks, double length DES
+	KeyZPKAES = "zpk_aes" // zone pin key of the ISO 9564 format 4 pin blocks, AES
+	KeyTK = "tk" // tokenization key, the token of a card is the MAC of its number
+)
+
+// About the keys the service needs and their algorithm
+var RequiredKeys = map[string]string{
+	KeyCVK:		AlgorithmTDES,
+	KeyDCVK:	AlgorithmHMAC,
+	KeyPVK:		AlgorithmTDES,
+	KeyZPK:		AlgorithmTDES,
+	KeyZPKAES:	AlgorithmAES,
+	KeyTK:		AlgorithmHMAC,
+}
+
+var (
+	ErrKeyNotFound	= errors.New("key not found in the key store")
+	ErrInvalidKey	= errors.New("invalid key, a key must have 16, 24 or 32 bytes")
+)
+
+// About a key version with its clear value, only the software HSM reads the value
+type Key struct {
+	KeyInfo
+	value		[]byte
+}
+
+// About the store of the keys used by the software HSM
+type KeyStore interface {
+	// the active version of a key
+	ActiveKey(name string) (*Key, error)
+	// a version of a key, it must be active or verify only
+	KeyVersion(name string, version int) (*Key, error)
+	// the usable versions of a key, the active one first
+	UsableKeys(name string) ([]*Key, error)
+	Keys() []KeyInfo
+}
+
+// About a key store kept in memory, loaded from the encrypted key file
+type SoftwareKeyStore struct {
+	keys	map[string][]*Key // by name, sorted by version
+}
+
+// About create an empty key store
+func NewSoftwareKeyStore() *SoftwareKeyStore {
+	return &SoftwareKeyStore{keys: map[string][]*Key{}}
+}
+
+// About check the size of a key value for its algorithm
+func checkKey(algorithm string, value []byte) error {
+	switch algorithm {
+	case AlgorithmTDES:
+		if len(value) != 16 && len(value) != 24 {
+			return fmt.Errorf("%w: a TDES key has 16 or 24 bytes, got %d", ErrInvalidKey, len(value))
+		}
+	case AlgorithmAES:
+		if len(value) != 16 && len(value) != 24 && len(value) != 32 {
+			return fmt.Errorf("%w: an AES key has 16, 24 or 32 bytes, got %d", ErrInvalidKey, len(value))
+		}
+	case AlgorithmHMAC:
+		if len(value) < 16 {
+			return fmt.Errorf("%w: a HMAC key has at least 16 bytes, got %d", ErrInvalidKey, len(value))
+		}
+	default:
+		return fmt.Errorf("%w: unknown algorithm %s", ErrInvalidKey, algorithm)
+	}
+	return nil
+}
+
+// About add a key version, the value is checked against its algorithm and KCV
+func (k *SoftwareKeyStore) add(info KeyInfo, value []byte) error {
+	if err := checkKey(info.Algorithm, value); err != nil {
+		return fmt.Errorf("%s v%d: %w", info.Name, info.Version, err)
+	}
+	kcv, err := KCV(info.Algorithm, value)
+	if err != nil {
+		return err
+	}
+	if info.KCV != kcv {
+		return fmt.Errorf("%s v%d: the key check value %s doesn't match %s", info.Name, info.Version, kcv, info.KCV)
+	}
+	for _, key := range k.keys[info.Name] {
+		if key.Version == info.Version {
+			return fmt.Errorf("%s v%d: duplicated version", info.Name, info.Version)
+		}
+		if key.Status == KeyStatusActive && info.Status == KeyStatusActive {
+			return fmt.Errorf("%s: more than one active version", info.Name)
+		}
+		if info.Name == KeyPVK && key.Status != KeyStatusRetired && info.Status != KeyStatusRetired && pvkiOf(key.Version) == pvkiOf(info.Version) {
+			return fmt.Errorf("%s v%d: the pvki %d is used by v%d, it must be retired first", info.Name, info.Version, pvkiOf(info.Version), key.Version)
+		}
+	}
+
+	k.keys[info.Name] = append(k.keys[info.Name], &Key{KeyInfo: info, value: append([]byte(nil), value...)})
+	sort.Slice(k.keys[info.Name], func(i, j int) bool {
+		return k.keys[info.Name][i].Version < k.keys[info.Name][j].Version
+	})
+	return nil
+}
+
+func (k *SoftwareKeyStore) ActiveKey(name string) (*Key, error) {
+	for _, key := range k.keys[name] {
+		if key.Status == KeyStatusActive {
+			return key, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %s has no active version", ErrKeyNotFound, name)
+}
+
+func (k *SoftwareKeyStore) KeyVersion(name string, version int) (*Key, error) {
+	for _, key := range k.keys[name] {
+		if key.Version == version && key.Status != KeyStatusRetired {
+			return key, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %s v%d", ErrKeyNotFound, name, version)
+}
+
+func (k *SoftwareKeyStore) UsableKeys(name string) ([]*Key, error) {
+	active, err := k.ActiveKey(name)
+	if err != nil {
+		return nil, err
+	}
+	keys := []*Key{active}
+	for i := len(k.keys[name]) - 1; i >= 0; i-- {
+		if k.keys[name][i].Status == KeyStatusVerifyOnly {
+			keys = append(keys, k.keys[name][i])
+		}
+	}
+	return keys, nil
+}
+
+func (k *SoftwareKeyStore) Keys() []KeyInfo {
+	names := make([]string, 0, len(k.keys))
+	for name := range k.keys {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	infos := []KeyInfo{}
+	for _, name := range names {
+		for _, key := range k.keys[name] {
+			infos = append(infos, key.KeyInfo)
+		}
+	}
+	return infos
+}
+
+// About check that the keys exist with an active version and the expected algorithm
+func (k *SoftwareKeyStore) Check(required map[string]string) error {
+	names := make([]string, 0, len(required))
+	for name := range required {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var errs []error
+	for _, name := range names {
+		algorithm := required[name]
+		key, err := k.ActiveKey(name)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if key.Algorithm != algorithm {
+			errs = append(errs, fmt.Errorf("%s must be a %s key, got %s", name, algorithm, key.Algorithm))
+		}
+	}
+	return errors.Join(errs...)
+}
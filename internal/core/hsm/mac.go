@@ -0,0 +1,140 @@
+package hsm
+
+import (
+	"crypto/aes"
+	"crypto/des"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/cipher"
+	"crypto/subtle"
+	"encoding/hex"
+	"strings"
+)
+
+// About the key check value of a key: the 3 first bytes of the encryption of a zero block for the
+// TDES keys, of the AES-CMAC of an empty message for the AES keys and of the HMAC of a zero block for the HMAC keys
+func KCV(algorithm string, value []byte) (string, error) {
+	if err := checkKey(algorithm, value); err != nil {
+		return "", err
+	}
+
+	var check []byte
+	switch algorithm {
+	case AlgorithmTDES:
+		block, err := tripleDES(value)
+		if err != nil {
+			return "", err
+		}
+		check = make([]byte, des.BlockSize)
+		block.Encrypt(check, check)
+	case AlgorithmAES:
+		block, err := aes.NewCipher(value)
+		if err != nil {
+			return "", err
+		}
+		check = cmac(block, nil)
+	case AlgorithmHMAC:
+		mac := hmac.New(sha256.New, value)
+		mac.Write(make([]byte, 8))
+		check = mac.Sum(nil)
+	}
+	return strings.ToUpper(hex.EncodeToString(check[:3])), nil
+}
+
+// About the MAC of a message with a key, the algorithm follows the key
+func mac(key *Key, data []byte) ([]byte, error) {
+	switch key.Algorithm {
+	case AlgorithmHMAC:
+		mac := hmac.New(sha256.New, key.value)
+		mac.Write(data)
+		return mac.Sum(nil), nil
+	case AlgorithmAES:
+		block, err := aes.NewCipher(key.value)
+		if err != nil {
+			return nil, err
+		}
+		return cmac(block, data), nil
+	case AlgorithmTDES:
+		return retailMAC(key.value, data)
+	default:
+		return nil, ErrInvalidKey
+	}
+}
+
+// About the CMAC of a message (NIST SP 800-38B)
+func cmac(block cipher.Block, data []byte) []byte {
+	size := block.BlockSize()
+
+	// subkeys
+	subkey := func(in []byte) []byte {
+		out := make([]byte, size)
+		carry := byte(0)
+		for i := size - 1; i >= 0; i-- {
+			out[i] = in[i] << 1 | carry
+			carry = in[i] >> 7
+		}
+		if carry == 1 {
+			out[size - 1] ^= 0x87
+		}
+		return out
+	}
+	l := make([]byte, size)
+	block.Encrypt(l, l)
+	k1 := subkey(l)
+	k2 := subkey(k1)
+
+	// the last block is XORed with k1 when complete, padded (10..0) and XORed with k2 otherwise
+	blocks := (len(data) + size - 1) / size
+	last := make([]byte, size)
+	if blocks > 0 && len(data) % size == 0 {
+		subtle.XORBytes(last, data[(blocks - 1) * size:], k1)
+	} else {
+		if blocks == 0 {
+			blocks = 1
+		}
+		rest := data[(blocks - 1) * size:]
+		copy(last, rest)
+		last[len(rest)] = 0x80
+		subtle.XORBytes(last, last, k2)
+	}
+
+	result := make([]byte, size)
+	for i := 0; i < blocks - 1; i++ {
+		subtle.XORBytes(result, result, data[i * size:(i + 1) * size])
+		block.Encrypt(result, result)
+	}
+	subtle.XORBytes(result, result, last)
+	block.Encrypt(result, result)
+	return result
+}
+
+// About the ISO 9797-1 MAC algorithm 3 (retail MAC) with the padding method 2, for the TDES keys
+// The message is DES CBC encrypted with the left key, the last block is decrypted with the right key
+// and encrypted again with the left key
+func retailMAC(key []byte, data []byte) ([]byte, error) {
+	if len(key) != 16 {
+		return nil, ErrInvalidKey
+	}
+	left, err := des.NewCipher(key[:8])
+	if err != nil {
+		return nil, err
+	}
+	right, err := des.NewCipher(key[8:])
+	if err != nil {
+		return nil, err
+	}
+
+	padded := append(append([]byte(nil), data...), 0x80)
+	for len(padded) % des.BlockSize != 0 {
+		padded = append(padded, 0x00)
+	}
+
+	result := make([]byte, des.BlockSize)
+	for i := 0; i < len(padded); i += des.BlockSize {
+		subtle.XORBytes(result, result, padded[i:i + des.BlockSize])
+		left.Encrypt(result, result)
+	}
+	right.Decrypt(result, result)
+	left.Encrypt(result, result)
+	return result, nil
+}
@@ -0,0 +1,82 @@
+package hsm
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"crypto/aes"
+)
+
+func TestKCV(t *testing.T) {
+	tests := []struct {
+		name		string
+		algorithm	string
+		key			string
+		kcv			string
+	}{
+		{"tdes", AlgorithmTDES, testTDESKey, "08D7B4"},
+		// the AES-CMAC of an empty message of NIST SP 800-38B (example 1)
+		{"aes", AlgorithmAES, "2B7E151628AED2A6ABF7158809CF4F3C", "BB1D69"},
+		{"hmac", AlgorithmHMAC, testHMACKey, "9F0CD9"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			kcv, err := KCV(test.algorithm, mustHex(t, test.key))
+			if err != nil {
+				t.Fatalf("kcv: %v", err)
+			}
+			if kcv != test.kcv {
+				t.Errorf("kcv %s, want %s", kcv, test.kcv)
+			}
+		})
+	}
+
+	if _, err := KCV(AlgorithmTDES, mustHex(t, testAESKey)[:8]); !errors.Is(err, ErrInvalidKey) {
+		t.Errorf("short key err %v, want %v", err, ErrInvalidKey)
+	}
+	if _, err := KCV("RSA", mustHex(t, testTDESKey)); !errors.Is(err, ErrInvalidKey) {
+		t.Errorf("unknown algorithm err %v, want %v", err, ErrInvalidKey)
+	}
+}
+
+func TestCMAC(t *testing.T) {
+	// NIST SP 800-38B, AES-128
+	block, err := aes.NewCipher(mustHex(t, "2B7E151628AED2A6ABF7158809CF4F3C"))
+	if err != nil {
+		t.Fatalf("aes: %v", err)
+	}
+
+	tests := []struct {
+		name	string
+		data	string
+		mac		string
+	}{
+		{"empty", "", "BB1D6929E95937287FA37D129B756746"},
+		{"one block", "6BC1BEE22E409F96E93D7E117393172A", "070A16B46B4D4144F79BDD9DD04A287C"},
+		{"partial block", "6BC1BEE22E409F96E93D7E117393172AAE2D8A571E03AC9C9EB76FAC45AF8E5130C81C46A35CE411", "DFA66747DE9AE63030CA32611497C827"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if mac := cmac(block, mustHex(t, test.data)); !bytes.Equal(mac, mustHex(t, test.mac)) {
+				t.Errorf("cmac %X, want %s", mac, test.mac)
+			}
+		})
+	}
+}
+
+func TestRetailMAC(t *testing.T) {
+	// "Now is the time for all " padded with the method 2
+	mac, err := retailMAC(mustHex(t, testTDESKey), []byte("Now is the time for all "))
+	if err != nil {
+		t.Fatalf("retail mac: %v", err)
+	}
+	if !bytes.Equal(mac, mustHex(t, "E9086230CA3BE796")) {
+		t.Errorf("retail mac %X, want E9086230CA3BE796", mac)
+	}
+
+	if _, err := retailMAC(mustHex(t, testHMACKey), nil); !errors.Is(err, ErrInvalidKey) {
+		t.Errorf("triple length key err %v, want %v", err, ErrInvalidKey)
+	}
+}
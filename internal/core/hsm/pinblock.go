@@ -0,0 +1,183 @@
+package hsm
+
+import (
+	"fmt"
+	"errors"
+	"strconv"
+	"strings"
+	"crypto/aes"
+	"crypto/des"
+	"crypto/rand"
+	"crypto/cipher"
+	"crypto/subtle"
+	"encoding/hex"
+)
+
+// the ISO 9564 pin block formats
+const (
+	PinBlockFormat0	= 0 // 8 bytes, PIN XOR PAN, TDES
+	PinBlockFormat4	= 4 // 16 bytes, AES
+)
+
+var (
+	ErrInvalidPinBlock	= errors.New("invalid pin block")
+	ErrInvalidPin		= errors.New("invalid pin, it must have 4 to 12 digits")
+)
+
+// About the key of a pin block format
+func zpkName(format int) (string, error) {
+	switch format {
+	case PinBlockFormat0:
+		return KeyZPK, nil
+	case PinBlockFormat4:
+		return KeyZPKAES, nil
+	default:
+		return "", ErrInvalidPinBlock
+	}
+}
+
+// About a TDES cipher, a double length key is used as K1 K2 K1
+func tripleDES(key []byte) (cipher.Block, error) {
+	switch len(key) {
+	case 16:
+		return des.NewTripleDESCipher(append(append([]byte(nil), key...), key[:8]...))
+	case 24:
+		return des.NewTripleDESCipher(key)
+	default:
+		return nil, ErrInvalidKey
+	}
+}
+
+// About the PAN field of a format 0 pin block: 0000 and the 12 rightmost digits of the PAN without the check digit
+func panField0(pan string) ([]byte, error) {
+	if !isDigits(pan) || len(pan) < 13 {
+		return nil, ErrInvalidPinBlock
+	}
+	return hex.DecodeString("0000" + pan[len(pan) - 13:len(pan) - 1])
+}
+
+// About the PAN field of a format 4 pin block: the PAN length - 12 and the PAN, padded with zeros
+func panField4(pan string) ([]byte, error) {
+	if !isDigits(pan) || len(pan) < 12 || len(pan) > 19 {
+		return nil, ErrInvalidPinBlock
+	}
+	field := strconv.Itoa(len(pan) - 12) + pan
+	return hex.DecodeString(field + strings.Repeat("0", 32 - len(field)))
+}
+
+// About extract the clear pin of a pin field, the fill must match the format (F for 0, A for 4)
+func pinFromField(field string, format int) ([]byte, error) {
+	field = strings.ToUpper(field)
+	fill := byte('F')
+	if format == PinBlockFormat4 {
+		fill = 'A'
+	}
+	if field[0] != byte('0' + format) {
+		return nil, ErrInvalidPinBlock
+	}
+	length, err := strconv.ParseUint(field[1:2], 16, 8)
+	if err != nil || length < 4 || length > 12 {
+		return nil, ErrInvalidPin
+	}
+	pin := []byte(field[2:2 + length])
+	if !isDigits(string(pin)) {
+		return nil, ErrInvalidPinBlock
+	}
+	for i := 2 + int(length); i < 16; i++ {
+		if field[i] != fill {
+			return nil, ErrInvalidPinBlock
+		}
+	}
+	return pin, nil
+}
+
+// About decrypt a pin block under its zone key and extract the clear pin
+// The caller must clear the pin (clear) as soon as it is used
+func decryptPinBlock(zpk []byte, pinBlock []byte, format int, pan string) ([]byte, error) {
+	switch format {
+	case PinBlockFormat0:
+		if len(pinBlock) != 8 {
+			return nil, ErrInvalidPinBlock
+		}
+		block, err := tripleDES(zpk)
+		if err != nil {
+			return nil, err
+		}
+		panField, err := panField0(pan)
+		if err != nil {
+			return nil, err
+		}
+		clear := make([]byte, 8)
+		block.Decrypt(clear, pinBlock)
+		subtle.XORBytes(clear, clear, panField)
+		return pinFromField(hex.EncodeToString(clear), format)
+	case PinBlockFormat4:
+		if len(pinBlock) != 16 {
+			return nil, ErrInvalidPinBlock
+		}
+		block, err := aes.NewCipher(zpk)
+		if err != nil {
+			return nil, ErrInvalidKey
+		}
+		panField, err := panField4(pan)
+		if err != nil {
+			return nil, err
+		}
+		clear := make([]byte, 16)
+		block.Decrypt(clear, pinBlock)
+		subtle.XORBytes(clear, clear, panField)
+		block.Decrypt(clear, clear)
+		return pinFromField(hex.EncodeToString(clear), format)
+	default:
+		return nil, ErrInvalidPinBlock
+	}
+}
+
+// About build and encrypt a pin block, it is the terminal side (test client, siege)
+func encryptPinBlock(zpk []byte, pin string, format int, pan string) ([]byte, error) {
+	if !isDigits(pin) || len(pin) < 4 || len(pin) > 12 {
+		return nil, ErrInvalidPin
+	}
+	switch format {
+	case PinBlockFormat0:
+		block, err := tripleDES(zpk)
+		if err != nil {
+			return nil, err
+		}
+		panField, err := panField0(pan)
+		if err != nil {
+			return nil, err
+		}
+		field := fmt.Sprintf("0%X%s", len(pin), pin)
+		clear, err := hex.DecodeString(field + strings.Repeat("F", 16 - len(field)))
+		if err != nil {
+			return nil, err
+		}
+		subtle.XORBytes(clear, clear, panField)
+		block.Encrypt(clear, clear)
+		return clear, nil
+	case PinBlockFormat4:
+		block, err := aes.NewCipher(zpk)
+		if err != nil {
+			return nil, ErrInvalidKey
+		}
+		panField, err := panField4(pan)
+		if err != nil {
+			return nil, err
+		}
+		field := fmt.Sprintf("4%X%s", len(pin), pin)
+		clear, err := hex.DecodeString(field + strings.Repeat("A", 16 - len(field)) + strings.Repeat("0", 16))
+		if err != nil {
+			return nil, err
+		}
+		if _, err := rand.Read(clear[8:]); err != nil {
+			return nil, err
+		}
+		block.Encrypt(clear, clear)
+		subtle.XORBytes(clear, clear, panField)
+		block.Encrypt(clear, clear)
+		return clear, nil
+	default:
+		return nil, ErrInvalidPinBlock
+	}
+}
@@ -0,0 +1,112 @@
+package hsm
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+// the pan of the ISO 9564 format 0 example, the pin block field is 0412AC89ABCDEF67 for the pin 1234
+const testPinPAN = "43219876543210987"
+
+func TestDecryptPinBlock(t *testing.T) {
+	tests := []struct {
+		name		string
+		key			string
+		format		int
+		pinBlock	string
+	}{
+		{"format 0", testTDESKey, PinBlockFormat0, "C967C8198151A458"},
+		// the random fill is 0123456789ABCDEF
+		{"format 4", testAESKey, PinBlockFormat4, "6AA10979C29078540F07B1F3A21C2C58"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			pin, err := decryptPinBlock(mustHex(t, test.key), mustHex(t, test.pinBlock), test.format, testPinPAN)
+			if err != nil {
+				t.Fatalf("decrypt pin block: %v", err)
+			}
+			if string(pin) != "1234" {
+				t.Errorf("pin %s, want 1234", pin)
+			}
+
+			// the pin block is bound to its pan
+			pin, err = decryptPinBlock(mustHex(t, test.key), mustHex(t, test.pinBlock), test.format, "43219876543210995")
+			if err == nil && string(pin) == "1234" {
+				t.Errorf("pin block decrypted with a wrong pan")
+			}
+		})
+	}
+}
+
+func TestEncryptPinBlock(t *testing.T) {
+	pinBlock, err := encryptPinBlock(mustHex(t, testTDESKey), "1234", PinBlockFormat0, testPinPAN)
+	if err != nil {
+		t.Fatalf("encrypt pin block: %v", err)
+	}
+	if !bytes.Equal(pinBlock, mustHex(t, "C967C8198151A458")) {
+		t.Errorf("pin block %X, want C967C8198151A458", pinBlock)
+	}
+
+	for _, test := range []struct {
+		key		string
+		format	int
+		pin		string
+	}{
+		{testTDESKey, PinBlockFormat0, "123456789012"},
+		{testAESKey, PinBlockFormat4, "0000"},
+		{testAESKey, PinBlockFormat4, "123456789012"},
+	} {
+		pinBlock, err := encryptPinBlock(mustHex(t, test.key), test.pin, test.format, testPinPAN)
+		if err != nil {
+			t.Fatalf("encrypt pin block: %v", err)
+		}
+		pin, err := decryptPinBlock(mustHex(t, test.key), pinBlock, test.format, testPinPAN)
+		if err != nil {
+			t.Fatalf("decrypt pin block: %v", err)
+		}
+		if string(pin) != test.pin {
+			t.Errorf("format %d pin %s, want %s", test.format, pin, test.pin)
+		}
+	}
+
+	// the random fill of the format 4 gives a new pin block every time
+	first, _ := encryptPinBlock(mustHex(t, testAESKey), "1234", PinBlockFormat4, testPinPAN)
+	second, _ := encryptPinBlock(mustHex(t, testAESKey), "1234", PinBlockFormat4, testPinPAN)
+	if bytes.Equal(first, second) {
+		t.Errorf("format 4 pin blocks must differ")
+	}
+}
+
+func TestPinBlockInvalid(t *testing.T) {
+	tests := []struct {
+		name		string
+		pin			string
+		format		int
+		pan			string
+		err			error
+	}{
+		{"short pin", "123", PinBlockFormat0, testPinPAN, ErrInvalidPin},
+		{"long pin", "1234567890123", PinBlockFormat0, testPinPAN, ErrInvalidPin},
+		{"pin with letters", "12A4", PinBlockFormat0, testPinPAN, ErrInvalidPin},
+		{"short pan", "1234", PinBlockFormat0, "432198765432", ErrInvalidPinBlock},
+		{"format", "1234", 1, testPinPAN, ErrInvalidPinBlock},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if _, err := encryptPinBlock(mustHex(t, testTDESKey), test.pin, test.format, test.pan); !errors.Is(err, test.err) {
+				t.Errorf("err %v, want %v", err, test.err)
+			}
+		})
+	}
+
+	if _, err := decryptPinBlock(mustHex(t, testTDESKey), mustHex(t, "C967C8198151"), PinBlockFormat0, testPinPAN); !errors.Is(err, ErrInvalidPinBlock) {
+		t.Errorf("short pin block err %v, want %v", err, ErrInvalidPinBlock)
+	}
+	// a format 0 pin block decrypted as format 4
+	if _, err := decryptPinBlock(mustHex(t, testAESKey), mustHex(t, "C967C8198151A458C967C8198151A458"), PinBlockFormat4, testPinPAN); err == nil {
+		t.Errorf("invalid format 4 pin block decrypted")
+	}
+}
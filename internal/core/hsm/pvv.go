@@ -0,0 +1,40 @@
+package hsm
+
+import (
+	"fmt"
+	"errors"
+	"encoding/hex"
+)
+
+// the PVKI is a single digit, a PVKI out of range is a key store error, not a client one
+var ErrInvalidPVKI = errors.New("invalid pvki, it must be a single digit")
+
+// About the PVKI of a PVK version, the versions cycle on the digits (v10 is the PVKI 0, v11 the PVKI 1)
+func pvkiOf(version int) int {
+	return version % 10
+}
+
+// About compute the Visa PVV of a pin
+// The TSP (11 rightmost digits of the PAN without the check digit, PVKI and the 4 first digits of the pin)
+// is TDES encrypted with the PVK, the result is decimalized and the 4 first digits are the PVV
+func pvv(pvk []byte, pan string, pvki int, pin []byte) (string, error) {
+	if pvki < 0 || pvki > 9 {
+		return "", ErrInvalidPVKI
+	}
+	if !isDigits(pan) || len(pan) < 12 || len(pin) < 4 {
+		return "", ErrInvalidPinBlock
+	}
+	block, err := tripleDES(pvk)
+	if err != nil {
+		return "", err
+	}
+
+	tsp, err := hex.DecodeString(fmt.Sprintf("%s%d%s", pan[len(pan) - 12:len(pan) - 1], pvki, pin[:4]))
+	if err != nil {
+		return "", err
+	}
+	result := make([]byte, 8)
+	block.Encrypt(result, tsp)
+
+	return decimalize(hex.EncodeToString(result), 4), nil
+}
@@ -0,0 +1,141 @@
+package hsm
+
+import (
+	"time"
+	"errors"
+	"testing"
+)
+
+func TestPVV(t *testing.T) {
+	tests := []struct {
+		name	string
+		pan		string
+		pvki	int
+		pin		string
+		pvv		string
+	}{
+		// the TSP is 4567890123411234
+		{"pvki 1", "4123456789012345", 1, "1234", "1894"},
+		{"pvki 2", "4123456789012345", 2, "1234", "9300"},
+		{"pin", "4123456789012345", 1, "4321", "4661"},
+		{"only 4 digits of the pin", "4123456789012345", 1, "123456", "1894"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			pvv, err := pvv(mustHex(t, testTDESKey), test.pan, test.pvki, []byte(test.pin))
+			if err != nil {
+				t.Fatalf("pvv: %v", err)
+			}
+			if pvv != test.pvv {
+				t.Errorf("pvv %s, want %s", pvv, test.pvv)
+			}
+		})
+	}
+}
+
+func TestPVVInvalidPVKI(t *testing.T) {
+	for _, pvki := range []int{-1, 10, 11} {
+		if _, err := pvv(mustHex(t, testTDESKey), "4123456789012345", pvki, []byte("1234")); !errors.Is(err, ErrInvalidPVKI) {
+			t.Errorf("pvki %d err %v, want %v", pvki, err, ErrInvalidPVKI)
+		}
+	}
+}
+
+func TestGenerateVerifyPIN(t *testing.T) {
+	softwareHSM, _ := newTestHSM(t)
+
+	pinBlock, err := softwareHSM.EncryptPinBlock("1234", PinBlockFormat4, testPinPAN)
+	if err != nil {
+		t.Fatalf("encrypt pin block: %v", err)
+	}
+	pvv, pvki, err := softwareHSM.GeneratePVV(pinBlock, PinBlockFormat4, testPinPAN)
+	if err != nil {
+		t.Fatalf("generate pvv: %v", err)
+	}
+	if pvki != 1 {
+		t.Errorf("pvki %d, want 1", pvki)
+	}
+
+	tests := []struct {
+		name	string
+		pin		string
+		format	int
+		want	bool
+	}{
+		{"same pin format 0", "1234", PinBlockFormat0, true},
+		{"same pin format 4", "1234", PinBlockFormat4, true},
+		{"wrong pin", "1235", PinBlockFormat0, false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			pinBlock, err := softwareHSM.EncryptPinBlock(test.pin, test.format, testPinPAN)
+			if err != nil {
+				t.Fatalf("encrypt pin block: %v", err)
+			}
+			verified, err := softwareHSM.VerifyPIN(pinBlock, test.format, testPinPAN, pvki, pvv)
+			if err != nil {
+				t.Fatalf("verify pin: %v", err)
+			}
+			if verified != test.want {
+				t.Errorf("verify pin = %v, want %v", verified, test.want)
+			}
+		})
+	}
+}
+
+func TestPVKIVersions(t *testing.T) {
+	softwareHSM, keyStore := newTestHSM(t)
+
+	pinBlock, err := softwareHSM.EncryptPinBlock("1234", PinBlockFormat0, testPinPAN)
+	if err != nil {
+		t.Fatalf("encrypt pin block: %v", err)
+	}
+	generate := func(want int) (string, int) {
+		t.Helper()
+		pvv, pvki, err := softwareHSM.GeneratePVV(pinBlock, PinBlockFormat0, testPinPAN)
+		if err != nil {
+			t.Fatalf("generate pvv: %v", err)
+		}
+		if pvki != want {
+			t.Errorf("pvki %d, want %d", pvki, want)
+		}
+		return pvv, pvki
+	}
+	verify := func(pvki int, pvv string, want bool) {
+		t.Helper()
+		verified, err := softwareHSM.VerifyPIN(pinBlock, PinBlockFormat0, testPinPAN, pvki, pvv)
+		if err != nil {
+			t.Fatalf("verify pin pvki %d: %v", pvki, err)
+		}
+		if verified != want {
+			t.Errorf("verify pin pvki %d = %v, want %v", pvki, verified, want)
+		}
+	}
+
+	pvv1, pvki1 := generate(1)
+
+	// v2 to v10, the v10 is the pvki 0
+	for i := 2; i <= 10; i++ {
+		if _, err := keyStore.Rotate(KeyPVK, AlgorithmTDES, nil, time.Now()); err != nil {
+			t.Fatalf("rotate v%d: %v", i, err)
+		}
+	}
+	pvv10, pvki10 := generate(0)
+	verify(pvki10, pvv10, true)
+	verify(pvki1, pvv1, true)
+
+	// the pvki 1 is used by the v1 until it is retired
+	if _, err := keyStore.Rotate(KeyPVK, AlgorithmTDES, nil, time.Now()); err == nil {
+		t.Fatalf("rotate v11 with the v1 usable, want an error")
+	}
+	if err := keyStore.Retire(KeyPVK, 1, time.Now()); err != nil {
+		t.Fatalf("retire v1: %v", err)
+	}
+	if _, err := keyStore.Rotate(KeyPVK, AlgorithmTDES, nil, time.Now()); err != nil {
+		t.Fatalf("rotate v11: %v", err)
+	}
+	pvv11, pvki11 := generate(1)
+	verify(pvki11, pvv11, true)
+	verify(pvki10, pvv10, true)
+}
@@ -0,0 +1,284 @@
+package hsm
+
+import (
+	"fmt"
+	"errors"
+	"crypto/aes"
+	"crypto/des"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/cipher"
+	"crypto/subtle"
+)
+
+var ErrInvalidCiphertext = errors.New("invalid ciphertext")
+
+// About a HSM implemented in software with the keys of a key store, for the development and the tests
+// The derived keys are returned encrypted under the storage key (AES-256-GCM)
+type SoftwareHSM struct {
+	keyStore	KeyStore
+	storage		cipher.AEAD
+}
+
+// About create a software HSM, the storage key encrypts the derived keys
+func NewSoftwareHSM(keyStore KeyStore, storageKey []byte) (*SoftwareHSM, error) {
+	storage, err := masterAEAD(storageKey)
+	if err != nil {
+		return nil, err
+	}
+	return &SoftwareHSM{keyStore: keyStore, storage: storage}, nil
+}
+
+// About decrypt a pin block with the active zone key of its format
+func (h *SoftwareHSM) clearPin(pinBlock []byte, format int, pan string) ([]byte, error) {
+	name, err := zpkName(format)
+	if err != nil {
+		return nil, err
+	}
+	zpk, err := h.keyStore.ActiveKey(name)
+	if err != nil {
+		return nil, err
+	}
+	return decryptPinBlock(zpk.value, pinBlock, format, pan)
+}
+
+func (h *SoftwareHSM) GeneratePVV(pinBlock []byte, format int, pan string) (string, int, error) {
+	pin, err := h.clearPin(pinBlock, format, pan)
+	if err != nil {
+		return "", 0, err
+	}
+	defer clear(pin)
+
+	pvk, err := h.keyStore.ActiveKey(KeyPVK)
+	if err != nil {
+		return "", 0, err
+	}
+	pvki := pvkiOf(pvk.Version)
+	res, err := pvv(pvk.value, pan, pvki, pin)
+	return res, pvki, err
+}
+
+func (h *SoftwareHSM) VerifyPIN(pinBlock []byte, format int, pan string, pvki int, expected string) (bool, error) {
+	pin, err := h.clearPin(pinBlock, format, pan)
+	if err != nil {
+		return false, err
+	}
+	defer clear(pin)
+
+	pvk, err := h.pvk(pvki)
+	if err != nil {
+		return false, err
+	}
+	actual, err := pvv(pvk.value, pan, pvki, pin)
+	if err != nil {
+		return false, err
+	}
+	return subtle.ConstantTimeCompare([]byte(actual), []byte(expected)) == 1, nil
+}
+
+// About the usable PVK version of a PVKI, the key store keeps one usable version per PVKI
+func (h *SoftwareHSM) pvk(pvki int) (*Key, error) {
+	keys, err := h.keyStore.UsableKeys(KeyPVK)
+	if err != nil {
+		return nil, err
+	}
+	for _, key := range keys {
+		if pvkiOf(key.Version) == pvki {
+			return key, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %s pvki %d", ErrKeyNotFound, KeyPVK, pvki)
+}
+
+func (h *SoftwareHSM) DeriveKey(keyName string, data []byte) (*DerivedKey, error) {
+	master, err := h.keyStore.ActiveKey(keyName)
+	if err != nil {
+		return nil, err
+	}
+
+	var derived []byte
+	switch master.Algorithm {
+	case AlgorithmTDES:
+		// EMV option A: the left half is the encryption of the data, the right half of the inverted data
+		if len(data) != des.BlockSize {
+			return nil, ErrInvalidKey
+		}
+		block, err := tripleDES(master.value)
+		if err != nil {
+			return nil, err
+		}
+		derived = make([]byte, 16)
+		block.Encrypt(derived[:8], data)
+		inverted := make([]byte, des.BlockSize)
+		for i := range data {
+			inverted[i] = data[i] ^ 0xff
+		}
+		block.Encrypt(derived[8:], inverted)
+		for i := range derived {
+			derived[i] = oddParity(derived[i])
+		}
+	default:
+		mac := hmac.New(sha256.New, master.value)
+		mac.Write(data)
+		derived = mac.Sum(nil)[:len(master.value)]
+	}
+	defer clear(derived)
+
+	kcv, err := KCV(master.Algorithm, derived)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, h.storage.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return &DerivedKey{
+		KeyBlock:	h.storage.Seal(nonce, nonce, derived, []byte(master.Algorithm)),
+		KCV:		kcv,
+	}, nil
+}
+
+func (h *SoftwareHSM) Encrypt(keyName string, plaintext []byte) ([]byte, error) {
+	key, err := h.keyStore.ActiveKey(keyName)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := aesGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	// version (1 byte), nonce and ciphertext
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	res := append([]byte{byte(key.Version)}, nonce...)
+	return aead.Seal(res, nonce, plaintext, []byte(keyName)), nil
+}
+
+func (h *SoftwareHSM) Decrypt(keyName string, ciphertext []byte) ([]byte, error) {
+	if len(ciphertext) < 1 {
+		return nil, ErrInvalidCiphertext
+	}
+	key, err := h.keyStore.KeyVersion(keyName, int(ciphertext[0]))
+	if err != nil {
+		return nil, err
+	}
+	aead, err := aesGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(ciphertext) < 1 + aead.NonceSize() {
+		return nil, ErrInvalidCiphertext
+	}
+	nonce := ciphertext[1:1 + aead.NonceSize()]
+	res, err := aead.Open(nil, nonce, ciphertext[1 + aead.NonceSize():], []byte(keyName))
+	if err != nil {
+		return nil, ErrInvalidCiphertext
+	}
+	return res, nil
+}
+
+func (h *SoftwareHSM) MAC(keyName string, data []byte) ([]byte, error) {
+	key, err := h.keyStore.ActiveKey(keyName)
+	if err != nil {
+		return nil, err
+	}
+	return mac(key, data)
+}
+
+func (h *SoftwareHSM) GenerateCVV(pan string, expiry string, serviceCode string) (string, error) {
+	cvk, err := h.keyStore.ActiveKey(KeyCVK)
+	if err != nil {
+		return "", err
+	}
+	return generateCVV(cvk.value, pan, expiry, serviceCode)
+}
+
+// About verify a CVV with the active and verify only versions of the CVK
+func (h *SoftwareHSM) VerifyCVV(pan string, expiry string, serviceCode string, cvv string) (bool, error) {
+	keys, err := h.keyStore.UsableKeys(KeyCVK)
+	if err != nil {
+		return false, err
+	}
+	verified := 0
+	for _, cvk := range keys {
+		expected, err := generateCVV(cvk.value, pan, expiry, serviceCode)
+		if err != nil {
+			return false, err
+		}
+		verified = verified | subtle.ConstantTimeCompare([]byte(expected), []byte(cvv))
+	}
+	return verified == 1, nil
+}
+
+func (h *SoftwareHSM) GenerateDynamicCVV(pan string, counter uint64) (string, error) {
+	dcvk, err := h.keyStore.ActiveKey(KeyDCVK)
+	if err != nil {
+		return "", err
+	}
+	return generateDynamicCVV(dcvk.value, pan, counter)
+}
+
+// About verify a dynamic cvv of the window (counter) or the previous one, with the usable versions of the key
+func (h *SoftwareHSM) VerifyDynamicCVV(pan string, counter uint64, dcvv string) (bool, error) {
+	keys, err := h.keyStore.UsableKeys(KeyDCVK)
+	if err != nil {
+		return false, err
+	}
+	verified := 0
+	for _, dcvk := range keys {
+		for _, window := range []uint64{counter, counter - 1} {
+			expected, err := generateDynamicCVV(dcvk.value, pan, window)
+			if err != nil {
+				return false, err
+			}
+			verified = verified | subtle.ConstantTimeCompare([]byte(expected), []byte(dcvv))
+		}
+	}
+	return verified == 1, nil
+}
+
+func (h *SoftwareHSM) Keys() []KeyInfo {
+	return h.keyStore.Keys()
+}
+
+// About encrypt a pin under the active zone key, it is the terminal side (test client, siege), not part of HSM
+func (h *SoftwareHSM) EncryptPinBlock(pin string, format int, pan string) ([]byte, error) {
+	name, err := zpkName(format)
+	if err != nil {
+		return nil, err
+	}
+	zpk, err := h.keyStore.ActiveKey(name)
+	if err != nil {
+		return nil, err
+	}
+	return encryptPinBlock(zpk.value, pin, format, pan)
+}
+
+// About the AES-GCM of an AES key
+func aesGCM(key *Key) (cipher.AEAD, error) {
+	if key.Algorithm != AlgorithmAES {
+		return nil, ErrInvalidKey
+	}
+	block, err := aes.NewCipher(key.value)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// About set the odd parity bit of a DES key byte
+func oddParity(b byte) byte {
+	b = b & 0xfe
+	ones := 0
+	for i := 1; i < 8; i++ {
+		ones = ones + int(b >> i & 1)
+	}
+	if ones % 2 == 0 {
+		b = b | 1
+	}
+	return b
+}
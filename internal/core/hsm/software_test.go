@@ -0,0 +1,112 @@
+package hsm
+
+import (
+	"time"
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestEncryptDecrypt(t *testing.T) {
+	softwareHSM, keyStore := newTestHSM(t)
+
+	ciphertext, err := softwareHSM.Encrypt(KeyZPKAES, []byte("4123456789012345"))
+	if err != nil {
+		t.Fatalf("encrypt: %v", err)
+	}
+	plaintext, err := softwareHSM.Decrypt(KeyZPKAES, ciphertext)
+	if err != nil {
+		t.Fatalf("decrypt: %v", err)
+	}
+	if string(plaintext) != "4123456789012345" {
+		t.Errorf("plaintext %s, want 4123456789012345", plaintext)
+	}
+
+	// a random nonce every time
+	again, _ := softwareHSM.Encrypt(KeyZPKAES, []byte("4123456789012345"))
+	if bytes.Equal(ciphertext, again) {
+		t.Errorf("the ciphertexts must differ")
+	}
+
+	tampered := append([]byte(nil), ciphertext...)
+	tampered[len(tampered) - 1] ^= 0x01
+	if _, err := softwareHSM.Decrypt(KeyZPKAES, tampered); !errors.Is(err, ErrInvalidCiphertext) {
+		t.Errorf("tampered err %v, want %v", err, ErrInvalidCiphertext)
+	}
+	if _, err := softwareHSM.Decrypt(KeyZPKAES, ciphertext[:5]); !errors.Is(err, ErrInvalidCiphertext) {
+		t.Errorf("truncated err %v, want %v", err, ErrInvalidCiphertext)
+	}
+	if _, err := softwareHSM.Encrypt(KeyCVK, []byte("4123456789012345")); !errors.Is(err, ErrInvalidKey) {
+		t.Errorf("tdes key err %v, want %v", err, ErrInvalidKey)
+	}
+
+	// the previous version decrypts until it is retired
+	if _, err := keyStore.Rotate(KeyZPKAES, AlgorithmAES, nil, time.Now()); err != nil {
+		t.Fatalf("rotate: %v", err)
+	}
+	rotated, _ := softwareHSM.Encrypt(KeyZPKAES, []byte("4123456789012345"))
+	if rotated[0] != 2 {
+		t.Errorf("key version %d, want 2", rotated[0])
+	}
+	if _, err := softwareHSM.Decrypt(KeyZPKAES, ciphertext); err != nil {
+		t.Errorf("decrypt with the verify only version: %v", err)
+	}
+	if err := keyStore.Retire(KeyZPKAES, 1, time.Now()); err != nil {
+		t.Fatalf("retire: %v", err)
+	}
+	if _, err := softwareHSM.Decrypt(KeyZPKAES, ciphertext); !errors.Is(err, ErrKeyNotFound) {
+		t.Errorf("retired version err %v, want %v", err, ErrKeyNotFound)
+	}
+}
+
+func TestMAC(t *testing.T) {
+	softwareHSM, keyStore := newTestHSM(t)
+
+	token, err := softwareHSM.MAC(KeyTK, []byte("4123456789012345"))
+	if err != nil {
+		t.Fatalf("mac: %v", err)
+	}
+	if again, _ := softwareHSM.MAC(KeyTK, []byte("4123456789012345")); !bytes.Equal(token, again) {
+		t.Errorf("the mac of a card number must be stable")
+	}
+
+	// a rotation changes the mac
+	if _, err := keyStore.Rotate(KeyTK, AlgorithmHMAC, nil, time.Now()); err != nil {
+		t.Fatalf("rotate: %v", err)
+	}
+	if rotated, _ := softwareHSM.MAC(KeyTK, []byte("4123456789012345")); bytes.Equal(token, rotated) {
+		t.Errorf("the mac must follow the active version")
+	}
+	if _, err := softwareHSM.MAC("unknown", []byte("4123456789012345")); !errors.Is(err, ErrKeyNotFound) {
+		t.Errorf("unknown key err %v, want %v", err, ErrKeyNotFound)
+	}
+}
+
+func TestDeriveKey(t *testing.T) {
+	softwareHSM, _ := newTestHSM(t)
+
+	derived, err := softwareHSM.DeriveKey(KeyCVK, mustHex(t, "4123456789012345"))
+	if err != nil {
+		t.Fatalf("derive key: %v", err)
+	}
+	again, _ := softwareHSM.DeriveKey(KeyCVK, mustHex(t, "4123456789012345"))
+	if derived.KCV != again.KCV {
+		t.Errorf("kcv %s, want %s", again.KCV, derived.KCV)
+	}
+	if bytes.Equal(derived.KeyBlock, again.KeyBlock) {
+		t.Errorf("the key blocks must be encrypted with a random nonce")
+	}
+	other, _ := softwareHSM.DeriveKey(KeyCVK, mustHex(t, "4123456789012346"))
+	if derived.KCV == other.KCV {
+		t.Errorf("the keys of two cards must differ")
+	}
+	if _, err := softwareHSM.DeriveKey(KeyCVK, []byte("short")); !errors.Is(err, ErrInvalidKey) {
+		t.Errorf("tdes derivation data err %v, want %v", err, ErrInvalidKey)
+	}
+}
+
+func TestNewSoftwareHSMInvalidStorageKey(t *testing.T) {
+	if _, err := NewSoftwareHSM(NewSoftwareKeyStore(), mustHex(t, testAESKey)); !errors.Is(err, ErrInvalidMasterKey) {
+		t.Errorf("err %v, want %v", err, ErrInvalidMasterKey)
+	}
+}
@@ -0,0 +1,61 @@
+package hsm
+
+import (
+	"github.com/go-card/internal/core/erro"
+)
+
+// About the HSM of a pod started without key store (no master key or key file), every operation fails
+// with erro.ErrHSMUnavailable, so only the endpoints that need the keys (cvv, pin and dcvv) are disabled
+// The cards are still issued (without cvv2) and tokenized (blake3 token)
+type UnavailableHSM struct {
+	Reason		error
+}
+
+// the reason (a path of the pod) is logged at start, it is not returned to the clients
+func (u *UnavailableHSM) err() error {
+	return erro.ErrHSMUnavailable
+}
+
+func (u *UnavailableHSM) GeneratePVV(pinBlock []byte, format int, pan string) (string, int, error) {
+	return "", 0, u.err()
+}
+
+func (u *UnavailableHSM) VerifyPIN(pinBlock []byte, format int, pan string, pvki int, pvv string) (bool, error) {
+	return false, u.err()
+}
+
+func (u *UnavailableHSM) DeriveKey(keyName string, data []byte) (*DerivedKey, error) {
+	return nil, u.err()
+}
+
+func (u *UnavailableHSM) Encrypt(keyName string, plaintext []byte) ([]byte, error) {
+	return nil, u.err()
+}
+
+func (u *UnavailableHSM) Decrypt(keyName string, ciphertext []byte) ([]byte, error) {
+	return nil, u.err()
+}
+
+func (u *UnavailableHSM) MAC(keyName string, data []byte) ([]byte, error) {
+	return nil, u.err()
+}
+
+func (u *UnavailableHSM) GenerateCVV(pan string, expiry string, serviceCode string) (string, error) {
+	return "", u.err()
+}
+
+func (u *UnavailableHSM) VerifyCVV(pan string, expiry string, serviceCode string, cvv string) (bool, error) {
+	return false, u.err()
+}
+
+func (u *UnavailableHSM) GenerateDynamicCVV(pan string, counter uint64) (string, error) {
+	return "", u.err()
+}
+
+func (u *UnavailableHSM) VerifyDynamicCVV(pan string, counter uint64, dcvv string) (bool, error) {
+	return false, u.err()
+}
+
+func (u *UnavailableHSM) Keys() []KeyInfo {
+	return []KeyInfo{}
+}
@@ -0,0 +1,50 @@
+package iso8583
+
+import (
+	"io"
+	"fmt"
+	"encoding/binary"
+)
+
+// the max size of a message, a bigger length is a broken framing
+const maxFrameSize = 8192
+
+// About read a message with its length header (big endian, the length of the message only)
+func (s *Spec) ReadFrame(r io.Reader) ([]byte, error) {
+	header := make([]byte, s.LengthHeader)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, err
+	}
+
+	length := 0
+	if s.LengthHeader == 4 {
+		length = int(binary.BigEndian.Uint32(header))
+	} else {
+		length = int(binary.BigEndian.Uint16(header))
+	}
+	if length == 0 || length > maxFrameSize {
+		return nil, fmt.Errorf("%w: frame length %d", ErrInvalidMessage, length)
+	}
+
+	data := make([]byte, length)
+	if _, err := io.ReadFull(r, data); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// About write a message with its length header, in one write
+func (s *Spec) WriteFrame(w io.Writer, data []byte) error {
+	if len(data) == 0 || len(data) > maxFrameSize {
+		return fmt.Errorf("%w: frame length %d", ErrInvalidMessage, len(data))
+	}
+
+	frame := make([]byte, s.LengthHeader, s.LengthHeader + len(data))
+	if s.LengthHeader == 4 {
+		binary.BigEndian.PutUint32(frame, uint32(len(data)))
+	} else {
+		binary.BigEndian.PutUint16(frame, uint16(len(data)))
+	}
+	_, err := w.Write(append(frame, data...))
+	return err
+}
@@ -0,0 +1,184 @@
+package iso8583
+
+import (
+	"fmt"
+	"sort"
+	"errors"
+	"strconv"
+	"encoding/hex"
+)
+
+var (
+	ErrInvalidMessage	= errors.New("invalid iso 8583 message")
+	ErrUnknownField		= errors.New("iso 8583 field not defined in the spec")
+)
+
+// About a message, the MTI (ex: 0100) and the value of each field (ascii or binary as the spec)
+type Message struct {
+	MTI		string
+	Fields	map[int][]byte
+}
+
+// About create an empty message
+func NewMessage(mti string) *Message {
+	return &Message{MTI: mti, Fields: map[int][]byte{}}
+}
+
+// About set a field
+func (m *Message) Set(number int, value []byte) {
+	m.Fields[number] = value
+}
+
+// About get a field, false when the field is not present
+func (m *Message) Get(number int) ([]byte, bool) {
+	value, ok := m.Fields[number]
+	return value, ok
+}
+
+// About the present fields in order
+func (m *Message) Numbers() []int {
+	numbers := make([]int, 0, len(m.Fields))
+	for number := range m.Fields {
+		numbers = append(numbers, number)
+	}
+	sort.Ints(numbers)
+	return numbers
+}
+
+// About pack a message: the MTI, the bitmap (with the secondary bitmap when a field > 64 is present) and the fields
+func (s *Spec) Pack(m *Message) ([]byte, error) {
+	if len(m.MTI) != 4 {
+		return nil, fmt.Errorf("%w: mti %q", ErrInvalidMessage, m.MTI)
+	}
+
+	numbers := m.Numbers()
+	bitmap := make([]byte, 8)
+	if len(numbers) > 0 && numbers[len(numbers) - 1] > 64 {
+		bitmap = make([]byte, 16)
+		bitmap[0] |= 0x80
+	}
+
+	var fields []byte
+	for _, number := range numbers {
+		field, ok := s.Fields[number]
+		if !ok || number < 2 || number > 128 {
+			return nil, fmt.Errorf("%w: %d", ErrUnknownField, number)
+		}
+		bitmap[(number - 1) / 8] |= 0x80 >> ((number - 1) % 8)
+
+		value := m.Fields[number]
+		switch field.Type {
+		case TypeFixed:
+			if len(value) != field.Length {
+				return nil, fmt.Errorf("%w: field %d must have %d bytes, got %d", ErrInvalidMessage, number, field.Length, len(value))
+			}
+		default:
+			if len(value) > field.Length {
+				return nil, fmt.Errorf("%w: field %d must have at most %d bytes, got %d", ErrInvalidMessage, number, field.Length, len(value))
+			}
+			fields = append(fields, fmt.Sprintf("%0*d", prefixLength(field), len(value))...)
+		}
+		fields = append(fields, value...)
+	}
+
+	res := []byte(m.MTI)
+	if s.Bitmap == BitmapHex {
+		res = append(res, fmt.Sprintf("%X", bitmap)...)
+	} else {
+		res = append(res, bitmap...)
+	}
+	return append(res, fields...), nil
+}
+
+// About unpack a message, a field of the bitmap not defined in the spec is an error (its length is unknown)
+func (s *Spec) Unpack(data []byte) (*Message, error) {
+	if len(data) < 4 {
+		return nil, fmt.Errorf("%w: missing mti", ErrInvalidMessage)
+	}
+	m := NewMessage(string(data[:4]))
+	i := 4
+
+	bitmap, err := s.readBitmap(data, &i)
+	if err != nil {
+		return nil, err
+	}
+	if bitmap[0] & 0x80 != 0 {
+		secondary, err := s.readBitmap(data, &i)
+		if err != nil {
+			return nil, err
+		}
+		bitmap = append(bitmap, secondary...)
+	}
+
+	for number := 2; number <= len(bitmap) * 8; number++ {
+		if bitmap[(number - 1) / 8] & (0x80 >> ((number - 1) % 8)) == 0 {
+			continue
+		}
+		field, ok := s.Fields[number]
+		if !ok {
+			return nil, fmt.Errorf("%w: %d", ErrUnknownField, number)
+		}
+
+		length := field.Length
+		if field.Type != TypeFixed {
+			size := prefixLength(field)
+			if i + size > len(data) {
+				return nil, fmt.Errorf("%w: field %d length is truncated", ErrInvalidMessage, number)
+			}
+			// only digits, Atoi accepts a sign ("-1")
+			length, err = strconv.Atoi(string(data[i:i + size]))
+			if err != nil || !isDigits(data[i:i + size]) || length > field.Length {
+				return nil, fmt.Errorf("%w: field %d length %q is invalid", ErrInvalidMessage, number, data[i:i + size])
+			}
+			i = i + size
+		}
+		if i + length > len(data) {
+			return nil, fmt.Errorf("%w: field %d is truncated", ErrInvalidMessage, number)
+		}
+		m.Set(number, data[i:i + length])
+		i = i + length
+	}
+	if i != len(data) {
+		return nil, fmt.Errorf("%w: %d bytes after the last field", ErrInvalidMessage, len(data) - i)
+	}
+
+	return m, nil
+}
+
+// About read a bitmap (8 bytes) at i
+func (s *Spec) readBitmap(data []byte, i *int) ([]byte, error) {
+	if s.Bitmap == BitmapHex {
+		if *i + 16 > len(data) {
+			return nil, fmt.Errorf("%w: bitmap is truncated", ErrInvalidMessage)
+		}
+		bitmap, err := hex.DecodeString(string(data[*i:*i + 16]))
+		if err != nil {
+			return nil, fmt.Errorf("%w: bitmap must be hex encoded", ErrInvalidMessage)
+		}
+		*i = *i + 16
+		return bitmap, nil
+	}
+	if *i + 8 > len(data) {
+		return nil, fmt.Errorf("%w: bitmap is truncated", ErrInvalidMessage)
+	}
+	bitmap := append([]byte{}, data[*i:*i + 8]...)
+	*i = *i + 8
+	return bitmap, nil
+}
+
+func isDigits(value []byte) bool {
+	for _, b := range value {
+		if b < '0' || b > '9' {
+			return false
+		}
+	}
+	return len(value) > 0
+}
+
+// About the digits of the length prefix of a variable field
+func prefixLength(field FieldSpec) int {
+	if field.Type == TypeLLLVar {
+		return 3
+	}
+	return 2
+}
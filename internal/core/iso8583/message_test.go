@@ -0,0 +1,169 @@
+package iso8583
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func testMessage() *Message {
+	m := NewMessage("0100")
+	m.Set(FieldPAN, []byte("4111111111111111"))
+	m.Set(FieldProcessingCode, []byte("000000"))
+	m.Set(FieldAmount, []byte("000000012050"))
+	m.Set(11, []byte("123456"))
+	m.Set(FieldMcc, []byte("5411"))
+	m.Set(FieldCurrency, []byte("986"))
+	m.Set(FieldEMV, []byte{0x9F, 0x36, 0x02, 0x00, 0x06})
+	return m
+}
+
+func TestPackUnpack(t *testing.T) {
+	hexSpec := DefaultSpec()
+	hexSpec.Bitmap = BitmapHex
+
+	secondarySpec := DefaultSpec()
+	secondarySpec.Fields[102] = FieldSpec{Name: "account", Type: TypeLLVar, Length: 28}
+
+	tests := []struct {
+		name	string
+		spec	*Spec
+		message	func() *Message
+	}{
+		{"binary bitmap", DefaultSpec(), testMessage},
+		{"hex bitmap", hexSpec, testMessage},
+		{"secondary bitmap", secondarySpec, func() *Message {
+			m := testMessage()
+			m.Set(102, []byte("0001234567"))
+			return m
+		}},
+		{"empty variable field", DefaultSpec(), func() *Message {
+			m := testMessage()
+			m.Set(FieldEMV, []byte{})
+			return m
+		}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			message := test.message()
+			data, err := test.spec.Pack(message)
+			if err != nil {
+				t.Fatalf("pack: %v", err)
+			}
+			res, err := test.spec.Unpack(data)
+			if err != nil {
+				t.Fatalf("unpack: %v", err)
+			}
+			if res.MTI != message.MTI {
+				t.Errorf("mti %s, want %s", res.MTI, message.MTI)
+			}
+			if len(res.Fields) != len(message.Fields) {
+				t.Fatalf("fields %v, want %v", res.Numbers(), message.Numbers())
+			}
+			for number, value := range message.Fields {
+				if got, _ := res.Get(number); !bytes.Equal(got, value) {
+					t.Errorf("field %d %q, want %q", number, got, value)
+				}
+			}
+		})
+	}
+}
+
+func TestPackInvalid(t *testing.T) {
+	spec := DefaultSpec()
+
+	tests := []struct {
+		name	string
+		message	func() *Message
+		err		error
+	}{
+		{"mti", func() *Message { m := testMessage(); m.MTI = "100"; return m }, ErrInvalidMessage},
+		{"fixed length", func() *Message { m := testMessage(); m.Set(FieldAmount, []byte("12050")); return m }, ErrInvalidMessage},
+		{"variable too long", func() *Message { m := testMessage(); m.Set(FieldPAN, []byte("41111111111111111111")); return m }, ErrInvalidMessage},
+		{"unknown field", func() *Message { m := testMessage(); m.Set(5, []byte("1")); return m }, ErrUnknownField},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if _, err := spec.Pack(test.message()); !errors.Is(err, test.err) {
+				t.Errorf("err %v, want %v", err, test.err)
+			}
+		})
+	}
+}
+
+func TestUnpackMalformed(t *testing.T) {
+	spec := DefaultSpec()
+	hexSpec := DefaultSpec()
+	hexSpec.Bitmap = BitmapHex
+
+	// 0100 with only the DE2 (bitmap 0x40)
+	frame := func(field string) []byte {
+		return append([]byte("0100\x40\x00\x00\x00\x00\x00\x00\x00"), field...)
+	}
+	valid, err := spec.Pack(testMessage())
+	if err != nil {
+		t.Fatalf("pack: %v", err)
+	}
+
+	tests := []struct {
+		name	string
+		spec	*Spec
+		data	[]byte
+		err		error
+	}{
+		{"empty", spec, []byte{}, ErrInvalidMessage},
+		{"missing mti", spec, []byte("010"), ErrInvalidMessage},
+		{"truncated bitmap", spec, []byte("0100\x40\x00"), ErrInvalidMessage},
+		{"truncated secondary bitmap", spec, []byte("0100\xC0\x00\x00\x00\x00\x00\x00\x00\x00\x00"), ErrInvalidMessage},
+		{"invalid hex bitmap", hexSpec, []byte("0100ZZ00000000000000"), ErrInvalidMessage},
+		{"negative length", spec, frame("-1"), ErrInvalidMessage},
+		{"signed length", spec, frame("+4" + "4111"), ErrInvalidMessage},
+		{"space in length", spec, frame(" 4" + "4111"), ErrInvalidMessage},
+		{"non digit length", spec, frame("AB"), ErrInvalidMessage},
+		{"length over max", spec, frame("20" + "41111111111111111111"), ErrInvalidMessage},
+		{"truncated length", spec, frame("1"), ErrInvalidMessage},
+		{"truncated field", spec, frame("16" + "4111"), ErrInvalidMessage},
+		{"trailing bytes", spec, append(append([]byte{}, valid...), '0'), ErrInvalidMessage},
+		{"unknown field", spec, []byte("0100\x08\x00\x00\x00\x00\x00\x00\x00" + "1"), ErrUnknownField},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if _, err := test.spec.Unpack(test.data); !errors.Is(err, test.err) {
+				t.Errorf("err %v, want %v", err, test.err)
+			}
+		})
+	}
+}
+
+func TestFrame(t *testing.T) {
+	for _, lengthHeader := range []int{2, 4} {
+		spec := DefaultSpec()
+		spec.LengthHeader = lengthHeader
+
+		var buffer bytes.Buffer
+		if err := spec.WriteFrame(&buffer, []byte("0800")); err != nil {
+			t.Fatalf("write frame: %v", err)
+		}
+		if buffer.Len() != lengthHeader + 4 {
+			t.Errorf("frame of %d bytes, want %d", buffer.Len(), lengthHeader + 4)
+		}
+		data, err := spec.ReadFrame(&buffer)
+		if err != nil {
+			t.Fatalf("read frame: %v", err)
+		}
+		if string(data) != "0800" {
+			t.Errorf("frame %q, want 0800", data)
+		}
+	}
+
+	spec := DefaultSpec()
+	if _, err := spec.ReadFrame(bytes.NewReader([]byte{0x00, 0x00})); !errors.Is(err, ErrInvalidMessage) {
+		t.Errorf("empty frame err %v, want %v", err, ErrInvalidMessage)
+	}
+	if _, err := spec.ReadFrame(bytes.NewReader([]byte{0xFF, 0xFF})); !errors.Is(err, ErrInvalidMessage) {
+		t.Errorf("frame over max err %v, want %v", err, ErrInvalidMessage)
+	}
+}
@@ -0,0 +1,147 @@
+package iso8583
+
+import (
+	"os"
+	"fmt"
+	"bytes"
+	"errors"
+	"encoding/json"
+)
+
+// the kinds of field, the variable fields have an ascii length prefix (2 or 3 digits)
+const (
+	TypeFixed		= "FIXED"
+	TypeLLVar		= "LLVAR"
+	TypeLLLVar		= "LLLVAR"
+
+	EncodingASCII	= "ASCII"
+	EncodingBinary	= "BINARY"
+
+	BitmapBinary	= "BINARY" // 8 bytes for each bitmap
+	BitmapHex		= "HEX" // 16 hex digits for each bitmap
+)
+
+// the fields read and written by the authorization
+const (
+	FieldPAN				= 2
+	FieldProcessingCode		= 3
+	FieldAmount				= 4
+	FieldMcc				= 18
+	FieldPosEntryMode		= 22
+	FieldApprovalCode		= 38
+	FieldResponseCode		= 39
+	FieldMerchantID			= 42
+	FieldMerchantName		= 43
+	FieldCurrency			= 49
+	FieldEMV				= 55
+)
+
+// the fields that every spec must have
+var RequiredFields = []int{FieldPAN, FieldAmount, FieldResponseCode, FieldEMV}
+
+type FieldSpec struct {
+	Name		string	`json:"name"`
+	Type		string	`json:"type"` // FIXED, LLVAR or LLLVAR
+	Length		int		`json:"length"` // the length of a fixed field, the max length of a variable field
+	Encoding	string	`json:"encoding"` // ASCII (default) or BINARY, the length of a binary field is in bytes
+}
+
+// About the field spec of the messages and their framing
+type Spec struct {
+	LengthHeader	int					`json:"length_header"` // bytes of the frame length (big endian), 2 or 4
+	Bitmap			string				`json:"bitmap"` // BINARY or HEX
+	Fields			map[int]FieldSpec	`json:"fields"`
+}
+
+// About the default spec, ascii fields with a binary bitmap and a 2 bytes frame length
+func DefaultSpec() *Spec {
+	return &Spec{
+		LengthHeader:	2,
+		Bitmap:			BitmapBinary,
+		Fields: map[int]FieldSpec{
+			2:	{Name: "pan", Type: TypeLLVar, Length: 19},
+			3:	{Name: "processing code", Type: TypeFixed, Length: 6},
+			4:	{Name: "amount", Type: TypeFixed, Length: 12},
+			7:	{Name: "transmission date time", Type: TypeFixed, Length: 10},
+			11:	{Name: "stan", Type: TypeFixed, Length: 6},
+			12:	{Name: "local time", Type: TypeFixed, Length: 6},
+			13:	{Name: "local date", Type: TypeFixed, Length: 4},
+			14:	{Name: "expiration date", Type: TypeFixed, Length: 4},
+			18:	{Name: "mcc", Type: TypeFixed, Length: 4},
+			22:	{Name: "pos entry mode", Type: TypeFixed, Length: 3},
+			32:	{Name: "acquirer id", Type: TypeLLVar, Length: 11},
+			37:	{Name: "retrieval reference number", Type: TypeFixed, Length: 12},
+			38:	{Name: "approval code", Type: TypeFixed, Length: 6},
+			39:	{Name: "response code", Type: TypeFixed, Length: 2},
+			41:	{Name: "terminal id", Type: TypeFixed, Length: 8},
+			42:	{Name: "merchant id", Type: TypeFixed, Length: 15},
+			43:	{Name: "merchant name and location", Type: TypeFixed, Length: 40},
+			49:	{Name: "currency", Type: TypeFixed, Length: 3},
+			55:	{Name: "emv data", Type: TypeLLLVar, Length: 255, Encoding: EncodingBinary},
+		},
+	}
+}
+
+// About load a spec file (json), the fields missing in the file are not defined
+func LoadSpec(path string) (*Spec, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	spec := Spec{}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&spec); err != nil {
+		return nil, err
+	}
+	return &spec, nil
+}
+
+// About validate the spec, every error is returned
+func (s *Spec) Validate() error {
+	var errs []error
+
+	if s.LengthHeader != 2 && s.LengthHeader != 4 {
+		errs = append(errs, fmt.Errorf("length_header must be 2 or 4, got %d", s.LengthHeader))
+	}
+	if s.Bitmap != BitmapBinary && s.Bitmap != BitmapHex {
+		errs = append(errs, fmt.Errorf("bitmap must be %s or %s, got %q", BitmapBinary, BitmapHex, s.Bitmap))
+	}
+	for number, field := range s.Fields {
+		// the field 1 is the secondary bitmap
+		if number < 2 || number > 128 {
+			errs = append(errs, fmt.Errorf("field %d must be between 2 and 128", number))
+			continue
+		}
+		maxLength := 0
+		switch field.Type {
+		case TypeFixed:
+		case TypeLLVar:
+			maxLength = 99
+		case TypeLLLVar:
+			maxLength = 999
+		default:
+			errs = append(errs, fmt.Errorf("field %d type must be %s, %s or %s, got %q", number, TypeFixed, TypeLLVar, TypeLLLVar, field.Type))
+		}
+		if field.Length <= 0 || (maxLength > 0 && field.Length > maxLength) {
+			errs = append(errs, fmt.Errorf("field %d length %d is invalid", number, field.Length))
+		}
+		if field.Encoding != "" && field.Encoding != EncodingASCII && field.Encoding != EncodingBinary {
+			errs = append(errs, fmt.Errorf("field %d encoding must be %s or %s, got %q", number, EncodingASCII, EncodingBinary, field.Encoding))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// About check that the spec defines the fields
+func (s *Spec) Check(fields []int) error {
+	var errs []error
+	for _, number := range fields {
+		if _, ok := s.Fields[number]; !ok {
+			errs = append(errs, fmt.Errorf("field %d is not defined", number))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -11,7 +11,11 @@ type AppServer struct {
 	Server     		*Server     				`json:"server"`
 	ConfigOTEL		*go_core_observ.ConfigOTEL	`json:"otel_config"`
 	DatabaseConfig	*go_core_pg.DatabaseConfig  `json:"database"`
-	ApiService 		*[]ApiService				`json:"api_endpoints"`
+	ApiService 		map[string]ApiService		`json:"api_endpoints"`
+	RateLimit		*RateLimit					`json:"rate_limit"`
+	FeatureFlags	map[string]bool				`json:"feature_flags"`
+	AccountCache	*CacheConfig				`json:"account_cache"`
+	ExpiryJob		*ExpiryJob					`json:"expiry_job"`
 }
 
 type InfoPod struct {
@@ -34,6 +38,31 @@ type Server struct {
 	WriteTimeout			int `json:"writeTimeout"`
 	IdleTimeout				int `json:"idleTimeout"`
 	CtxTimeout				int `json:"ctxTimeout"`
+	LogLevel				string `json:"logLevel"`
+	GrpcPort				int `json:"grpcPort"`
+	IsoPort					int `json:"isoPort"`
+	AdminToken				string `json:"-"`
+}
+
+type CacheConfig struct {
+	Size					int `json:"size"`
+	TTL						time.Duration `json:"ttl"`
+}
+
+type ExpiryJob struct {
+	Interval				time.Duration `json:"interval"`
+	RenewalDays				int `json:"renewal_days"`
+	ChunkSize				int `json:"chunk_size"`
+}
+
+type ExpiryJobResult struct {
+	Expired					int `json:"expired"`
+	RenewalCandidates		int `json:"renewal_candidates"`
+}
+
+type RateLimit struct {
+	RequestsPerSecond		int `json:"requests_per_second"`
+	Burst					int `json:"burst"`
 }
 
 type ApiService struct {
@@ -43,6 +72,20 @@ type ApiService struct {
 	XApigwApiId		string `json:"x-apigw-api-id,omitempty"`
 	HostName		string `json:"host_name"`
 	HttpTimeout		time.Duration `json:"httpTimeout"`
+	CircuitBreaker	CircuitBreakerConfig `json:"circuit_breaker"`
+	Retry			RetryConfig `json:"retry"`
+}
+
+type CircuitBreakerConfig struct {
+	FailureThreshold	int				`json:"failure_threshold"`
+	OpenTimeout			time.Duration	`json:"open_timeout"`
+	HalfOpenMaxCalls	int				`json:"half_open_max_calls"`
+}
+
+type RetryConfig struct {
+	MaxAttempts			int				`json:"max_attempts"`
+	BaseDelay			time.Duration	`json:"base_delay"`
+	MaxDelay			time.Duration	`json:"max_delay"`
 }
 
 type MessageRouter struct {
@@ -62,16 +105,147 @@ type Account struct {
 type Card struct {
 	ID				int			`json:"id,omitempty"`
 	FkAccountID		int			`json:"fk_account_id,omitempty"`
-	AccountID		string		`json:"account_id,omitempty"`	
-	CardNumber		string  	`json:"card_number,omitempty"`
+	AccountID		string		`json:"account_id,omitempty" validate:"required"`	
+	CardNumber		string  	`json:"card_number,omitempty" validate:"required,pan,luhn"`
 	TokenData		string  	`json:"token_data,omitempty"`
-	Holder			string  	`json:"holder,omitempty"`
-	Type			string  	`json:"type,omitempty"`
-	Model			string  	`json:"model,omitempty"`
+	Holder			string  	`json:"holder,omitempty" validate:"required,min=2,max=26,holder"`
+	Type			string  	`json:"type,omitempty" validate:"required,oneof=CREDIT DEBIT PREPAID"`
+	Model			string  	`json:"model,omitempty" validate:"required,oneof=CHIP CONTACTLESS VIRTUAL"`
 	Atc				int			`json:"atc,omitempty"`
 	Status			string  	`json:"status,omitempty"`
 	ExpiredAt		time.Time 	`json:"expired_at,omitempty"`
 	CreatedAt		time.Time 	`json:"created_at,omitempty"`
 	UpdatedAt		*time.Time 	`json:"updated_at,omitempty"`
 	TenantID		string  	`json:"tenant_id,omitempty"`
-}
\ No newline at end of file
+	PredecessorID	int			`json:"predecessor_id,omitempty"`
+	ReissueReason	string  	`json:"reissue_reason,omitempty"`
+	Cvv2			string  	`json:"cvv2,omitempty" validate:"isdefault"`
+	CvvFailures		int			`json:"-"`
+	PinPvv			string		`json:"-"`
+	PinPvki			int			`json:"-"`
+	PinFailures		int			`json:"-"`
+}
+
+type CardReissue struct {
+	Reason			string		`json:"reason" validate:"required,oneof=LOST STOLEN DAMAGED RENEWAL"`
+}
+
+type CardCvvVerify struct {
+	Cvv2			string		`json:"cvv2" validate:"required,numeric,len=3"`
+}
+
+type CardDcvvVerify struct {
+	Dcvv			string		`json:"dcvv" validate:"required,numeric,len=3"`
+}
+
+type DynamicCvv struct {
+	Dcvv			string		`json:"dcvv"`
+	ExpiresAt		time.Time	`json:"expires_at"`
+}
+
+type CardPin struct {
+	PinBlock		string		`json:"pin_block" validate:"required,pin_block"`
+	Format			int			`json:"format" validate:"oneof=0 4"`
+}
+
+type CardPinChange struct {
+	PinBlock		string		`json:"pin_block" validate:"required,pin_block"`
+	NewPinBlock		string		`json:"new_pin_block" validate:"required,pin_block"`
+	Format			int			`json:"format" validate:"oneof=0 4"`
+}
+
+type PinVerification struct {
+	Verified		bool		`json:"verified"`
+	AttemptsLeft	int			`json:"attempts_left"`
+}
+
+type CvvVerification struct {
+	Verified		bool		`json:"verified"`
+	AttemptsLeft	int			`json:"attempts_left"`
+}
+
+type CardControl struct {
+	Currency				string			`json:"currency" validate:"required,len=3,alpha,uppercase"`
+	TransactionLimit		*int64			`json:"transaction_limit,omitempty" validate:"omitempty,gte=0"`
+	DailyLimit				*int64			`json:"daily_limit,omitempty" validate:"omitempty,gte=0"`
+	MonthlyLimit			*int64			`json:"monthly_limit,omitempty" validate:"omitempty,gte=0"`
+	MccAllow				[]string		`json:"mcc_allow,omitempty" validate:"max=200,dive,numeric,len=4"`
+	MccDeny					[]string		`json:"mcc_deny,omitempty" validate:"max=200,dive,numeric,len=4"`
+	EcommerceEnabled		*bool			`json:"ecommerce_enabled" validate:"required"`
+	InternationalEnabled	*bool			`json:"international_enabled" validate:"required"`
+	AtmTransactionLimit		*int64			`json:"atm_transaction_limit,omitempty" validate:"omitempty,gte=0"`
+	AtmDailyLimit			*int64			`json:"atm_daily_limit,omitempty" validate:"omitempty,gte=0"`
+	Usage					ControlUsage	`json:"usage"`
+	UpdatedAt				*time.Time		`json:"updated_at,omitempty"`
+}
+
+type ControlUsage struct {
+	Date			time.Time	`json:"date"`
+	DailySpent		int64		`json:"daily_spent"`
+	MonthlySpent	int64		`json:"monthly_spent"`
+	AtmDailySpent	int64		`json:"atm_daily_spent"`
+}
+
+// the amounts are in minor units of the currency (ex: 12050 is 120.50 BRL), like the DE4 of the iso 8583
+type CardTransaction struct {
+	Amount			int64		`json:"amount" validate:"required,gt=0"`
+	Currency		string		`json:"currency" validate:"required,len=3,alpha,uppercase"`
+	Mcc				string		`json:"mcc" validate:"required,numeric,len=4"`
+	Channel			string		`json:"channel" validate:"required,oneof=POS ECOMMERCE ATM"`
+}
+
+type ControlEvaluation struct {
+	Decision		string		`json:"decision"`
+	Reasons			[]string	`json:"reasons,omitempty"`
+}
+
+type CardAuthorize struct {
+	CardTransaction
+	Emv				string		`json:"emv,omitempty" validate:"omitempty,hexadecimal,max=512"`
+	MerchantID		string		`json:"merchant_id,omitempty" validate:"max=15"`
+	MerchantName	string		`json:"merchant_name,omitempty" validate:"max=40"`
+}
+
+type Authorization struct {
+	ID				int64		`json:"id,omitempty"`
+	CardID			int			`json:"-"`
+	Amount			int64		`json:"amount"`
+	Currency		string		`json:"currency"`
+	Mcc				string		`json:"mcc"`
+	Channel			string		`json:"channel"`
+	MerchantID		string		`json:"merchant_id,omitempty"`
+	MerchantName	string		`json:"merchant_name,omitempty"`
+	Atc				int			`json:"atc,omitempty"`
+	ResponseCode	string		`json:"response_code"`
+	Decision		string		`json:"decision"`
+	Reasons			[]string	`json:"reasons,omitempty"`
+	TraceID			string		`json:"trace_id,omitempty"`
+	CreatedAt		time.Time	`json:"created_at"`
+}
+
+type AuthorizationFilter struct {
+	From			time.Time
+	To				time.Time
+	Limit			int
+	Cursor			string
+}
+
+type AuthorizationPage struct {
+	Authorizations	[]Authorization	`json:"authorizations"`
+	NextCursor		string			`json:"next_cursor,omitempty"`
+}
+
+type Readiness struct {
+	Status			string				`json:"status"`
+	CheckedAt		time.Time			`json:"checked_at"`
+	Dependencies	[]DependencyStatus	`json:"dependencies"`
+}
+
+type DependencyStatus struct {
+	Name			string		`json:"name"`
+	Status			string		`json:"status"`
+	Critical		bool		`json:"critical"`
+	LatencyMs		int64		`json:"latency_ms"`
+	Error			string		`json:"error,omitempty"`
+	Details			interface{}	`json:"details,omitempty"`
+}
@@ -0,0 +1,79 @@
+package pan
+
+import (
+	"errors"
+	"strings"
+	"math/big"
+	"crypto/rand"
+)
+
+// About the digits of a card number, without the separators (ex: 5550.0000.0000.0004)
+func Digits(cardNumber string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= '0' && r <= '9' {
+			return r
+		}
+		return -1
+	}, cardNumber)
+}
+
+// About the luhn (mod 10) check of the digits
+func Valid(digits string) bool {
+	if digits == "" {
+		return false
+	}
+	return luhnSum(digits, false) % 10 == 0
+}
+
+// About the luhn check digit of the digits without it
+func CheckDigit(payload string) byte {
+	return byte('0' + (10 - luhnSum(payload, true) % 10) % 10)
+}
+
+func luhnSum(digits string, double bool) int {
+	sum := 0
+	for i := len(digits) - 1; i >= 0; i-- {
+		d := int(digits[i] - '0')
+		if double {
+			d = d * 2
+			if d > 9 {
+				d = d - 9
+			}
+		}
+		sum += d
+		double = !double
+	}
+	return sum
+}
+
+// About generate a new card number from an existing one
+// The BIN (6 first digits), the length and the separators are kept, the account digits are random and the check digit is valid
+func Generate(cardNumber string) (string, error) {
+	digits := Digits(cardNumber)
+	if len(digits) < 8 {
+		return "", errors.New("card number too short to keep the BIN")
+	}
+
+	payload := []byte(digits[:6])
+	for len(payload) < len(digits) - 1 {
+		n, err := rand.Int(rand.Reader, big.NewInt(10))
+		if err != nil {
+			return "", err
+		}
+		payload = append(payload, byte('0' + n.Int64()))
+	}
+	newDigits := string(payload) + string(CheckDigit(string(payload)))
+
+	// keep the separators of the original card number
+	var res strings.Builder
+	i := 0
+	for _, r := range cardNumber {
+		if r >= '0' && r <= '9' {
+			res.WriteByte(newDigits[i])
+			i++
+			continue
+		}
+		res.WriteRune(r)
+	}
+	return res.String(), nil
+}
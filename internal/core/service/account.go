@@ -0,0 +1,139 @@
+package service
+
+import(
+	"fmt"
+	"time"
+	"errors"
+	"context"
+	"encoding/json"
+
+	"github.com/go-card/internal/core/model"
+
+	go_core_api "github.com/eliezerraj/go-core/api"
+)
+
+// the feature flag that skips the account cache
+const flagAccountCacheBypass = "account_cache_bypass"
+
+// About the account cache keys, the account is cached by account_id and by id (PK)
+func accountIDKey(accountID string) string {
+	return "account_id:" + accountID
+}
+
+func accountPKKey(id int) string {
+	return fmt.Sprintf("id:%d", id)
+}
+
+// About get the account from its account_id (ex: ACC-001)
+func (s *WorkerService) getAccountByAccountID(ctx context.Context, accountID string) (*model.Account, error){
+	return s.getAccount(ctx, accountIDKey(accountID), "/get/" + accountID)
+}
+
+// About get the account from its id (PK)
+func (s *WorkerService) getAccountByID(ctx context.Context, id int) (*model.Account, error){
+	return s.getAccount(ctx, accountPKKey(id), "/getId/" + fmt.Sprintf("%v", id))
+}
+
+// About get the account from the cache or from the account service
+// The mapping account_id <=> id is immutable, so both keys are cached at every load
+// The feature flag account_cache_bypass skips the cache (ex: a bad mapping cached), it can be set with a config reload
+func (s *WorkerService) getAccount(ctx context.Context, key string, path string) (*model.Account, error){
+	childLogger.Info().Str("func","getAccount").Ctx(ctx).Interface("trace-resquest-id", ctx.Value("trace-request-id")).Str("key", key).Send()
+
+	// trace
+	ctx, span := tracerProvider.SpanCtx(ctx, "service.getAccount")
+	defer span.End()
+
+	if s.IsFeatureEnabled(flagAccountCacheBypass) {
+		return s.loadAccount(ctx, path)
+	}
+
+	accountService, err := s.getApiService(accountServiceName)
+	if err != nil {
+		return nil, err
+	}
+
+	account, err := s.accountCache.GetOrLoad(ctx, key, loadTimeout(accountService), func(ctx context.Context) (model.Account, error) {
+		account, err := s.loadAccount(ctx, path)
+		if err != nil {
+			return model.Account{}, err
+		}
+		s.accountCache.Set(accountIDKey(account.AccountID), *account)
+		s.accountCache.Set(accountPKKey(account.ID), *account)
+		return *account, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &account, nil
+}
+
+// About the timeout of a load shared by the callers, every attempt with its backoff
+func loadTimeout(downstream model.ApiService) time.Duration {
+	attempts := max(downstream.Retry.MaxAttempts, 1)
+	return downstream.HttpTimeout * time.Duration(attempts) + downstream.Retry.MaxDelay * time.Duration(attempts - 1)
+}
+
+// About call the account service
+func (s *WorkerService) loadAccount(ctx context.Context, path string) (*model.Account, error){
+	childLogger.Info().Str("func","loadAccount").Ctx(ctx).Interface("trace-resquest-id", ctx.Value("trace-request-id")).Str("path", path).Send()
+
+	trace_id := fmt.Sprintf("%v",ctx.Value("trace-request-id"))
+	accountService, err := s.getApiService(accountServiceName)
+	if err != nil {
+		return nil, err
+	}
+
+	// Set headers
+	headers := map[string]string{
+		"Content-Type":  "application/json;charset=UTF-8",
+		"X-Request-Id": trace_id,
+		"x-apigw-api-id": accountService.XApigwApiId,
+		"Host": accountService.HostName,
+	}
+	// Set client http
+	httpClient := go_core_api.HttpClient {
+		Url: 	accountService.Url + path,
+		Method: accountService.Method,
+		Timeout: accountService.HttpTimeout,
+		Headers: &headers,
+	}
+
+	res_payload, statusCode, err := s.callApiService(ctx, accountService, httpClient)
+	if err != nil {
+		return nil, errorStatusCode(statusCode, accountService.Name, err)
+	}
+
+	jsonString, err  := json.Marshal(res_payload)
+	if err != nil {
+		return nil, errors.New(err.Error())
+	}
+	var account_parsed model.Account
+	err = json.Unmarshal(jsonString, &account_parsed)
+	if err != nil {
+		return nil, errors.New(err.Error())
+	}
+
+	return &account_parsed, nil
+}
+
+// About purge the account cache, all entries when accountID is empty
+func (s *WorkerService) PurgeAccountCache(ctx context.Context, accountID string) int {
+	childLogger.Info().Str("func","PurgeAccountCache").Ctx(ctx).Interface("trace-resquest-id", ctx.Value("trace-request-id")).Str("account_id", accountID).Send()
+
+	if accountID == "" {
+		return s.accountCache.Purge()
+	}
+
+	count := 0
+	if account, ok := s.accountCache.Get(ctx, accountIDKey(accountID)); ok {
+		if s.accountCache.Delete(accountPKKey(account.ID)) {
+			count++
+		}
+	}
+	if s.accountCache.Delete(accountIDKey(accountID)) {
+		count++
+	}
+	return count
+}
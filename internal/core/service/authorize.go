@@ -0,0 +1,207 @@
+package service
+
+import(
+	"fmt"
+	"time"
+	"errors"
+	"context"
+	"encoding/hex"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/go-card/internal/core/model"
+	"github.com/go-card/internal/core/erro"
+	"github.com/go-card/internal/core/emv"
+)
+
+const (
+	cardStatusExpired	= "EXPIRED"
+
+	// the max gap between the atc of the chip and the last atc of the card
+	atcWindow			= 32
+)
+
+// the ISO 8583 response codes (DE39) of the authorization
+const (
+	ResponseApproved		= "00"
+	ResponseDoNotHonor		= "05"
+	ResponseInvalidCard		= "14"
+	ResponseFormatError		= "30"
+	ResponseExpiredCard		= "54"
+	ResponseNotPermitted	= "57"
+	ResponseExceedsLimit	= "61"
+	ResponseRestrictedCard	= "62"
+	ResponseSystemError		= "96" // the authorization failed (ex: database), not a decline
+)
+
+// the decline reasons of the card and the chip, the controls have their own reasons
+const (
+	reasonCardNotFound	= "CARD_NOT_FOUND"
+	reasonCardBlocked	= "CARD_BLOCKED"
+	reasonCardCanceled	= "CARD_CANCELED"
+	reasonCardExpired	= "CARD_EXPIRED"
+	reasonEmvInvalid	= "EMV_INVALID"
+	reasonAtcInvalid	= "ATC_INVALID"
+)
+
+// About the response code of each decline reason of the controls
+var controlResponseCodes = map[string]string{
+	reasonTransactionLimit:			ResponseExceedsLimit,
+	reasonDailyLimit:				ResponseExceedsLimit,
+	reasonMonthlyLimit:				ResponseExceedsLimit,
+	reasonAtmTransactionLimit:		ResponseExceedsLimit,
+	reasonAtmDailyLimit:			ResponseExceedsLimit,
+	reasonMccNotAllowed:			ResponseNotPermitted,
+	reasonMccDenied:				ResponseNotPermitted,
+	reasonEcommerceDisabled:		ResponseNotPermitted,
+	reasonInternationalDisabled:	ResponseNotPermitted,
+}
+
+// About decline an authorization
+func decline(authorization *model.Authorization, responseCode string, reasons ...string) {
+	authorization.Decision = decisionDecline
+	authorization.ResponseCode = responseCode
+	authorization.Reasons = reasons
+}
+
+// About the atc of the chip (tag 9F36 of the emv data), or the next atc of the card without emv data (e-commerce)
+// The atc of the chip must be greater than the last atc of the card (replay) and in the atc window
+func nextAtc(card model.Card, emvData string) (int, string) {
+	if emvData == "" {
+		return card.Atc + 1, ""
+	}
+
+	data, err := hex.DecodeString(emvData)
+	if err != nil {
+		return 0, reasonEmvInvalid
+	}
+	tags, err := emv.Parse(data)
+	if err != nil {
+		return 0, reasonEmvInvalid
+	}
+	atc, err := emv.ATC(tags)
+	if err != nil {
+		return 0, reasonEmvInvalid
+	}
+	if atc <= card.Atc || atc - card.Atc > atcWindow {
+		return 0, reasonAtcInvalid
+	}
+	return atc, ""
+}
+
+// About authorize a transaction of a card, every attempt is recorded with its ISO 8583 response code
+// In one transaction (the card is locked): the status and expiry of the card, the atc, then the controls.
+// The atc is updated when it is valid, even when the controls decline, and the usage of the controls only when approved
+// A decline is not an error, an error is a failure of the authorization
+func (s *WorkerService) Authorize(ctx context.Context, card model.Card, authorize model.CardAuthorize) (*model.Authorization, error){
+	childLogger.Info().Str("func","Authorize").Ctx(ctx).Interface("trace-resquest-id", ctx.Value("trace-request-id")).Interface("card", card).Interface("authorize", authorize.CardTransaction).Send()
+
+	// trace
+	ctx, span := tracerProvider.SpanCtx(ctx, "service.Authorize")
+	defer span.End()
+
+	authorization := model.Authorization{
+		Amount:			authorize.Amount,
+		Currency:		authorize.Currency,
+		Mcc:			authorize.Mcc,
+		Channel:		authorize.Channel,
+		MerchantID:		authorize.MerchantID,
+		MerchantName:	authorize.MerchantName,
+		TraceID:		fmt.Sprintf("%v", ctx.Value("trace-request-id")),
+	}
+
+	// the card not found can't be recorded
+	res_card, err := s.workerRepository.GetCard(ctx, card)
+	if errors.Is(err, erro.ErrNotFound) {
+		decline(&authorization, ResponseInvalidCard, reasonCardNotFound)
+		authorization.CreatedAt = time.Now()
+		s.metrics.recordAuthorization(ctx, model.Card{}, authorization)
+		return &authorization, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	authorization.CardID = res_card.ID
+
+	var res *model.Authorization
+	err = s.workerRepository.WithTx(ctx, func(tx pgx.Tx) error {
+		locked_card, err := s.workerRepository.GetCardForUpdate(ctx, tx, res_card.ID)
+		if err != nil {
+			return err
+		}
+		res_card = locked_card
+		now := time.Now()
+
+		switch {
+		case locked_card.Status == cardStatusBlocked:
+			decline(&authorization, ResponseRestrictedCard, reasonCardBlocked)
+		case locked_card.Status == cardStatusCanceled:
+			decline(&authorization, ResponseRestrictedCard, reasonCardCanceled)
+		case locked_card.Status == cardStatusExpired || !now.Before(locked_card.ExpiredAt):
+			decline(&authorization, ResponseExpiredCard, reasonCardExpired)
+		}
+		if authorization.Decision == decisionDecline {
+			res, err = s.workerRepository.AddAuthorization(ctx, tx, authorization)
+			return err
+		}
+
+		// atc
+		atc, reason := nextAtc(*locked_card, authorize.Emv)
+		switch reason {
+		case reasonEmvInvalid:
+			decline(&authorization, ResponseFormatError, reason)
+		case reasonAtcInvalid:
+			decline(&authorization, ResponseDoNotHonor, reason)
+		}
+		if authorization.Decision == decisionDecline {
+			res, err = s.workerRepository.AddAuthorization(ctx, tx, authorization)
+			return err
+		}
+		locked_card.Atc = atc
+		_, err = s.workerRepository.UpdateCardAtc(ctx, tx, *locked_card)
+		if err != nil {
+			return err
+		}
+		authorization.Atc = atc
+
+		// controls
+		control, err := s.workerRepository.GetCardControlForUpdate(ctx, tx, locked_card.ID)
+		if errors.Is(err, erro.ErrNotFound) {
+			default_control := defaultCardControl(now)
+			control = &default_control
+		} else if err != nil {
+			return err
+		}
+
+		evaluation := evaluateControls(*control, authorize.CardTransaction, now)
+		if evaluation.Decision == decisionDecline {
+			decline(&authorization, controlResponseCodes[evaluation.Reasons[0]], evaluation.Reasons...)
+		} else {
+			authorization.Decision = decisionApprove
+			authorization.ResponseCode = ResponseApproved
+
+			usage := usageAt(control.Usage, now)
+			usage.DailySpent += authorize.Amount
+			usage.MonthlySpent += authorize.Amount
+			if authorize.Channel == channelATM {
+				usage.AtmDailySpent += authorize.Amount
+			}
+			_, err = s.workerRepository.UpdateCardControlUsage(ctx, tx, locked_card.ID, control.Currency, usage)
+			if err != nil {
+				return err
+			}
+		}
+
+		res, err = s.workerRepository.AddAuthorization(ctx, tx, authorization)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.metrics.recordAuthorization(ctx, *res_card, *res)
+	if res.Decision == decisionDecline {
+		childLogger.Info().Ctx(ctx).Int("card_id", res_card.ID).Str("response_code", res.ResponseCode).Strs("reasons", res.Reasons).Msg("authorization declined")
+	}
+
+	return res, nil
+}
@@ -0,0 +1,201 @@
+package service
+
+import(
+	"time"
+	"errors"
+	"slices"
+	"context"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/go-card/internal/core/model"
+	"github.com/go-card/internal/core/erro"
+)
+
+const (
+	defaultControlCurrency	= "BRL" // currency of the limits of a card without controls
+
+	channelEcommerce		= "ECOMMERCE"
+	channelATM				= "ATM"
+
+	decisionApprove			= "APPROVE"
+	decisionDecline			= "DECLINE"
+)
+
+// the decline reasons of the controls
+const (
+	reasonTransactionLimit		= "TRANSACTION_LIMIT_EXCEEDED"
+	reasonDailyLimit			= "DAILY_LIMIT_EXCEEDED"
+	reasonMonthlyLimit			= "MONTHLY_LIMIT_EXCEEDED"
+	reasonMccNotAllowed			= "MCC_NOT_ALLOWED"
+	reasonMccDenied				= "MCC_DENIED"
+	reasonEcommerceDisabled		= "ECOMMERCE_DISABLED"
+	reasonInternationalDisabled	= "INTERNATIONAL_DISABLED"
+	reasonAtmTransactionLimit	= "ATM_TRANSACTION_LIMIT_EXCEEDED"
+	reasonAtmDailyLimit			= "ATM_DAILY_LIMIT_EXCEEDED"
+)
+
+// About the controls of a card without controls, no limit and every channel enabled
+func defaultCardControl(now time.Time) model.CardControl {
+	enabled := true
+	return model.CardControl{
+		Currency:				defaultControlCurrency,
+		EcommerceEnabled:		&enabled,
+		InternationalEnabled:	&enabled,
+		Usage:					model.ControlUsage{Date: usageDate(now)},
+	}
+}
+
+// About the day of the usage counters (UTC)
+func usageDate(now time.Time) time.Time {
+	year, month, day := now.UTC().Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+// About the usage counters at now, the daily counters restart every day and the monthly counter every month
+func usageAt(usage model.ControlUsage, now time.Time) model.ControlUsage {
+	today := usageDate(now)
+	if usage.Date.Equal(today) {
+		return usage
+	}
+	res := model.ControlUsage{Date: today}
+	if usage.Date.Year() == today.Year() && usage.Date.Month() == today.Month() {
+		res.MonthlySpent = usage.MonthlySpent
+	}
+	return res
+}
+
+// About check if spent plus the amount exceeds a limit, a nil limit is no limit (minor units)
+func exceeds(limit *int64, spent int64, amount int64) bool {
+	return limit != nil && spent + amount > *limit
+}
+
+// About evaluate a transaction against the controls and the usage of a card, every failed control is a reason
+// The amount is compared with the limits as is (no currency conversion), the transaction is
+// international when its currency is not the currency of the controls
+func evaluateControls(control model.CardControl, transaction model.CardTransaction, now time.Time) model.ControlEvaluation {
+	usage := usageAt(control.Usage, now)
+	reasons := []string{}
+
+	if exceeds(control.TransactionLimit, 0, transaction.Amount) {
+		reasons = append(reasons, reasonTransactionLimit)
+	}
+	if exceeds(control.DailyLimit, usage.DailySpent, transaction.Amount) {
+		reasons = append(reasons, reasonDailyLimit)
+	}
+	if exceeds(control.MonthlyLimit, usage.MonthlySpent, transaction.Amount) {
+		reasons = append(reasons, reasonMonthlyLimit)
+	}
+	if slices.Contains(control.MccDeny, transaction.Mcc) {
+		reasons = append(reasons, reasonMccDenied)
+	} else if len(control.MccAllow) > 0 && !slices.Contains(control.MccAllow, transaction.Mcc) {
+		reasons = append(reasons, reasonMccNotAllowed)
+	}
+	if transaction.Channel == channelEcommerce && control.EcommerceEnabled != nil && !*control.EcommerceEnabled {
+		reasons = append(reasons, reasonEcommerceDisabled)
+	}
+	if transaction.Currency != control.Currency && control.InternationalEnabled != nil && !*control.InternationalEnabled {
+		reasons = append(reasons, reasonInternationalDisabled)
+	}
+	if transaction.Channel == channelATM {
+		if exceeds(control.AtmTransactionLimit, 0, transaction.Amount) {
+			reasons = append(reasons, reasonAtmTransactionLimit)
+		}
+		if exceeds(control.AtmDailyLimit, usage.AtmDailySpent, transaction.Amount) {
+			reasons = append(reasons, reasonAtmDailyLimit)
+		}
+	}
+
+	if len(reasons) > 0 {
+		return model.ControlEvaluation{Decision: decisionDecline, Reasons: reasons}
+	}
+	return model.ControlEvaluation{Decision: decisionApprove}
+}
+
+// About the controls of a card with the usage at now, the default controls when the card has none
+func (s *WorkerService) cardControl(ctx context.Context, cardID int, now time.Time) (*model.CardControl, error){
+	res_control, err := s.workerRepository.GetCardControl(ctx, cardID)
+	if errors.Is(err, erro.ErrNotFound) {
+		control := defaultCardControl(now)
+		return &control, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	res_control.Usage = usageAt(res_control.Usage, now)
+
+	return res_control, nil
+}
+
+// About get the spending controls of a card
+func (s *WorkerService) GetCardControl(ctx context.Context, card model.Card) (*model.CardControl, error){
+	childLogger.Info().Str("func","GetCardControl").Ctx(ctx).Interface("trace-resquest-id", ctx.Value("trace-request-id")).Interface("card", card).Send()
+
+	// trace
+	ctx, span := tracerProvider.SpanCtx(ctx, "service.GetCardControl")
+	defer span.End()
+
+	res_card, err := s.workerRepository.GetCard(ctx, card)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.cardControl(ctx, res_card.ID, time.Now())
+}
+
+// About replace the spending controls of a card, the usage counters are kept
+func (s *WorkerService) UpdateCardControl(ctx context.Context, card model.Card, control model.CardControl) (*model.CardControl, error){
+	childLogger.Info().Str("func","UpdateCardControl").Ctx(ctx).Interface("trace-resquest-id", ctx.Value("trace-request-id")).Interface("card", card).Interface("control", control).Send()
+
+	// trace
+	ctx, span := tracerProvider.SpanCtx(ctx, "service.UpdateCardControl")
+	defer span.End()
+
+	res_card, err := s.workerRepository.GetCard(ctx, card)
+	if err != nil {
+		return nil, err
+	}
+	if res_card.Status == cardStatusCanceled {
+		return nil, erro.ErrCardCanceled
+	}
+
+	var res *model.CardControl
+	err = s.workerRepository.WithTx(ctx, func(tx pgx.Tx) error {
+		res, err = s.workerRepository.UpsertCardControl(ctx, tx, res_card.ID, control)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	res.Usage = usageAt(res.Usage, time.Now())
+
+	return res, nil
+}
+
+// About evaluate a proposed transaction against the spending controls of a card, nothing is recorded
+// Only the controls are evaluated, the status of the card is checked by the authorization
+func (s *WorkerService) EvaluateCardControl(ctx context.Context, card model.Card, transaction model.CardTransaction) (*model.ControlEvaluation, error){
+	childLogger.Info().Str("func","EvaluateCardControl").Ctx(ctx).Interface("trace-resquest-id", ctx.Value("trace-request-id")).Interface("card", card).Interface("transaction", transaction).Send()
+
+	// trace
+	ctx, span := tracerProvider.SpanCtx(ctx, "service.EvaluateCardControl")
+	defer span.End()
+
+	res_card, err := s.workerRepository.GetCard(ctx, card)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	control, err := s.cardControl(ctx, res_card.ID, now)
+	if err != nil {
+		return nil, err
+	}
+
+	res := evaluateControls(*control, transaction, now)
+	if res.Decision == decisionDecline {
+		childLogger.Info().Ctx(ctx).Int("card_id", res_card.ID).Strs("reasons", res.Reasons).Msg("transaction declined by the controls")
+	}
+
+	return &res, nil
+}
@@ -0,0 +1,100 @@
+package service
+
+import(
+	"fmt"
+	"errors"
+	"context"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/go-card/internal/core/model"
+	"github.com/go-card/internal/core/erro"
+	"github.com/go-card/internal/core/hsm"
+	"github.com/go-card/internal/core/pan"
+)
+
+// the failed verifications before the card is locked for the cvv
+const cvvMaxFailures = 3
+
+// About compute the cvv2 of a card (PAN, expiry YYMM, service code 000) with the active CVK of the HSM
+// The cvv2 must never be stored or logged, a legacy card (PAN shorter than 13 digits) has no cvv2
+// Without HSM the card is still issued, without cvv2 (pending)
+func (s *WorkerService) cardCVV2(card model.Card) (string, error) {
+	cvv2, err := s.hsm.GenerateCVV(pan.Digits(card.CardNumber), card.ExpiredAt.Format("0601"), hsm.ServiceCodeCVV2)
+	if errors.Is(err, hsm.ErrInvalidCVVData) {
+		childLogger.Warn().Int("card_id", card.ID).Msg("card without cvv2, the pan is not eligible")
+		return "", nil
+	}
+	if errors.Is(err, erro.ErrHSMUnavailable) {
+		childLogger.Warn().Int("card_id", card.ID).Msg("card without cvv2, the hsm is unavailable (cvv2 pending)")
+		return "", nil
+	}
+	return cvv2, err
+}
+
+// About verify the cvv2 of a card, a failure increments the lockout counter and a success resets it
+func (s *WorkerService) VerifyCVV2(ctx context.Context, card model.Card, cvv2 string) (*model.CvvVerification, error){
+	childLogger.Info().Str("func","VerifyCVV2").Ctx(ctx).Interface("trace-resquest-id", ctx.Value("trace-request-id")).Interface("card", card).Send()
+
+	// trace
+	ctx, span := tracerProvider.SpanCtx(ctx, "service.VerifyCVV2")
+	defer span.End()
+
+	return s.verifyWithLockout(ctx, card, func(locked_card model.Card) (bool, error) {
+		verified, err := s.hsm.VerifyCVV(pan.Digits(locked_card.CardNumber), locked_card.ExpiredAt.Format("0601"), hsm.ServiceCodeCVV2, cvv2)
+		if errors.Is(err, hsm.ErrInvalidCVVData) {
+			return false, fmt.Errorf("%w: the card has no cvv2", erro.ErrBadRequest)
+		}
+		return verified, err
+	})
+}
+
+// About verify a security code of a card with the lockout counter (cvv_failures)
+// The card is locked (row lock) during the verification, so the concurrent attempts are counted
+func (s *WorkerService) verifyWithLockout(ctx context.Context, card model.Card, verify func(locked_card model.Card) (bool, error)) (*model.CvvVerification, error){
+	res_card, err := s.workerRepository.GetCard(ctx, card)
+	if err != nil {
+		return nil, err
+	}
+
+	res := model.CvvVerification{}
+	err = s.workerRepository.WithTx(ctx, func(tx pgx.Tx) error {
+		locked_card, err := s.workerRepository.GetCardForUpdate(ctx, tx, res_card.ID)
+		if err != nil {
+			return err
+		}
+		if locked_card.Status == cardStatusCanceled {
+			return erro.ErrCardCanceled
+		}
+		if locked_card.CvvFailures >= cvvMaxFailures {
+			return erro.ErrCardLocked
+		}
+
+		res.Verified, err = verify(*locked_card)
+		if err != nil {
+			return err
+		}
+
+		if res.Verified {
+			if locked_card.CvvFailures == 0 {
+				res.AttemptsLeft = cvvMaxFailures
+				return nil
+			}
+			locked_card.CvvFailures = 0
+		} else {
+			locked_card.CvvFailures = locked_card.CvvFailures + 1
+		}
+		res.AttemptsLeft = cvvMaxFailures - locked_card.CvvFailures
+
+		_, err = s.workerRepository.UpdateCardCvvFailures(ctx, tx, *locked_card)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !res.Verified {
+		childLogger.Warn().Ctx(ctx).Int("card_id", res_card.ID).Int("attempts_left", res.AttemptsLeft).Msg("security code verification failed")
+	}
+
+	return &res, nil
+}
@@ -0,0 +1,61 @@
+package service
+
+import (
+	"time"
+	"testing"
+	"encoding/hex"
+
+	"github.com/go-card/internal/core/hsm"
+	"github.com/go-card/internal/core/model"
+)
+
+// About a software HSM with a version 1 of every required key
+func newTestHSM(t *testing.T) *hsm.SoftwareHSM {
+	t.Helper()
+
+	keyStore := hsm.NewSoftwareKeyStore()
+	for name, algorithm := range hsm.RequiredKeys {
+		if _, err := keyStore.Rotate(name, algorithm, nil, time.Now()); err != nil {
+			t.Fatalf("rotate %s: %v", name, err)
+		}
+	}
+	storageKey, _ := hex.DecodeString("1F1E1D1C1B1A191817161514131211100F0E0D0C0B0A09080706050403020100")
+	softwareHSM, err := hsm.NewSoftwareHSM(keyStore, storageKey)
+	if err != nil {
+		t.Fatalf("new software hsm: %v", err)
+	}
+	return softwareHSM
+}
+
+func TestCardCVV2(t *testing.T) {
+	card := model.Card{ID: 1, CardNumber: "4123456789012345", ExpiredAt: time.Date(2030, 7, 31, 0, 0, 0, 0, time.UTC)}
+	legacy := card
+	legacy.CardNumber = "411111111111"
+
+	tests := []struct {
+		name	string
+		hsm		hsm.HSM
+		card	model.Card
+		cvv2	bool
+	}{
+		{"hsm available", newTestHSM(t), card, true},
+		{"pan not eligible", newTestHSM(t), legacy, false},
+		{"hsm unavailable", &hsm.UnavailableHSM{}, card, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s := &WorkerService{hsm: test.hsm}
+			cvv2, err := s.cardCVV2(test.card)
+			if err != nil {
+				t.Fatalf("card cvv2: %v", err)
+			}
+			if test.cvv2 && len(cvv2) != 3 {
+				t.Errorf("cvv2 %q, want 3 digits", cvv2)
+			}
+			if !test.cvv2 && cvv2 != "" {
+				t.Errorf("cvv2 %q, want none", cvv2)
+			}
+		})
+	}
+}
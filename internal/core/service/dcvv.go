@@ -0,0 +1,69 @@
+package service
+
+import(
+	"time"
+	"context"
+
+	"github.com/go-card/internal/core/model"
+	"github.com/go-card/internal/core/erro"
+	"github.com/go-card/internal/core/pan"
+)
+
+const (
+	cardModelVirtual	= "VIRTUAL"
+	dcvvWindow			= 5 * time.Minute
+)
+
+// About the time window (counter) of the dynamic cvv
+func dcvvCounter(now time.Time) uint64 {
+	return uint64(now.Unix() / int64(dcvvWindow / time.Second))
+}
+
+// About get the dynamic cvv of a virtual card for the current time window
+// The code is computed, never stored, it expires at the end of the window
+func (s *WorkerService) GetDCVV(ctx context.Context, card model.Card) (*model.DynamicCvv, error){
+	childLogger.Info().Str("func","GetDCVV").Ctx(ctx).Interface("trace-resquest-id", ctx.Value("trace-request-id")).Interface("card", card).Send()
+
+	// trace
+	ctx, span := tracerProvider.SpanCtx(ctx, "service.GetDCVV")
+	defer span.End()
+
+	res_card, err := s.workerRepository.GetCard(ctx, card)
+	if err != nil {
+		return nil, err
+	}
+	if res_card.Model != cardModelVirtual {
+		return nil, erro.ErrCardNotVirtual
+	}
+	if res_card.Status == cardStatusCanceled {
+		return nil, erro.ErrCardCanceled
+	}
+
+	counter := dcvvCounter(time.Now())
+	dcvv, err := s.hsm.GenerateDynamicCVV(pan.Digits(res_card.CardNumber), counter)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.DynamicCvv{
+		Dcvv:		dcvv,
+		ExpiresAt:	time.Unix(int64(counter + 1) * int64(dcvvWindow / time.Second), 0).UTC(),
+	}, nil
+}
+
+// About verify the dynamic cvv of a virtual card, the current and the previous windows are accepted
+// The failures share the lockout counter of the cvv2
+func (s *WorkerService) VerifyDCVV(ctx context.Context, card model.Card, dcvv string) (*model.CvvVerification, error){
+	childLogger.Info().Str("func","VerifyDCVV").Ctx(ctx).Interface("trace-resquest-id", ctx.Value("trace-request-id")).Interface("card", card).Send()
+
+	// trace
+	ctx, span := tracerProvider.SpanCtx(ctx, "service.VerifyDCVV")
+	defer span.End()
+
+	return s.verifyWithLockout(ctx, card, func(locked_card model.Card) (bool, error) {
+		if locked_card.Model != cardModelVirtual {
+			return false, erro.ErrCardNotVirtual
+		}
+		return s.hsm.VerifyDynamicCVV(pan.Digits(locked_card.CardNumber), dcvvCounter(time.Now()), dcvv)
+	})
+}
@@ -0,0 +1,102 @@
+package service
+
+import(
+	"time"
+	"context"
+	"math/rand"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/go-card/internal/core/model"
+)
+
+// About run the expiry job once: the cards past their expiry are EXPIRED, the cards expiring
+// in the next RenewalDays are emitted as renewal candidates
+// Each chunk is its own transaction and skips the rows locked by another pod, so the job can run on every pod
+func (s *WorkerService) RunExpiryJob(ctx context.Context, expiryJob model.ExpiryJob) (*model.ExpiryJobResult, error){
+	childLogger.Info().Str("func","RunExpiryJob").Ctx(ctx).Interface("trace-resquest-id", ctx.Value("trace-request-id")).Interface("expiryJob", expiryJob).Send()
+
+	// trace
+	ctx, span := tracerProvider.SpanCtx(ctx, "service.RunExpiryJob")
+	defer span.End()
+
+	result := model.ExpiryJobResult{}
+	now := time.Now()
+	until := now.AddDate(0, 0, expiryJob.RenewalDays)
+
+	// expire the cards
+	for {
+		var cards []model.Card
+		err := s.workerRepository.WithTx(ctx, func(tx pgx.Tx) error {
+			var err error
+			cards, err = s.workerRepository.ExpireCards(ctx, tx, now, expiryJob.ChunkSize)
+			return err
+		})
+		if err != nil {
+			return &result, err
+		}
+		for _, card := range cards {
+			s.metrics.recordCardExpired(ctx, card)
+		}
+		result.Expired = result.Expired + len(cards)
+		if len(cards) < expiryJob.ChunkSize {
+			break
+		}
+	}
+
+	// emit the renewal candidates
+	for {
+		var cards []model.Card
+		err := s.workerRepository.WithTx(ctx, func(tx pgx.Tx) error {
+			var err error
+			cards, err = s.workerRepository.MarkRenewalCandidates(ctx, tx, now, until, expiryJob.ChunkSize)
+			return err
+		})
+		if err != nil {
+			return &result, err
+		}
+		for _, card := range cards {
+			s.metrics.recordRenewalCandidate(ctx, card)
+			childLogger.Info().Str("event","renewal_candidate").
+								Int("card_id", card.ID).
+								Int("fk_account_id", card.FkAccountID).
+								Str("tenant_id", card.TenantID).
+								Time("expired_at", card.ExpiredAt).Send()
+		}
+		result.RenewalCandidates = result.RenewalCandidates + len(cards)
+		if len(cards) < expiryJob.ChunkSize {
+			break
+		}
+	}
+
+	childLogger.Info().Str("func","RunExpiryJob").Interface("result", result).Msg("expiry job done")
+
+	return &result, nil
+}
+
+// About run the expiry job every Interval until the context is canceled, a zero Interval disables it
+// The first run is jittered so the pods started together don't compete for the same chunks
+func (s *WorkerService) ScheduleExpiryJob(ctx context.Context, expiryJob model.ExpiryJob) {
+	childLogger.Info().Str("func","ScheduleExpiryJob").Interface("expiryJob", expiryJob).Send()
+
+	if expiryJob.Interval <= 0 {
+		childLogger.Info().Msg("expiry job scheduler disabled (EXPIRY_JOB_INTERVAL)")
+		return
+	}
+
+	timer := time.NewTimer(time.Duration(rand.Int63n(int64(expiryJob.Interval))))
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+			_, err := s.RunExpiryJob(ctx, expiryJob)
+			if err != nil {
+				childLogger.Error().Err(err).Msg("error expiry job")
+			}
+			timer.Reset(expiryJob.Interval)
+		}
+	}
+}
@@ -0,0 +1,162 @@
+package service
+
+import(
+	"fmt"
+	"time"
+	"context"
+	"strconv"
+	"strings"
+	"encoding/base64"
+
+	"github.com/go-card/internal/core/model"
+	"github.com/go-card/internal/core/erro"
+)
+
+const (
+	defaultHistoryDays		= 30 // from when the filter has no from
+	maxHistoryDays			= 92 // about 3 monthly partitions by query
+	defaultHistoryLimit		= 50
+	maxHistoryLimit			= 200
+
+	// the partitions of the current and next months
+	authorizationPartitionMonths	= 3
+	authorizationPartitionInterval	= 24 * time.Hour
+)
+
+// About the cursor of the next page, the position (created_at, id) of the last authorization of the page
+func encodeAuthorizationCursor(authorization model.Authorization) string {
+	position := strconv.FormatInt(authorization.CreatedAt.UnixNano(), 10) + "." + strconv.FormatInt(authorization.ID, 10)
+	return base64.RawURLEncoding.EncodeToString([]byte(position))
+}
+
+func decodeAuthorizationCursor(cursor string) (model.Authorization, error) {
+	invalid := &erro.ValidationError{Fields: []erro.FieldError{{Field: "cursor", Code: "invalid_format", Message: "is invalid"}}}
+
+	position, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return model.Authorization{}, invalid
+	}
+	createdAt, id, found := strings.Cut(string(position), ".")
+	if !found {
+		return model.Authorization{}, invalid
+	}
+	nanos, err := strconv.ParseInt(createdAt, 10, 64)
+	if err != nil {
+		return model.Authorization{}, invalid
+	}
+	res := model.Authorization{CreatedAt: time.Unix(0, nanos)}
+	res.ID, err = strconv.ParseInt(id, 10, 64)
+	if err != nil || res.ID <= 0 {
+		return model.Authorization{}, invalid
+	}
+	return res, nil
+}
+
+// About the filter with its defaults, to is now and from is 30 days before to, the range is at most 92 days
+func historyFilter(filter model.AuthorizationFilter, now time.Time) (model.AuthorizationFilter, error) {
+	if filter.To.IsZero() {
+		filter.To = now
+	}
+	if filter.From.IsZero() {
+		filter.From = filter.To.AddDate(0, 0, -defaultHistoryDays)
+	}
+	if filter.Limit == 0 {
+		filter.Limit = defaultHistoryLimit
+	}
+
+	var fields []erro.FieldError
+	if !filter.From.Before(filter.To) {
+		fields = append(fields, erro.FieldError{Field: "from", Code: "invalid_value", Message: "must be before to"})
+	} else if filter.To.Sub(filter.From) > maxHistoryDays * 24 * time.Hour {
+		fields = append(fields, erro.FieldError{Field: "from", Code: "invalid_value", Message: fmt.Sprintf("must be at most %d days before to", maxHistoryDays)})
+	}
+	if filter.Limit < 1 || filter.Limit > maxHistoryLimit {
+		fields = append(fields, erro.FieldError{Field: "limit", Code: "invalid_value", Message: fmt.Sprintf("must be between 1 and %d", maxHistoryLimit)})
+	}
+	if len(fields) > 0 {
+		return filter, &erro.ValidationError{Fields: fields}
+	}
+	return filter, nil
+}
+
+// About list the authorizations of a card from the newest, a page at a time
+// The next page is read with the same filter and the next_cursor of the page
+func (s *WorkerService) ListAuthorizations(ctx context.Context, card model.Card, filter model.AuthorizationFilter) (*model.AuthorizationPage, error){
+	childLogger.Info().Str("func","ListAuthorizations").Ctx(ctx).Interface("trace-resquest-id", ctx.Value("trace-request-id")).Interface("card", card).Interface("filter", filter).Send()
+
+	// trace
+	ctx, span := tracerProvider.SpanCtx(ctx, "service.ListAuthorizations")
+	defer span.End()
+
+	filter, err := historyFilter(filter, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	// the first page starts at to (excluded)
+	after := model.Authorization{CreatedAt: filter.To}
+	if filter.Cursor != "" {
+		after, err = decodeAuthorizationCursor(filter.Cursor)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	res_card, err := s.workerRepository.GetCard(ctx, card)
+	if err != nil {
+		return nil, err
+	}
+
+	// one more to know if there is a next page
+	res_list, err := s.workerRepository.ListAuthorizations(ctx, res_card.ID, filter.From, after, filter.Limit + 1)
+	if err != nil {
+		return nil, err
+	}
+
+	res := model.AuthorizationPage{Authorizations: res_list}
+	if len(res_list) > filter.Limit {
+		res.Authorizations = res_list[:filter.Limit]
+		res.NextCursor = encodeAuthorizationCursor(res.Authorizations[filter.Limit - 1])
+	}
+
+	return &res, nil
+}
+
+// About create the missing monthly partitions of the authorizations, the current and next months
+func (s *WorkerService) CreateAuthorizationPartitions(ctx context.Context) (int, error){
+	childLogger.Info().Str("func","CreateAuthorizationPartitions").Ctx(ctx).Send()
+
+	// trace
+	ctx, span := tracerProvider.SpanCtx(ctx, "service.CreateAuthorizationPartitions")
+	defer span.End()
+
+	created, err := s.workerRepository.CreateAuthorizationPartitions(ctx, time.Now(), authorizationPartitionMonths)
+	if err != nil {
+		return 0, err
+	}
+	if created > 0 {
+		childLogger.Info().Int("created", created).Msg("authorization partitions created")
+	}
+
+	return created, nil
+}
+
+// About create the authorization partitions at start and every day until the context is canceled
+// A failure is retried the next day, the rows of a month without partition go to the default partition
+func (s *WorkerService) ScheduleAuthorizationPartitions(ctx context.Context) {
+	childLogger.Info().Str("func","ScheduleAuthorizationPartitions").Send()
+
+	ticker := time.NewTicker(authorizationPartitionInterval)
+	defer ticker.Stop()
+
+	for {
+		_, err := s.CreateAuthorizationPartitions(ctx)
+		if err != nil {
+			childLogger.Error().Err(err).Msg("error create authorization partitions")
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
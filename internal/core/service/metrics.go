@@ -0,0 +1,170 @@
+package service
+
+import(
+	"time"
+	"errors"
+	"context"
+
+	"github.com/go-card/internal/core/model"
+	"github.com/go-card/internal/core/erro"
+	"github.com/go-card/internal/adapter/database"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+const (
+	outcomeFound 	= "found"
+	outcomeNotFound = "not_found"
+	outcomeError 	= "error"
+)
+
+// About the business metrics, exported on /metrics by the prometheus exporter
+type serviceMetrics struct {
+	cardIssued			metric.Int64Counter
+	atcIncrement		metric.Int64Counter
+	tokenCreated		metric.Int64Counter
+	cardExpired			metric.Int64Counter
+	renewalCandidate	metric.Int64Counter
+	authorization		metric.Int64Counter
+	lookup				metric.Int64Counter
+	downstreamLatency	metric.Float64Histogram
+}
+
+// About create the business metrics and the database pool gauges
+func newServiceMetrics(workerRepository *database.WorkerRepository) *serviceMetrics {
+	childLogger.Info().Str("func","newServiceMetrics").Send()
+
+	meter := otel.Meter("go-card")
+	serviceMetrics := &serviceMetrics{}
+	var errs []error
+	var err error
+
+	serviceMetrics.cardIssued, err = meter.Int64Counter("card_issued", metric.WithDescription("Cards issued"))
+	errs = append(errs, err)
+	serviceMetrics.atcIncrement, err = meter.Int64Counter("card_atc_increment", metric.WithDescription("Card ATC increments"))
+	errs = append(errs, err)
+	serviceMetrics.tokenCreated, err = meter.Int64Counter("card_token_created", metric.WithDescription("Card tokens created"))
+	errs = append(errs, err)
+	serviceMetrics.cardExpired, err = meter.Int64Counter("card_expired", metric.WithDescription("Cards expired by the expiry job"))
+	errs = append(errs, err)
+	serviceMetrics.renewalCandidate, err = meter.Int64Counter("card_renewal_candidate", metric.WithDescription("Cards flagged as renewal candidates by the expiry job"))
+	errs = append(errs, err)
+	serviceMetrics.authorization, err = meter.Int64Counter("card_authorization", metric.WithDescription("Card authorizations by response code"))
+	errs = append(errs, err)
+	serviceMetrics.lookup, err = meter.Int64Counter("card_lookup", metric.WithDescription("Card and token lookups by outcome (found, not_found, error)"))
+	errs = append(errs, err)
+	serviceMetrics.downstreamLatency, err = meter.Float64Histogram("downstream_request_duration",
+		metric.WithDescription("Latency of the downstream services calls (account service)"),
+		metric.WithUnit("s"))
+	errs = append(errs, err)
+
+	// pgx pool stats
+	acquiredConns, err := meter.Int64ObservableGauge("db_pool_acquired_conns", metric.WithDescription("Connections acquired from the pool"))
+	errs = append(errs, err)
+	idleConns, err := meter.Int64ObservableGauge("db_pool_idle_conns", metric.WithDescription("Idle connections in the pool"))
+	errs = append(errs, err)
+	totalConns, err := meter.Int64ObservableGauge("db_pool_total_conns", metric.WithDescription("Total connections in the pool"))
+	errs = append(errs, err)
+	maxConns, err := meter.Int64ObservableGauge("db_pool_max_conns", metric.WithDescription("Max connections of the pool"))
+	errs = append(errs, err)
+	acquireCount, err := meter.Int64ObservableCounter("db_pool_acquire", metric.WithDescription("Connections acquired from the pool (cumulative)"))
+	errs = append(errs, err)
+	emptyAcquireCount, err := meter.Int64ObservableCounter("db_pool_empty_acquire", metric.WithDescription("Acquires that waited for a connection (cumulative)"))
+	errs = append(errs, err)
+	canceledAcquireCount, err := meter.Int64ObservableCounter("db_pool_canceled_acquire", metric.WithDescription("Acquires canceled by the context (cumulative)"))
+	errs = append(errs, err)
+
+	_, err = meter.RegisterCallback(func(ctx context.Context, observer metric.Observer) error {
+		stats := workerRepository.Stat(ctx)
+		observer.ObserveInt64(acquiredConns, int64(stats.AcquiredConns))
+		observer.ObserveInt64(idleConns, int64(stats.IdleConns))
+		observer.ObserveInt64(totalConns, int64(stats.TotalConns))
+		observer.ObserveInt64(maxConns, int64(stats.MaxConns))
+		observer.ObserveInt64(acquireCount, stats.AcquireCount)
+		observer.ObserveInt64(emptyAcquireCount, stats.EmptyAcquireCount)
+		observer.ObserveInt64(canceledAcquireCount, stats.CanceledAcquireCount)
+		return nil
+	}, acquiredConns, idleConns, totalConns, maxConns, acquireCount, emptyAcquireCount, canceledAcquireCount)
+	errs = append(errs, err)
+
+	if err := errors.Join(errs...); err != nil {
+		childLogger.Error().Err(err).Msg("error register business metrics")
+	}
+
+	return serviceMetrics
+}
+
+// About the labels of a card
+func cardAttributes(card model.Card) metric.MeasurementOption {
+	return metric.WithAttributes(
+		attribute.String("tenant", card.TenantID),
+		attribute.String("card_type", card.Type),
+		attribute.String("status", card.Status),
+	)
+}
+
+// About the outcome of a lookup
+func lookupOutcome(err error) string {
+	switch {
+	case err == nil:
+		return outcomeFound
+	case errors.Is(err, erro.ErrNotFound):
+		return outcomeNotFound
+	default:
+		return outcomeError
+	}
+}
+
+func (m *serviceMetrics) recordCardIssued(ctx context.Context, card model.Card) {
+	m.cardIssued.Add(ctx, 1, cardAttributes(card))
+}
+
+func (m *serviceMetrics) recordAtcIncrement(ctx context.Context, card model.Card) {
+	m.atcIncrement.Add(ctx, 1, cardAttributes(card))
+}
+
+func (m *serviceMetrics) recordTokenCreated(ctx context.Context, card model.Card) {
+	m.tokenCreated.Add(ctx, 1, cardAttributes(card))
+}
+
+func (m *serviceMetrics) recordCardExpired(ctx context.Context, card model.Card) {
+	m.cardExpired.Add(ctx, 1, cardAttributes(card))
+}
+
+func (m *serviceMetrics) recordRenewalCandidate(ctx context.Context, card model.Card) {
+	m.renewalCandidate.Add(ctx, 1, cardAttributes(card))
+}
+
+func (m *serviceMetrics) recordAuthorization(ctx context.Context, card model.Card, authorization model.Authorization) {
+	m.authorization.Add(ctx, 1, metric.WithAttributes(
+		attribute.String("tenant", card.TenantID),
+		attribute.String("card_type", card.Type),
+		attribute.String("channel", authorization.Channel),
+		attribute.String("response_code", authorization.ResponseCode),
+	))
+}
+
+// the card type and status are only known when the card is found, they are empty otherwise
+func (m *serviceMetrics) recordLookup(ctx context.Context, operation string, card model.Card, err error) {
+	if err != nil {
+		card = model.Card{TenantID: card.TenantID}
+	}
+	m.lookup.Add(ctx, 1, metric.WithAttributes(
+		attribute.String("operation", operation),
+		attribute.String("tenant", card.TenantID),
+		attribute.String("card_type", card.Type),
+		attribute.String("status", card.Status),
+		attribute.String("outcome", lookupOutcome(err)),
+	))
+}
+
+// the tenant is the one of the baggage, set before the downstream calls
+func (m *serviceMetrics) recordDownstreamLatency(ctx context.Context, service string, statusCode int, start time.Time) {
+	m.downstreamLatency.Record(ctx, time.Since(start).Seconds(), metric.WithAttributes(
+		attribute.String("service", service),
+		attribute.String("tenant", tenantFromBaggage(ctx)),
+		attribute.Int("status_code", statusCode),
+	))
+}
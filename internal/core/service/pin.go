@@ -0,0 +1,210 @@
+package service
+
+import(
+	"fmt"
+	"errors"
+	"context"
+	"encoding/hex"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/go-card/internal/core/model"
+	"github.com/go-card/internal/core/erro"
+	"github.com/go-card/internal/core/hsm"
+	"github.com/go-card/internal/core/pan"
+)
+
+const (
+	cardStatusBlocked	= "BLOCKED"
+	pinMaxFailures		= 3 // wrong pins before the card is blocked
+)
+
+// About convert the pin block errors of the HSM into bad requests
+func pinError(err error) error {
+	if errors.Is(err, hsm.ErrInvalidPinBlock) || errors.Is(err, hsm.ErrInvalidPin) {
+		return fmt.Errorf("%w: %s", erro.ErrBadRequest, err.Error())
+	}
+	return err
+}
+
+// About lock a card for a pin operation, the canceled and blocked cards are rejected
+func (s *WorkerService) lockCardForPin(ctx context.Context, tx pgx.Tx, id int) (*model.Card, error){
+	locked_card, err := s.workerRepository.GetCardForUpdate(ctx, tx, id)
+	if err != nil {
+		return nil, err
+	}
+	if locked_card.Status == cardStatusCanceled {
+		return nil, erro.ErrCardCanceled
+	}
+	if locked_card.Status == cardStatusBlocked {
+		return nil, erro.ErrCardBlocked
+	}
+	return locked_card, nil
+}
+
+// About verify the pin of a locked card, the wrong pins counter is updated and the card
+// is blocked after pinMaxFailures wrong pins, a right pin resets the counter
+func (s *WorkerService) verifyPinLocked(ctx context.Context, tx pgx.Tx, locked_card *model.Card, pinBlock []byte, format int) (*model.PinVerification, error){
+	if locked_card.PinPvv == "" {
+		return nil, erro.ErrPinNotSet
+	}
+
+	verified, err := s.hsm.VerifyPIN(pinBlock, format, pan.Digits(locked_card.CardNumber), locked_card.PinPvki, locked_card.PinPvv)
+	if err != nil {
+		return nil, pinError(err)
+	}
+
+	res := model.PinVerification{Verified: verified}
+	if verified {
+		res.AttemptsLeft = pinMaxFailures
+		if locked_card.PinFailures == 0 {
+			return &res, nil
+		}
+		locked_card.PinFailures = 0
+	} else {
+		locked_card.PinFailures = locked_card.PinFailures + 1
+		res.AttemptsLeft = pinMaxFailures - locked_card.PinFailures
+		if locked_card.PinFailures >= pinMaxFailures {
+			locked_card.Status = cardStatusBlocked
+			childLogger.Warn().Ctx(ctx).Int("card_id", locked_card.ID).Msg("card blocked, too many wrong pins")
+		}
+	}
+
+	_, err = s.workerRepository.UpdateCardPin(ctx, tx, *locked_card)
+	if err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// About set the first pin of a card, only the PVV is stored
+func (s *WorkerService) SetPIN(ctx context.Context, card model.Card, cardPin model.CardPin) (*model.MessageRouter, error){
+	childLogger.Info().Str("func","SetPIN").Ctx(ctx).Interface("trace-resquest-id", ctx.Value("trace-request-id")).Interface("card", card).Send()
+
+	// trace
+	ctx, span := tracerProvider.SpanCtx(ctx, "service.SetPIN")
+	defer span.End()
+
+	pinBlock, err := hex.DecodeString(cardPin.PinBlock)
+	if err != nil {
+		return nil, erro.ErrBadRequest
+	}
+
+	res_card, err := s.workerRepository.GetCard(ctx, card)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.workerRepository.WithTx(ctx, func(tx pgx.Tx) error {
+		locked_card, err := s.lockCardForPin(ctx, tx, res_card.ID)
+		if err != nil {
+			return err
+		}
+		if locked_card.PinPvv != "" {
+			return erro.ErrPinAlreadySet
+		}
+
+		// the PVKI is the last digit of the version of the active PVK
+		locked_card.PinPvv, locked_card.PinPvki, err = s.hsm.GeneratePVV(pinBlock, cardPin.Format, pan.Digits(locked_card.CardNumber))
+		if err != nil {
+			return pinError(err)
+		}
+		locked_card.PinFailures = 0
+
+		_, err = s.workerRepository.UpdateCardPin(ctx, tx, *locked_card)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.MessageRouter{Message: "pin set"}, nil
+}
+
+// About change the pin of a card, the current pin must be verified (a wrong pin counts as a failure)
+func (s *WorkerService) ChangePIN(ctx context.Context, card model.Card, cardPinChange model.CardPinChange) (*model.MessageRouter, error){
+	childLogger.Info().Str("func","ChangePIN").Ctx(ctx).Interface("trace-resquest-id", ctx.Value("trace-request-id")).Interface("card", card).Send()
+
+	// trace
+	ctx, span := tracerProvider.SpanCtx(ctx, "service.ChangePIN")
+	defer span.End()
+
+	pinBlock, err := hex.DecodeString(cardPinChange.PinBlock)
+	if err != nil {
+		return nil, erro.ErrBadRequest
+	}
+	newPinBlock, err := hex.DecodeString(cardPinChange.NewPinBlock)
+	if err != nil {
+		return nil, erro.ErrBadRequest
+	}
+
+	res_card, err := s.workerRepository.GetCard(ctx, card)
+	if err != nil {
+		return nil, err
+	}
+
+	// the failure must be committed, so the wrong pin is returned after the transaction
+	var verification *model.PinVerification
+	err = s.workerRepository.WithTx(ctx, func(tx pgx.Tx) error {
+		locked_card, err := s.lockCardForPin(ctx, tx, res_card.ID)
+		if err != nil {
+			return err
+		}
+		verification, err = s.verifyPinLocked(ctx, tx, locked_card, pinBlock, cardPinChange.Format)
+		if err != nil || !verification.Verified {
+			return err
+		}
+
+		// the PVKI is the last digit of the version of the active PVK
+		locked_card.PinPvv, locked_card.PinPvki, err = s.hsm.GeneratePVV(newPinBlock, cardPinChange.Format, pan.Digits(locked_card.CardNumber))
+		if err != nil {
+			return pinError(err)
+		}
+		locked_card.PinFailures = 0
+
+		_, err = s.workerRepository.UpdateCardPin(ctx, tx, *locked_card)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !verification.Verified {
+		return nil, fmt.Errorf("%w: %d attempts left", erro.ErrPinInvalid, verification.AttemptsLeft)
+	}
+
+	return &model.MessageRouter{Message: "pin changed"}, nil
+}
+
+// About verify the pin of a card
+func (s *WorkerService) VerifyPIN(ctx context.Context, card model.Card, cardPin model.CardPin) (*model.PinVerification, error){
+	childLogger.Info().Str("func","VerifyPIN").Ctx(ctx).Interface("trace-resquest-id", ctx.Value("trace-request-id")).Interface("card", card).Send()
+
+	// trace
+	ctx, span := tracerProvider.SpanCtx(ctx, "service.VerifyPIN")
+	defer span.End()
+
+	pinBlock, err := hex.DecodeString(cardPin.PinBlock)
+	if err != nil {
+		return nil, erro.ErrBadRequest
+	}
+
+	res_card, err := s.workerRepository.GetCard(ctx, card)
+	if err != nil {
+		return nil, err
+	}
+
+	var res *model.PinVerification
+	err = s.workerRepository.WithTx(ctx, func(tx pgx.Tx) error {
+		locked_card, err := s.lockCardForPin(ctx, tx, res_card.ID)
+		if err != nil {
+			return err
+		}
+		res, err = s.verifyPinLocked(ctx, tx, locked_card, pinBlock, cardPin.Format)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
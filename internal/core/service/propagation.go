@@ -0,0 +1,85 @@
+package service
+
+import(
+	"context"
+	"net/http"
+
+	"github.com/go-card/internal/core/model"
+
+	go_core_api "github.com/eliezerraj/go-core/api"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/baggage"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const tenantBaggageKey = "tenant_id"
+
+// About add the tenant to the baggage, a tenant already set by the caller is kept
+func withTenantBaggage(ctx context.Context, tenant string) context.Context {
+	if tenant == "" {
+		return ctx
+	}
+	if tenantFromBaggage(ctx) != "" {
+		return ctx
+	}
+	bag := baggage.FromContext(ctx)
+	member, err := baggage.NewMemberRaw(tenantBaggageKey, tenant)
+	if err != nil {
+		childLogger.Warn().Err(err).Str("tenant", tenant).Msg("invalid tenant baggage")
+		return ctx
+	}
+	bag, err = bag.SetMember(member)
+	if err != nil {
+		childLogger.Warn().Err(err).Str("tenant", tenant).Msg("invalid tenant baggage")
+		return ctx
+	}
+	return baggage.ContextWithBaggage(ctx, bag)
+}
+
+// About the tenant of the baggage, empty when there is none
+func tenantFromBaggage(ctx context.Context) string {
+	return baggage.FromContext(ctx).Member(tenantBaggageKey).Value()
+}
+
+// About do one http call to a downstream service inside a client span
+// traceparent, tracestate and baggage are injected in the request headers
+func (s *WorkerService) doRequest(	ctx context.Context,
+									downstream model.ApiService,
+									httpClient go_core_api.HttpClient) (interface{}, int, error){
+	ctx, span := otel.Tracer("go-card").Start(ctx,
+											"HTTP " + httpClient.Method,
+											trace.WithSpanKind(trace.SpanKindClient),
+											trace.WithAttributes(
+												attribute.String("peer.service", downstream.Name),
+												attribute.String("http.request.method", httpClient.Method),
+												attribute.String("url.full", httpClient.Url),
+												attribute.String("server.address", downstream.HostName),
+											))
+	defer span.End()
+
+	// copy the headers, the caller map is not changed
+	headers := map[string]string{}
+	if httpClient.Headers != nil {
+		for key, value := range *httpClient.Headers {
+			headers[key] = value
+		}
+	}
+	otel.GetTextMapPropagator().Inject(ctx, propagation.MapCarrier(headers))
+	httpClient.Headers = &headers
+
+	res_payload, statusCode, err := apiService.CallRestApiV1(ctx,
+															s.goCoreRestApiService.Client,
+															httpClient,
+															nil)
+	span.SetAttributes(attribute.Int("http.response.status_code", statusCode))
+	if err != nil && (statusCode == 0 || statusCode >= http.StatusInternalServerError) {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+
+	return res_payload, statusCode, err
+}
@@ -0,0 +1,186 @@
+package service
+
+import(
+	"fmt"
+	"sync"
+	"time"
+	"context"
+
+	"github.com/go-card/internal/core/model"
+	"github.com/go-card/internal/core/erro"
+	"github.com/go-card/internal/core/hsm"
+
+	go_core_api "github.com/eliezerraj/go-core/api"
+)
+
+const (
+	readinessCacheTTL		= 5 * time.Second
+	readinessCheckTimeout	= 2 * time.Second
+	poolSaturationLimit		= 0.9
+	statusUp				= "UP"
+	statusDown				= "DOWN"
+	statusDegraded			= "DEGRADED"
+)
+
+// About check the readiness of all dependencies
+// The report is cached for readinessCacheTTL and each check is bounded by readinessCheckTimeout
+func (s *WorkerService) Readiness(ctx context.Context) model.Readiness {
+	childLogger.Info().Str("func","Readiness").Ctx(ctx).Interface("trace-resquest-id", ctx.Value("trace-request-id")).Send()
+
+	s.readinessMutex.Lock()
+	defer s.readinessMutex.Unlock()
+
+	if s.readiness != nil && time.Since(s.readiness.CheckedAt) < readinessCacheTTL {
+		return *s.readiness
+	}
+
+	// the report is shared by all callers, so a caller canceling its request must not fail the checks
+	ctx = context.WithoutCancel(ctx)
+
+	// trace
+	ctx, span := tracerProvider.SpanCtx(ctx, "service.Readiness")
+	defer span.End()
+
+	s.mutex.RLock()
+	downstreams := make([]model.ApiService, 0, len(s.apiService))
+	for _, downstream := range s.apiService {
+		downstreams = append(downstreams, downstream)
+	}
+	s.mutex.RUnlock()
+
+	dependencies := make([]model.DependencyStatus, 3 + len(downstreams))
+
+	var wg sync.WaitGroup
+	wg.Add(len(dependencies))
+	go func() {
+		defer wg.Done()
+		dependencies[0] = s.checkDatabase(ctx)
+	}()
+	go func() {
+		defer wg.Done()
+		dependencies[1] = s.checkPool(ctx)
+	}()
+	go func() {
+		defer wg.Done()
+		dependencies[2] = s.checkHSM()
+	}()
+	for i, downstream := range downstreams {
+		go func(i int, downstream model.ApiService) {
+			defer wg.Done()
+			dependencies[3 + i] = s.checkApiService(ctx, downstream)
+		}(i, downstream)
+	}
+	wg.Wait()
+
+	readiness := model.Readiness{
+		Status: 		statusUp,
+		CheckedAt: 		time.Now(),
+		Dependencies: 	dependencies,
+	}
+	for _, dependency := range dependencies {
+		if dependency.Critical && dependency.Status == statusDown {
+			readiness.Status = statusDown
+			break
+		}
+		if dependency.Status != statusUp {
+			readiness.Status = statusDegraded
+		}
+	}
+
+	s.readiness = &readiness
+
+	return readiness
+}
+
+// About check the database connectivity
+func (s *WorkerService) checkDatabase(ctx context.Context) model.DependencyStatus {
+	dependency := model.DependencyStatus{Name: "database", Critical: true, Status: statusUp}
+	start := time.Now()
+
+	ctx, cancel := context.WithTimeout(ctx, readinessCheckTimeout)
+	defer cancel()
+
+	done := make(chan error, 1)
+	go func() {
+		done <- s.workerRepository.DatabasePGServer.Ping()
+	}()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			dependency.Status = statusDown
+			dependency.Error = err.Error()
+		}
+	case <-ctx.Done():
+		dependency.Status = statusDown
+		dependency.Error = ctx.Err().Error()
+	}
+	dependency.LatencyMs = time.Since(start).Milliseconds()
+
+	return dependency
+}
+
+// About check the saturation of the database pool
+func (s *WorkerService) checkPool(ctx context.Context) model.DependencyStatus {
+	dependency := model.DependencyStatus{Name: "database_pool", Critical: false, Status: statusUp}
+
+	stats := s.workerRepository.Stat(ctx)
+	dependency.Details = stats
+
+	if stats.MaxConns > 0 && float64(stats.AcquiredConns) / float64(stats.MaxConns) >= poolSaturationLimit {
+		dependency.Status = statusDegraded
+		dependency.Error = fmt.Sprintf("pool saturated %d/%d connections acquired", stats.AcquiredConns, stats.MaxConns)
+	}
+
+	return dependency
+}
+
+// About check the key store of the HSM, a pod without keys stays ready (DEGRADED), only the endpoints that need the keys fail
+func (s *WorkerService) checkHSM() model.DependencyStatus {
+	dependency := model.DependencyStatus{Name: "hsm", Critical: false, Status: statusUp}
+
+	if unavailable, ok := s.hsm.(*hsm.UnavailableHSM); ok {
+		dependency.Status = statusDegraded
+		dependency.Error = fmt.Sprintf("%v: %v", erro.ErrHSMUnavailable, unavailable.Reason)
+	}
+
+	return dependency
+}
+
+// About check the health of a downstream service
+// A downstream is not critical, only its endpoints fail when it is down, so the pod stays ready (DEGRADED)
+func (s *WorkerService) checkApiService(ctx context.Context, downstream model.ApiService) model.DependencyStatus {
+	dependency := model.DependencyStatus{Name: downstream.Name, Critical: false, Status: statusUp}
+	dependency.Details = map[string]string{"circuit_breaker": s.circuitBreakerState(downstream)}
+	start := time.Now()
+
+	timeout := readinessCheckTimeout
+	if downstream.HttpTimeout > 0 && downstream.HttpTimeout < timeout {
+		timeout = downstream.HttpTimeout
+	}
+
+	headers := map[string]string{
+		"Content-Type":  "application/json;charset=UTF-8",
+		"X-Request-Id": fmt.Sprintf("%v",ctx.Value("trace-request-id")),
+		"x-apigw-api-id": downstream.XApigwApiId,
+		"Host": downstream.HostName,
+	}
+	httpClient := go_core_api.HttpClient {
+		Url: 	downstream.Url + "/health",
+		Method: "GET",
+		Timeout: timeout,
+		Headers: &headers,
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	_, statusCode, err := s.doRequest(ctx, downstream, httpClient)
+	if err != nil {
+		dependency.Status = statusDown
+		dependency.Error = errorStatusCode(statusCode, downstream.Name, err).Error()
+	}
+	dependency.LatencyMs = time.Since(start).Milliseconds()
+
+	return dependency
+}
@@ -0,0 +1,130 @@
+package service
+
+import(
+	"context"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/go-card/internal/core/model"
+	"github.com/go-card/internal/core/erro"
+	"github.com/go-card/internal/core/pan"
+)
+
+const (
+	cardStatusIssued	= "ISSUED"
+	cardStatusCanceled	= "CANCELED"
+	tokenStatusCanceled	= "CANCELED"
+
+	reissueLost			= "LOST"
+	reissueStolen		= "STOLEN"
+	reissueDamaged		= "DAMAGED"
+	reissueRenewal		= "RENEWAL"
+)
+
+// About the reissue policy of a reason
+// A lost or stolen card gets a new PAN, a damaged or expiring card keeps its PAN with a new expiry
+// The active tokens follow the new card, except for a stolen card (they are canceled), the controls always follow it
+type reissuePolicy struct {
+	newPan			bool
+	migrateTokens	bool
+}
+
+var reissuePolicies = map[string]reissuePolicy{
+	reissueLost:	{newPan: true, migrateTokens: true},
+	reissueStolen:	{newPan: true, migrateTokens: false},
+	reissueDamaged:	{newPan: false, migrateTokens: true},
+	reissueRenewal:	{newPan: false, migrateTokens: true},
+}
+
+// About replace a card, the new card is linked to its predecessor and the predecessor is canceled
+// Everything runs in one transaction, the predecessor is locked to avoid a concurrent reissue
+func (s *WorkerService) ReissueCard(ctx context.Context, card model.Card, reason string) (*model.Card, error){
+	childLogger.Info().Str("func","ReissueCard").Ctx(ctx).Interface("trace-resquest-id", ctx.Value("trace-request-id")).Interface("card", card).Str("reason", reason).Send()
+
+	// trace
+	ctx, span := tracerProvider.SpanCtx(ctx, "service.ReissueCard")
+	defer span.End()
+
+	policy, ok := reissuePolicies[reason]
+	if !ok {
+		return nil, erro.ErrBadRequest
+	}
+
+	res_card, err := s.workerRepository.GetCard(ctx, card)
+	if err != nil {
+		return nil, err
+	}
+
+	// prepare the new card, the PAN is generated before the transaction
+	new_card := model.Card{
+		FkAccountID:	res_card.FkAccountID,
+		CardNumber:		res_card.CardNumber,
+		Holder:			res_card.Holder,
+		Type:			res_card.Type,
+		Model:			res_card.Model,
+		Status:			cardStatusIssued,
+		TenantID:		res_card.TenantID,
+		PredecessorID:	res_card.ID,
+		ReissueReason:	reason,
+	}
+	if policy.newPan {
+		new_card.CardNumber, err = pan.Generate(res_card.CardNumber)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var res *model.Card
+	err = s.workerRepository.WithTx(ctx, func(tx pgx.Tx) error {
+		predecessor, err := s.workerRepository.GetCardForUpdate(ctx, tx, res_card.ID)
+		if err != nil {
+			return err
+		}
+		if predecessor.Status == cardStatusCanceled {
+			return erro.ErrCardCanceled
+		}
+
+		predecessor.Status = cardStatusCanceled
+		_, err = s.workerRepository.UpdateCardStatus(ctx, tx, *predecessor)
+		if err != nil {
+			return err
+		}
+
+		res, err = s.workerRepository.AddCard(ctx, tx, new_card)
+		if err != nil {
+			return err
+		}
+		res.Cvv2, err = s.cardCVV2(*res)
+		if err != nil {
+			return err
+		}
+
+		// the spending controls (and the usage of the day) follow the new card
+		_, err = s.workerRepository.CopyCardControl(ctx, tx, predecessor.ID, res.ID)
+		if err != nil {
+			return err
+		}
+
+		if policy.migrateTokens {
+			_, err = s.workerRepository.MigrateCardToken(ctx, tx, predecessor.ID, res.ID)
+		} else {
+			_, err = s.workerRepository.UpdateCardTokenStatus(ctx, tx, predecessor.ID, tokenStatusCanceled)
+		}
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.metrics.recordCardIssued(ctx, *res)
+
+	// get account_id from id (PK), the reissue is committed so a failure is only logged
+	ctx = withTenantBaggage(ctx, res.TenantID)
+	account, err := s.getAccountByID(ctx, res.FkAccountID)
+	if err != nil {
+		childLogger.Warn().Err(err).Ctx(ctx).Int("fk_account_id", res.FkAccountID).Msg("reissued card without account_id")
+	} else {
+		res.AccountID = account.AccountID
+	}
+
+	return res, nil
+}
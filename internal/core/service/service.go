@@ -3,46 +3,107 @@ package service
 import(
 	"fmt"
 	"time"
+	"sync"
 	"context"
 	"errors"
 	"net/http"	
-	"encoding/json"
+	"encoding/hex"
 	"github.com/zeebo/blake3"
+	"github.com/jackc/pgx/v5"
 
 	"github.com/rs/zerolog/log"
 
 	"github.com/go-card/internal/core/model"
 	"github.com/go-card/internal/core/erro"
+	"github.com/go-card/internal/core/hsm"
 	"github.com/go-card/internal/adapter/database"
+	"github.com/go-card/internal/infra/circuitbreaker"
+	"github.com/go-card/internal/infra/cache"
+	"github.com/go-card/internal/infra/logger"
 
 	go_core_pg "github.com/eliezerraj/go-core/database/pg"
 	go_core_observ "github.com/eliezerraj/go-core/observability"
 	go_core_api "github.com/eliezerraj/go-core/api"
 )
 
+const accountServiceName = "go-account"
+
 var (
 	tracerProvider go_core_observ.TracerProvider
-	childLogger = log.With().Str("component","go-card").Str("package","internal.core.service").Logger()
+	childLogger = logger.With().Str("component","go-card").Str("package","internal.core.service").Logger()
 	apiService go_core_api.ApiService
 )
 
 type WorkerService struct {
 	goCoreRestApiService	go_core_api.ApiService
 	workerRepository 		*database.WorkerRepository
-	apiService				[]model.ApiService
+	apiService				map[string]model.ApiService
+	featureFlags			map[string]bool
+	mutex					sync.RWMutex
+	readiness				*model.Readiness
+	readinessMutex			sync.Mutex
+	circuitBreakers			*circuitbreaker.Registry
+	accountCache			*cache.LRU[model.Account]
+	metrics					*serviceMetrics
+	hsm						hsm.HSM
 }
 
 // About create a new worker service
 func NewWorkerService(	goCoreRestApiService	go_core_api.ApiService,	
 						workerRepository 		*database.WorkerRepository,
-						apiService				[]model.ApiService,) *WorkerService{
+						apiService				map[string]model.ApiService,
+						accountCache			model.CacheConfig,
+						hsm						hsm.HSM) *WorkerService{
 	childLogger.Info().Str("func","NewWorkerService").Send()
 
 	return &WorkerService{
 		goCoreRestApiService: 	goCoreRestApiService,
 		apiService: 			apiService,
 		workerRepository: 		workerRepository,
+		circuitBreakers:		circuitbreaker.NewRegistry(),
+		accountCache:			cache.NewLRU[model.Account]("account", accountCache.Size, accountCache.TTL),
+		metrics:				newServiceMetrics(workerRepository),
+		hsm:					hsm,
+	}
+}
+
+// About replace the downstream services endpoints (config reload)
+func (s *WorkerService) SetApiService(apiService map[string]model.ApiService) {
+	childLogger.Info().Str("func","SetApiService").Send()
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.apiService = apiService
+	s.circuitBreakers.SetConfig(apiService)
+}
+
+// About get a snapshot of a downstream service endpoint
+func (s *WorkerService) getApiService(name string) (model.ApiService, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	apiService, ok := s.apiService[name]
+	if !ok {
+		childLogger.Error().Str("func","getApiService").Str("name", name).Msg("downstream service not configured")
+		return model.ApiService{}, erro.ErrServiceNotConfigured
 	}
+	return apiService, nil
+}
+
+// About replace the feature flags (config reload)
+func (s *WorkerService) SetFeatureFlags(featureFlags map[string]bool) {
+	childLogger.Info().Str("func","SetFeatureFlags").Interface("featureFlags", featureFlags).Send()
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.featureFlags = featureFlags
+}
+
+// About check if a feature flag is enabled
+func (s *WorkerService) IsFeatureEnabled(name string) bool {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return s.featureFlags[name]
 }
 
 // About handle/convert http status code
@@ -57,247 +118,271 @@ func errorStatusCode(statusCode int, serviceName string, msg_err error) error{
 			err = erro.ErrHTTPForbiden
 		case http.StatusNotFound:
 			err = erro.ErrNotFound
+		case http.StatusServiceUnavailable:
+			if errors.Is(msg_err, erro.ErrCircuitOpen) {
+				err = erro.ErrCircuitOpen
+			} else {
+				err = fmt.Errorf("%w: service %s in outage => cause error: %s", erro.ErrDownstream, serviceName, msg_err.Error())
+			}
 		default:
-			err = errors.New(fmt.Sprintf("service %s in outage => cause error: %s", serviceName, msg_err.Error() ))
+			err = fmt.Errorf("%w: service %s in outage => cause error: %s", erro.ErrDownstream, serviceName, msg_err.Error())
 		}
 	return err
 }
 
+// About call a downstream service through its circuit breaker
+// Only the idempotent GET calls are retried, with a jittered exponential backoff
+func (s *WorkerService) callApiService(	ctx context.Context,
+										downstream model.ApiService,
+										httpClient go_core_api.HttpClient) (interface{}, int, error){
+	childLogger.Info().Str("func","callApiService").Ctx(ctx).Interface("trace-resquest-id", ctx.Value("trace-request-id")).Str("service", downstream.Name).Send()
+
+	breaker := s.circuitBreakers.Get(downstream)
+
+	maxAttempts := 1
+	if httpClient.Method == http.MethodGet {
+		maxAttempts = downstream.Retry.MaxAttempts
+	}
+
+	var res_payload interface{}
+	var statusCode int
+	var err error
+
+	for attempt := 0; attempt < maxAttempts; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return nil, statusCode, ctx.Err()
+			case <-time.After(circuitbreaker.Backoff(attempt - 1, downstream.Retry)):
+			}
+		}
+
+		if err := breaker.Allow(); err != nil {
+			return nil, http.StatusServiceUnavailable, err
+		}
+
+		start := time.Now()
+		res_payload, statusCode, err = s.doRequest(ctx, downstream, httpClient)
+		s.metrics.recordDownstreamLatency(ctx, downstream.Name, statusCode, start)
+		if err == nil {
+			breaker.Success()
+			return res_payload, statusCode, nil
+		}
+
+		// a 4xx is an answer of the service, not an outage
+		if statusCode >= http.StatusBadRequest && statusCode < http.StatusInternalServerError {
+			breaker.Success()
+			return nil, statusCode, err
+		}
+
+		breaker.Failure()
+		childLogger.Warn().Err(err).Str("service", downstream.Name).Int("attempt", attempt + 1).Int("statusCode", statusCode).Msg("downstream call failed")
+	}
+
+	return nil, statusCode, err
+}
+
+// About the circuit breaker state of a downstream service
+func (s *WorkerService) circuitBreakerState(downstream model.ApiService) string {
+	return s.circuitBreakers.Get(downstream).State().String()
+}
+
 // About handle/convert http status code
 func (s *WorkerService) Stat(ctx context.Context) (go_core_pg.PoolStats){
-	childLogger.Info().Str("func","Stat").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Send()
+	childLogger.Info().Str("func","Stat").Ctx(ctx).Interface("trace-resquest-id", ctx.Value("trace-request-id")).Send()
 
 	return s.workerRepository.Stat(ctx)
 }
 
 // About create a card
 func (s *WorkerService) AddCard(ctx context.Context, card model.Card) (*model.Card, error){
-	childLogger.Info().Str("func","AddCard").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Interface("card", card).Send()
+	childLogger.Info().Str("func","AddCard").Ctx(ctx).Interface("trace-resquest-id", ctx.Value("trace-request-id")).Interface("card", card).Send()
 
 	// trace
 	ctx, span := tracerProvider.SpanCtx(ctx, "service.AddCard")
 	defer span.End()
-	trace_id := fmt.Sprintf("%v",ctx.Value("trace-request-id"))
-
-	// prepare database
-	tx, conn, err := s.workerRepository.DatabasePGServer.StartTx(ctx)
-	if err != nil {
-		return nil, err
-	}
-	defer s.workerRepository.DatabasePGServer.ReleaseTx(conn)
 
-	// handle connection
-	defer func() {
-		if err != nil {
-			tx.Rollback(ctx)
-		} else {
-			tx.Commit(ctx)
-		}
-		span.End()
-	}()
-
-	// Get the Account ID (PK) from Account-service
+	// the tenant follows the trace to the downstream services
+	ctx = withTenantBaggage(ctx, card.TenantID)
 
-	// Set headers
-	headers := map[string]string{
-		"Content-Type":  "application/json;charset=UTF-8",
-		"X-Request-Id": trace_id,
-		"x-apigw-api-id": s.apiService[0].XApigwApiId,
-		"Host": s.apiService[0].HostName,
-	}
-	// Set client http	
-	httpClient := go_core_api.HttpClient {
-		Url: 	s.apiService[0].Url + "/get/" + card.AccountID,
-		Method: s.apiService[0].Method,
-		Timeout: s.apiService[0].HttpTimeout,
-		Headers: &headers,
-	}
-
-	res_payload, statusCode, err := apiService.CallRestApiV1(	ctx,
-																s.goCoreRestApiService.Client,
-																httpClient, 
-																nil)
+	// Get the Account ID (PK) from Account-service, before any transaction is opened
+	account, err := s.getAccountByAccountID(ctx, card.AccountID)
 	if err != nil {
-		return nil, errorStatusCode(statusCode, s.apiService[0].Name, err)
+		return nil, err
 	}
 
-	jsonString, err  := json.Marshal(res_payload)
-	if err != nil {
-		return nil, errors.New(err.Error())
-    }
-	var account_parsed model.Account
-	json.Unmarshal(jsonString, &account_parsed)
-
-	// prepare data, set ID (PK_)
-	card.FkAccountID = account_parsed.ID
+	// prepare data, set ID (PK_), the predecessor is only set by a reissue
+	card.FkAccountID = account.ID
+	card.PredecessorID = 0
+	card.ReissueReason = ""
 
 	// add card
-	res, err := s.workerRepository.AddCard(ctx, tx, card)
+	var res *model.Card
+	err = s.workerRepository.WithTx(ctx, func(tx pgx.Tx) error {
+		res, err = s.workerRepository.AddCard(ctx, tx, card)
+		if err != nil {
+			return err
+		}
+		// the cvv2 is only returned at issuance, it is never stored
+		res.Cvv2, err = s.cardCVV2(*res)
+		return err
+	})
 	if err != nil {
 		return nil, err
 	}
+	s.metrics.recordCardIssued(ctx, *res)
 
 	return res, nil
 }
 
 // About get a card
 func (s *WorkerService) GetCard(ctx context.Context, card model.Card) (*model.Card, error){
-	childLogger.Info().Str("func","GetCard").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Interface("card", card).Send()
+	childLogger.Info().Str("func","GetCard").Ctx(ctx).Interface("trace-resquest-id", ctx.Value("trace-request-id")).Interface("card", card).Send()
 
 	// span and trace
 	ctx, span := tracerProvider.SpanCtx(ctx, "service.GetCard")
 	defer span.End()
 
-	trace_id := fmt.Sprintf("%v",ctx.Value("trace-request-id"))
-
 	// get card
 	res_card, err := s.workerRepository.GetCard(ctx, card)
 	if err != nil {
+		s.metrics.recordLookup(ctx, "get_card", card, err)
 		return nil, err
 	}
+	s.metrics.recordLookup(ctx, "get_card", *res_card, nil)
 
-	// Get the Account ID (PK) from Account-service
-	// Set headers
-	headers := map[string]string{
-		"Content-Type":  "application/json;charset=UTF-8",
-		"X-Request-Id": trace_id,
-		"x-apigw-api-id": s.apiService[0].XApigwApiId,
-		"Host": s.apiService[0].HostName,
-	}
-
-	// Set client http	
-	httpClient := go_core_api.HttpClient {
-		Url: 	s.apiService[0].Url + "/getId/" + fmt.Sprintf("%v",res_card.FkAccountID),
-		Method: s.apiService[0].Method,
-		Timeout: s.apiService[0].HttpTimeout,
-		Headers: &headers,
-	}
-	// get account_if from id (PK)
-	res_payload, statusCode, err := apiService.CallRestApiV1(ctx,
-															s.goCoreRestApiService.Client,
-															httpClient, 
-															nil)
+	// get account_id from id (PK)
+	ctx = withTenantBaggage(ctx, res_card.TenantID)
+	account, err := s.getAccountByID(ctx, res_card.FkAccountID)
 	if err != nil {
-		return nil, errorStatusCode(statusCode, s.apiService[0].Name, err)
-	}
-
-	jsonString, err  := json.Marshal(res_payload)
-	if err != nil {
-		return nil, errors.New(err.Error())
+		return nil, err
 	}
-	var account_parsed model.Account
-	json.Unmarshal(jsonString, &account_parsed)
-	res_card.AccountID = account_parsed.AccountID
+	res_card.AccountID = account.AccountID
 
 	return res_card, nil
 }
 
 // About update a update
 func (s *WorkerService) UpdateCard(ctx context.Context, card model.Card) (*model.Card, error){
-	childLogger.Info().Str("func","UpdateCard").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Interface("card", card).Send()
+	childLogger.Info().Str("func","UpdateCard").Ctx(ctx).Interface("trace-resquest-id", ctx.Value("trace-request-id")).Interface("card", card).Send()
 
 	// trace
 	ctx, span := tracerProvider.SpanCtx(ctx, "service.UpdateCard")
 	defer span.End()
 
-	// prepare database
-	tx, conn, err := s.workerRepository.DatabasePGServer.StartTx(ctx)
+	//Check data exists
+	res_check, err := s.workerRepository.GetCard(ctx, card)
 	if err != nil {
 		return nil, err
 	}
-	defer s.workerRepository.DatabasePGServer.ReleaseTx(conn)
+	if res_check.Status == cardStatusCanceled {
+		return nil, erro.ErrCardCanceled
+	}
+	card.ID = res_check.ID
 
-	// handle connection
-	defer func() {
+	// Do update atc
+	err = s.workerRepository.WithTx(ctx, func(tx pgx.Tx) error {
+		res_update, err := s.workerRepository.UpdateCard(ctx, tx, card)
 		if err != nil {
-			tx.Rollback(ctx)
-		} else {
-			tx.Commit(ctx)
+			return err
 		}
-		span.End()
-	}()
-
-	//Check data exists
-	_, err = s.workerRepository.GetCard(ctx, card)
+		if (res_update == 0) {
+			return erro.ErrUpdate
+		}
+		return nil
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	// Do update atc
-	res_update, err := s.workerRepository.UpdateCard(ctx, tx, card)
+	//Get atc data
+	res_card, err := s.workerRepository.GetCard(ctx, card)
 	if err != nil {
 		return nil, err
 	}
-	if (res_update == 0) {
-		return nil, erro.ErrUpdate
-	}
+	s.metrics.recordAtcIncrement(ctx, *res_card)
 
-	tx.Commit(ctx) // commit to get the new values updated below
+	return res_card, nil
+}
 
-	//Get atc data
-	res_card, err := s.workerRepository.GetCard(ctx, card)
+// About the token of a card, a keyed MAC of the card number (token key of the HSM)
+// A card tokenized before the HSM keeps its legacy token (blake3 of the card number), so tokenizing it again
+// gives the same token, the new cards only get the MAC
+// Without HSM the cards are tokenized with the legacy token, they keep it once the HSM is back
+func (s *WorkerService) cardToken(ctx context.Context, cardID int, cardNumber string) (string, error) {
+	legacy := blake3.Sum256([]byte(cardNumber))
+	legacyToken := hex.EncodeToString(legacy[:])
+
+	token, err := s.hsm.MAC(hsm.KeyTK, []byte(cardNumber))
+	if errors.Is(err, erro.ErrHSMUnavailable) {
+		childLogger.Warn().Ctx(ctx).Int("card_id", cardID).Msg("legacy token (blake3), the hsm is unavailable")
+		return legacyToken, nil
+	}
 	if err != nil {
-		return nil, err
+		return "", err
 	}
 
-	return res_card, nil
+	exists, err := s.workerRepository.HasCardToken(ctx, cardID, legacyToken)
+	if err != nil {
+		return "", err
+	}
+	if exists {
+		return legacyToken, nil
+	}
+	return hex.EncodeToString(token), nil
 }
 
 // About create a tokenization data
 func (s * WorkerService) CreateCardToken(ctx context.Context, card model.Card) (*model.Card, error){
-	childLogger.Info().Str("func","CreateCardToken").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Interface("card", card).Send()
+	childLogger.Info().Str("func","CreateCardToken").Ctx(ctx).Interface("trace-resquest-id", ctx.Value("trace-request-id")).Interface("card", card).Send()
 
 	// Trace
 	ctx, span := tracerProvider.SpanCtx(ctx, "service.CreateCardToken")
-
-	// Get the database connection
-	tx, conn, err := s.workerRepository.DatabasePGServer.StartTx(ctx)
-	if err != nil {
-		return nil, err
-	}
-
-	// Handle the transaction
-	defer func() {
-		if err != nil {
-			tx.Rollback(ctx)
-		} else {
-			tx.Commit(ctx)
-		}
-		s.workerRepository.DatabasePGServer.ReleaseTx(conn)
-		span.End()
-	}()
+	defer span.End()
 
 	// Get cards info from token (FkAccountID)
 	res_card, err := s.workerRepository.GetCard(ctx, card)
 	if err != nil {
 		return nil, err
 	}
+	if res_card.Status == cardStatusCanceled {
+		return nil, erro.ErrCardCanceled
+	}
 
 	// prepare data
-	hasher := blake3.New()
-	hasher.Write([]byte(card.CardNumber))
-	card.TokenData = fmt.Sprintf("%x", (hasher.Sum(nil)) )
+	card.TokenData, err = s.cardToken(ctx, res_card.ID, card.CardNumber)
+	if err != nil {
+		return nil, err
+	}
 	card.Status = "ACTIVE"
 
 	card.CreatedAt = time.Now()
 	card.ExpiredAt = time.Now().AddDate(0, 3, 0) // Add 3 months
 	card.ID = res_card.ID
+	if card.TenantID == "" {
+		card.TenantID = res_card.TenantID
+	}
 
 	// Call a service
-	res, err := s.workerRepository.CreateCardToken(ctx, tx, card)
+	var res *model.Card
+	err = s.workerRepository.WithTx(ctx, func(tx pgx.Tx) error {
+		res, err = s.workerRepository.CreateCardToken(ctx, tx, card)
+		return err
+	})
 	if err != nil {
 		return nil, err
 	}
 
 	// Setting PK
 	card.ID = res.ID
+	s.metrics.recordTokenCreated(ctx, model.Card{TenantID: card.TenantID, Type: res_card.Type, Status: card.Status})
 
 	return &card, nil
 }
 
 // About get the card from token
 func (s * WorkerService) GetCardToken(ctx context.Context, card model.Card) (*[]model.Card, error){
-	childLogger.Info().Str("func","GetCardToken").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Interface("card", card).Send()
+	childLogger.Info().Str("func","GetCardToken").Ctx(ctx).Interface("trace-resquest-id", ctx.Value("trace-request-id")).Interface("card", card).Send()
 
 	// Trace
 	ctx, span := tracerProvider.SpanCtx(ctx, "service.GetCardToken")
@@ -306,23 +391,33 @@ func (s * WorkerService) GetCardToken(ctx context.Context, card model.Card) (*[]
 	// Call a service
 	res, err := s.workerRepository.GetCardToken(ctx, card)
 	if err != nil {
+		s.metrics.recordLookup(ctx, "get_card_token", card, err)
 		return nil, err
 	}
+	if len(*res) == 0 {
+		s.metrics.recordLookup(ctx, "get_card_token", card, erro.ErrNotFound)
+	} else {
+		s.metrics.recordLookup(ctx, "get_card_token", (*res)[0], nil)
+	}
 
 	return res, nil
 }
 
 // About check health service
 func (s * WorkerService) HealthCheck(ctx context.Context) error{
-	childLogger.Info().Str("func","HealthCheck").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Send()
+	childLogger.Info().Str("func","HealthCheck").Ctx(ctx).Interface("trace-resquest-id", ctx.Value("trace-request-id")).Send()
 
 	// Trace
 	ctx, span := tracerProvider.SpanCtx(ctx, "service.HealthCheck")
 	defer span.End()
 	trace_id := fmt.Sprintf("%v",ctx.Value("trace-request-id"))
+	accountService, err := s.getApiService(accountServiceName)
+	if err != nil {
+		return err
+	}
 
 	// Check database health
-	err := s.workerRepository.DatabasePGServer.Ping()
+	err = s.workerRepository.DatabasePGServer.Ping()
 	if err != nil {
 		log.Error().Err(err).Msg("*** Database HEALTH FAILED ***")
 		return erro.ErrHealthCheck
@@ -333,22 +428,19 @@ func (s * WorkerService) HealthCheck(ctx context.Context) error{
 	headers := map[string]string{
 		"Content-Type":  "application/json;charset=UTF-8",
 		"X-Request-Id": trace_id,
-		"Host": s.apiService[0].HostName,
+		"Host": accountService.HostName,
 	}
 
 	// Set client http	
 	httpClient := go_core_api.HttpClient {
-		Url: 	s.apiService[0].Url + "/health",
-		Method: s.apiService[0].Method,
-		Timeout: s.apiService[0].HttpTimeout,
+		Url: 	accountService.Url + "/health",
+		Method: accountService.Method,
+		Timeout: accountService.HttpTimeout,
 		Headers: &headers,
 	}
 
 	// get account_if from id (PK)
-	_, _, err = apiService.CallRestApiV1(	ctx,
-											s.goCoreRestApiService.Client,
-											httpClient, 
-											nil)
+	_, _, err = s.doRequest(ctx, accountService, httpClient)
 	if err != nil {
 		log.Error().Err(err).Msg("*** Service ACCOUNT HEALTH FAILED ***")
 		return erro.ErrHealthCheck
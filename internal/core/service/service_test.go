@@ -0,0 +1,26 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"encoding/hex"
+
+	"github.com/zeebo/blake3"
+
+	"github.com/go-card/internal/core/hsm"
+)
+
+func TestCardTokenHSMUnavailable(t *testing.T) {
+	cardNumber := "4123456789012345"
+	legacy := blake3.Sum256([]byte(cardNumber))
+
+	// without HSM the repository is not needed, the token is always the legacy one
+	s := &WorkerService{hsm: &hsm.UnavailableHSM{}}
+	token, err := s.cardToken(context.Background(), 1, cardNumber)
+	if err != nil {
+		t.Fatalf("card token: %v", err)
+	}
+	if token != hex.EncodeToString(legacy[:]) {
+		t.Errorf("token %s, want the blake3 token", token)
+	}
+}
@@ -0,0 +1,195 @@
+package cache
+
+import (
+	"sync"
+	"time"
+	"context"
+	"container/list"
+
+	"golang.org/x/sync/singleflight"
+
+	"github.com/go-card/internal/infra/logger"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+var childLogger = logger.With().Str("component","go-card").Str("package","internal.infra.cache").Logger()
+
+type entry[V any] struct {
+	key			string
+	value		V
+	expiresAt	time.Time
+}
+
+// About a bounded LRU cache with TTL, concurrent misses of the same key are loaded only once
+type LRU[V any] struct {
+	name		string
+	mutex		sync.Mutex
+	capacity	int
+	ttl			time.Duration
+	items		map[string]*list.Element
+	order		*list.List
+	group		singleflight.Group
+	hits		metric.Int64Counter
+	misses		metric.Int64Counter
+	attributes	metric.MeasurementOption
+}
+
+// About create a cache
+func NewLRU[V any](name string, capacity int, ttl time.Duration) *LRU[V] {
+	childLogger.Info().Str("func","NewLRU").Str("name", name).Int("capacity", capacity).Dur("ttl", ttl).Send()
+
+	lru := &LRU[V]{
+		name:		name,
+		capacity:	capacity,
+		ttl:		ttl,
+		items:		map[string]*list.Element{},
+		order:		list.New(),
+		attributes:	metric.WithAttributes(attribute.String("cache", name)),
+	}
+
+	meter := otel.Meter("go-card")
+	var err error
+	lru.hits, err = meter.Int64Counter("cache_hit", metric.WithDescription("Cache hits"))
+	if err != nil {
+		childLogger.Error().Err(err).Msg("error register cache_hit metric")
+	}
+	lru.misses, err = meter.Int64Counter("cache_miss", metric.WithDescription("Cache misses"))
+	if err != nil {
+		childLogger.Error().Err(err).Msg("error register cache_miss metric")
+	}
+	_, err = meter.Int64ObservableGauge(
+		"cache_size",
+		metric.WithDescription("Number of entries in the cache"),
+		metric.WithInt64Callback(func(_ context.Context, observer metric.Int64Observer) error {
+			observer.Observe(int64(lru.Len()), lru.attributes)
+			return nil
+		}),
+	)
+	if err != nil {
+		childLogger.Error().Err(err).Msg("error register cache_size metric")
+	}
+
+	return lru
+}
+
+// About get a value, expired entries are removed
+func (c *LRU[V]) Get(ctx context.Context, key string) (V, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	var zero V
+	element, ok := c.items[key]
+	if !ok {
+		c.record(ctx, c.misses)
+		return zero, false
+	}
+
+	item := element.Value.(*entry[V])
+	if time.Now().After(item.expiresAt) {
+		c.removeElement(element)
+		c.record(ctx, c.misses)
+		return zero, false
+	}
+
+	c.order.MoveToFront(element)
+	c.record(ctx, c.hits)
+	return item.value, true
+}
+
+// About set a value, the least recently used entry is evicted when the cache is full
+func (c *LRU[V]) Set(key string, value V) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if element, ok := c.items[key]; ok {
+		item := element.Value.(*entry[V])
+		item.value = value
+		item.expiresAt = time.Now().Add(c.ttl)
+		c.order.MoveToFront(element)
+		return
+	}
+
+	c.items[key] = c.order.PushFront(&entry[V]{key: key, value: value, expiresAt: time.Now().Add(c.ttl)})
+
+	for c.capacity > 0 && c.order.Len() > c.capacity {
+		c.removeElement(c.order.Back())
+	}
+}
+
+// About get a value or load it, concurrent loads of the same key share one call
+// The load runs on a context detached from the caller with its own timeout, so a caller canceled doesn't fail
+// the others waiting on the key, the canceled caller returns at once. A load error is not cached
+func (c *LRU[V]) GetOrLoad(ctx context.Context, key string, timeout time.Duration, load func(ctx context.Context) (V, error)) (V, error) {
+	var zero V
+	if value, ok := c.Get(ctx, key); ok {
+		return value, nil
+	}
+
+	ch := c.group.DoChan(key, func() (interface{}, error) {
+		loadCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), timeout)
+		defer cancel()
+
+		value, err := load(loadCtx)
+		if err != nil {
+			return nil, err
+		}
+		c.Set(key, value)
+		return value, nil
+	})
+
+	select {
+	case <-ctx.Done():
+		return zero, ctx.Err()
+	case res := <-ch:
+		if res.Err != nil {
+			return zero, res.Err
+		}
+		return res.Val.(V), nil
+	}
+}
+
+// About remove an entry
+func (c *LRU[V]) Delete(key string) bool {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	element, ok := c.items[key]
+	if ok {
+		c.removeElement(element)
+	}
+	return ok
+}
+
+// About remove all entries, returns the number of removed entries
+func (c *LRU[V]) Purge() int {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	count := c.order.Len()
+	c.items = map[string]*list.Element{}
+	c.order.Init()
+
+	childLogger.Info().Str("func","Purge").Str("name", c.name).Int("count", count).Send()
+	return count
+}
+
+// About the number of entries
+func (c *LRU[V]) Len() int {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.order.Len()
+}
+
+func (c *LRU[V]) removeElement(element *list.Element) {
+	c.order.Remove(element)
+	delete(c.items, element.Value.(*entry[V]).key)
+}
+
+func (c *LRU[V]) record(ctx context.Context, counter metric.Int64Counter) {
+	if counter != nil {
+		counter.Add(ctx, 1, c.attributes)
+	}
+}
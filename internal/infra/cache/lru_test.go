@@ -0,0 +1,194 @@
+package cache
+
+import (
+	"sync"
+	"time"
+	"errors"
+	"context"
+	"testing"
+	"sync/atomic"
+)
+
+func TestLRUEviction(t *testing.T) {
+	tests := []struct {
+		name		string
+		capacity	int
+		operations	func(c *LRU[int])
+		present		[]string
+		evicted		[]string
+	}{
+		{"least recently set", 2, func(c *LRU[int]) {
+			c.Set("a", 1)
+			c.Set("b", 2)
+			c.Set("c", 3)
+		}, []string{"b", "c"}, []string{"a"}},
+		{"a get keeps the entry", 2, func(c *LRU[int]) {
+			c.Set("a", 1)
+			c.Set("b", 2)
+			c.Get(context.Background(), "a")
+			c.Set("c", 3)
+		}, []string{"a", "c"}, []string{"b"}},
+		{"a set of the same key keeps the entry", 2, func(c *LRU[int]) {
+			c.Set("a", 1)
+			c.Set("b", 2)
+			c.Set("a", 10)
+			c.Set("c", 3)
+		}, []string{"a", "c"}, []string{"b"}},
+		{"no capacity is unbounded", 0, func(c *LRU[int]) {
+			c.Set("a", 1)
+			c.Set("b", 2)
+			c.Set("c", 3)
+		}, []string{"a", "b", "c"}, nil},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := NewLRU[int]("test", test.capacity, time.Minute)
+			test.operations(c)
+
+			if c.Len() != len(test.present) {
+				t.Errorf("len %d, want %d", c.Len(), len(test.present))
+			}
+			for _, key := range test.present {
+				if _, ok := c.Get(context.Background(), key); !ok {
+					t.Errorf("key %s evicted", key)
+				}
+			}
+			for _, key := range test.evicted {
+				if _, ok := c.Get(context.Background(), key); ok {
+					t.Errorf("key %s not evicted", key)
+				}
+			}
+		})
+	}
+}
+
+func TestLRUExpiryDeletePurge(t *testing.T) {
+	c := NewLRU[int]("test", 10, time.Millisecond)
+	c.Set("a", 1)
+	time.Sleep(2 * time.Millisecond)
+	if _, ok := c.Get(context.Background(), "a"); ok {
+		t.Errorf("expired entry returned")
+	}
+	if c.Len() != 0 {
+		t.Errorf("len %d after expiry, want 0", c.Len())
+	}
+
+	c = NewLRU[int]("test", 10, time.Minute)
+	c.Set("a", 1)
+	c.Set("b", 2)
+	if !c.Delete("a") || c.Delete("a") {
+		t.Errorf("delete must report if the key was present")
+	}
+	if count := c.Purge(); count != 1 || c.Len() != 0 {
+		t.Errorf("purge %d, len %d, want 1 and 0", count, c.Len())
+	}
+}
+
+func TestGetOrLoadSingleFlight(t *testing.T) {
+	c := NewLRU[int]("test", 10, time.Minute)
+
+	var loads atomic.Int32
+	release := make(chan struct{})
+	load := func(ctx context.Context) (int, error) {
+		loads.Add(1)
+		<-release
+		return 42, nil
+	}
+
+	// the callers arriving after the load get the cached value, so there is one load in every case
+	const callers = 10
+	var wg sync.WaitGroup
+	results := make(chan int, callers)
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			value, err := c.GetOrLoad(context.Background(), "a", time.Second, load)
+			if err != nil {
+				t.Errorf("get or load: %v", err)
+			}
+			results <- value
+		}()
+	}
+	time.Sleep(10 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	close(results)
+
+	if loads.Load() != 1 {
+		t.Errorf("%d loads, want 1", loads.Load())
+	}
+	for value := range results {
+		if value != 42 {
+			t.Errorf("value %d, want 42", value)
+		}
+	}
+}
+
+func TestGetOrLoadCanceledCaller(t *testing.T) {
+	c := NewLRU[int]("test", 10, time.Minute)
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	var loadErr error
+	load := func(ctx context.Context) (int, error) {
+		close(started)
+		<-release
+		loadErr = ctx.Err()
+		return 42, nil
+	}
+
+	canceled, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		_, err := c.GetOrLoad(canceled, "a", time.Second, load)
+		done <- err
+	}()
+	<-started
+
+	other := make(chan int, 1)
+	go func() {
+		value, _ := c.GetOrLoad(context.Background(), "a", time.Second, load)
+		other <- value
+	}()
+
+	// the canceled caller returns at once, the load goes on for the other one
+	cancel()
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Errorf("canceled caller err %v, want %v", err, context.Canceled)
+	}
+	close(release)
+	if value := <-other; value != 42 {
+		t.Errorf("value %d, want 42", value)
+	}
+	if loadErr != nil {
+		t.Errorf("load context err %v, want none", loadErr)
+	}
+}
+
+func TestGetOrLoadError(t *testing.T) {
+	c := NewLRU[int]("test", 10, time.Minute)
+	errLoad := errors.New("account service down")
+
+	tests := []struct {
+		name	string
+		key		string
+		load	func(ctx context.Context) (int, error)
+		value	int
+		err		error
+	}{
+		{"error", "a", func(ctx context.Context) (int, error) { return 0, errLoad }, 0, errLoad},
+		{"error not cached", "a", func(ctx context.Context) (int, error) { return 7, nil }, 7, nil},
+		{"timeout", "b", func(ctx context.Context) (int, error) { <-ctx.Done(); return 0, ctx.Err() }, 0, context.DeadlineExceeded},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			value, err := c.GetOrLoad(context.Background(), test.key, 10 * time.Millisecond, test.load)
+			if !errors.Is(err, test.err) || value != test.value {
+				t.Errorf("value %d err %v, want %d %v", value, err, test.value, test.err)
+			}
+		})
+	}
+}
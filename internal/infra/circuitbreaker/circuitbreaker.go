@@ -0,0 +1,208 @@
+package circuitbreaker
+
+import (
+	"sync"
+	"time"
+	"context"
+
+	"github.com/go-card/internal/core/model"
+	"github.com/go-card/internal/core/erro"
+	"github.com/go-card/internal/infra/logger"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+var childLogger = logger.With().Str("component","go-card").Str("package","internal.infra.circuitbreaker").Logger()
+
+type State int
+
+const (
+	StateClosed State = iota
+	StateHalfOpen
+	StateOpen
+)
+
+func (s State) String() string {
+	switch s {
+	case StateClosed:
+		return "CLOSED"
+	case StateHalfOpen:
+		return "HALF_OPEN"
+	case StateOpen:
+		return "OPEN"
+	default:
+		return "UNKNOWN"
+	}
+}
+
+// About a circuit breaker of a downstream service
+type CircuitBreaker struct {
+	name			string
+	mutex			sync.Mutex
+	config			model.CircuitBreakerConfig
+	state			State
+	failures		int
+	openedAt		time.Time
+	halfOpenCalls	int
+}
+
+// About create a circuit breaker
+func NewCircuitBreaker(name string, config model.CircuitBreakerConfig) *CircuitBreaker {
+	childLogger.Info().Str("func","NewCircuitBreaker").Str("name", name).Interface("config", config).Send()
+
+	return &CircuitBreaker{
+		name:	name,
+		config:	config,
+		state:	StateClosed,
+	}
+}
+
+// About change the config (config reload), the state is kept
+func (c *CircuitBreaker) SetConfig(config model.CircuitBreakerConfig) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.config = config
+}
+
+// About get the current state
+func (c *CircuitBreaker) State() State {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.refresh()
+	return c.state
+}
+
+// About move from open to half-open when the open timeout is over
+func (c *CircuitBreaker) refresh() {
+	if c.state == StateOpen && time.Since(c.openedAt) >= c.config.OpenTimeout {
+		c.transition(StateHalfOpen)
+	}
+}
+
+func (c *CircuitBreaker) transition(state State) {
+	childLogger.Warn().Str("func","transition").Str("name", c.name).Str("from", c.state.String()).Str("to", state.String()).Send()
+
+	c.state = state
+	c.failures = 0
+	c.halfOpenCalls = 0
+	if state == StateOpen {
+		c.openedAt = time.Now()
+	}
+}
+
+// About check if a call is allowed
+func (c *CircuitBreaker) Allow() error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.refresh()
+
+	switch c.state {
+	case StateOpen:
+		return erro.ErrCircuitOpen
+	case StateHalfOpen:
+		if c.halfOpenCalls >= c.config.HalfOpenMaxCalls {
+			return erro.ErrCircuitOpen
+		}
+		c.halfOpenCalls++
+	}
+	return nil
+}
+
+// About record a successful call
+func (c *CircuitBreaker) Success() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	switch c.state {
+	case StateHalfOpen:
+		c.transition(StateClosed)
+	default:
+		c.failures = 0
+	}
+}
+
+// About record a failed call
+func (c *CircuitBreaker) Failure() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	switch c.state {
+	case StateHalfOpen:
+		c.transition(StateOpen)
+	case StateClosed:
+		c.failures++
+		if c.failures >= c.config.FailureThreshold {
+			c.transition(StateOpen)
+		}
+	}
+}
+
+// About keep one circuit breaker per downstream service
+type Registry struct {
+	mutex		sync.RWMutex
+	breakers	map[string]*CircuitBreaker
+}
+
+// About create a registry and export the breakers state as metric
+func NewRegistry() *Registry {
+	childLogger.Info().Str("func","NewRegistry").Send()
+
+	registry := &Registry{
+		breakers: map[string]*CircuitBreaker{},
+	}
+
+	meter := otel.Meter("go-card")
+	_, err := meter.Int64ObservableGauge(
+		"circuit_breaker_state",
+		metric.WithDescription("Circuit breaker state per downstream service (0 closed, 1 half-open, 2 open)"),
+		metric.WithInt64Callback(func(_ context.Context, observer metric.Int64Observer) error {
+			registry.mutex.RLock()
+			defer registry.mutex.RUnlock()
+
+			for name, breaker := range registry.breakers {
+				observer.Observe(int64(breaker.State()), metric.WithAttributes(attribute.String("service", name)))
+			}
+			return nil
+		}),
+	)
+	if err != nil {
+		childLogger.Error().Err(err).Msg("error register circuit_breaker_state metric")
+	}
+
+	return registry
+}
+
+// About get the circuit breaker of a downstream service, it is created at the first call
+func (r *Registry) Get(downstream model.ApiService) *CircuitBreaker {
+	r.mutex.RLock()
+	breaker, ok := r.breakers[downstream.Name]
+	r.mutex.RUnlock()
+	if ok {
+		return breaker
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	breaker, ok = r.breakers[downstream.Name]
+	if !ok {
+		breaker = NewCircuitBreaker(downstream.Name, downstream.CircuitBreaker)
+		r.breakers[downstream.Name] = breaker
+	}
+	return breaker
+}
+
+// About apply the config of the downstream services (config reload)
+func (r *Registry) SetConfig(apiService map[string]model.ApiService) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	for name, breaker := range r.breakers {
+		if downstream, ok := apiService[name]; ok {
+			breaker.SetConfig(downstream.CircuitBreaker)
+		}
+	}
+}
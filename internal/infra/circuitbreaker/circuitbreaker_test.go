@@ -0,0 +1,135 @@
+package circuitbreaker
+
+import (
+	"time"
+	"errors"
+	"testing"
+
+	"github.com/go-card/internal/core/model"
+	"github.com/go-card/internal/core/erro"
+)
+
+// the operations of a test sequence
+const (
+	opAllow		= "allow"
+	opSuccess	= "success"
+	opFailure	= "failure"
+	opTimeout	= "timeout" // the open timeout is over
+)
+
+type step struct {
+	op		string
+	err		error // of allow
+	state	State // after the operation
+}
+
+func TestCircuitBreakerTransitions(t *testing.T) {
+	config := model.CircuitBreakerConfig{FailureThreshold: 3, OpenTimeout: time.Minute, HalfOpenMaxCalls: 1}
+
+	open := []step{
+		{opFailure, nil, StateClosed},
+		{opFailure, nil, StateClosed},
+		{opFailure, nil, StateOpen},
+	}
+
+	tests := []struct {
+		name	string
+		steps	[]step
+	}{
+		{"closed allows", []step{
+			{opAllow, nil, StateClosed},
+		}},
+		{"opens at the threshold", append(open, step{opAllow, erro.ErrCircuitOpen, StateOpen})},
+		{"success resets the failures", []step{
+			{opFailure, nil, StateClosed},
+			{opFailure, nil, StateClosed},
+			{opSuccess, nil, StateClosed},
+			{opFailure, nil, StateClosed},
+			{opFailure, nil, StateClosed},
+		}},
+		{"half-open after the open timeout", append(open,
+			step{opTimeout, nil, StateHalfOpen},
+			step{opAllow, nil, StateHalfOpen},
+			step{opAllow, erro.ErrCircuitOpen, StateHalfOpen},
+		)},
+		{"half-open success closes", append(open,
+			step{opTimeout, nil, StateHalfOpen},
+			step{opAllow, nil, StateHalfOpen},
+			step{opSuccess, nil, StateClosed},
+			step{opAllow, nil, StateClosed},
+		)},
+		{"half-open failure opens again", append(open,
+			step{opTimeout, nil, StateHalfOpen},
+			step{opAllow, nil, StateHalfOpen},
+			step{opFailure, nil, StateOpen},
+			step{opAllow, erro.ErrCircuitOpen, StateOpen},
+		)},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			breaker := NewCircuitBreaker(test.name, config)
+			for i, step := range test.steps {
+				switch step.op {
+				case opAllow:
+					if err := breaker.Allow(); !errors.Is(err, step.err) {
+						t.Fatalf("step %d allow err %v, want %v", i, err, step.err)
+					}
+				case opSuccess:
+					breaker.Success()
+				case opFailure:
+					breaker.Failure()
+				case opTimeout:
+					breaker.mutex.Lock()
+					breaker.openedAt = time.Now().Add(-config.OpenTimeout)
+					breaker.mutex.Unlock()
+				}
+				if state := breaker.State(); state != step.state {
+					t.Fatalf("step %d %s state %s, want %s", i, step.op, state, step.state)
+				}
+			}
+		})
+	}
+}
+
+func TestRegistryGet(t *testing.T) {
+	registry := NewRegistry()
+	downstream := model.ApiService{Name: "go-account", CircuitBreaker: model.CircuitBreakerConfig{FailureThreshold: 1, OpenTimeout: time.Minute}}
+
+	breaker := registry.Get(downstream)
+	if registry.Get(downstream) != breaker {
+		t.Fatalf("a second breaker was created for the same service")
+	}
+
+	// the config reload keeps the state
+	breaker.Failure()
+	downstream.CircuitBreaker.FailureThreshold = 5
+	registry.SetConfig(map[string]model.ApiService{downstream.Name: downstream})
+	if state := breaker.State(); state != StateOpen {
+		t.Errorf("state %s after the config reload, want %s", state, StateOpen)
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	config := model.RetryConfig{BaseDelay: 100 * time.Millisecond, MaxDelay: time.Second}
+
+	tests := []struct {
+		attempt	int
+		delay	time.Duration // before the jitter
+	}{
+		{0, 100 * time.Millisecond},
+		{1, 200 * time.Millisecond},
+		{3, 800 * time.Millisecond},
+		{4, time.Second},
+		{62, time.Second},
+	}
+
+	for _, test := range tests {
+		for i := 0; i < 20; i++ {
+			delay := Backoff(test.attempt, config)
+			if delay < test.delay / 2 || delay > test.delay {
+				t.Fatalf("attempt %d delay %s, want between %s and %s", test.attempt, delay, test.delay / 2, test.delay)
+			}
+		}
+	}
+}
@@ -0,0 +1,21 @@
+package circuitbreaker
+
+import (
+	"time"
+	"math/rand"
+
+	"github.com/go-card/internal/core/model"
+)
+
+// About the delay before the next attempt: exponential backoff with jitter (between 50% and 100% of the delay)
+func Backoff(attempt int, config model.RetryConfig) time.Duration {
+	delay := config.BaseDelay << attempt
+	if delay <= 0 || delay > config.MaxDelay {
+		delay = config.MaxDelay
+	}
+	half := int64(delay / 2)
+	if half <= 0 {
+		return delay
+	}
+	return time.Duration(half + rand.Int63n(half + 1))
+}
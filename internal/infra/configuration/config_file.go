@@ -0,0 +1,109 @@
+package configuration
+
+import(
+	"os"
+	"fmt"
+	"bytes"
+	"errors"
+	"strconv"
+	"strings"
+	"encoding/json"
+)
+
+// About the structured config file (CONFIG_FILE), the env vars are applied on top of it
+type configFile struct {
+	ApiServices		map[string]serviceConfig	`json:"api_services"`
+	RateLimit		*rateLimitConfig			`json:"rate_limit"`
+	FeatureFlags	map[string]bool				`json:"feature_flags"`
+	AccountCache	*cacheConfig				`json:"account_cache"`
+	ExpiryJob		*expiryJobConfig			`json:"expiry_job"`
+}
+
+type serviceConfig struct {
+	Url				string	`json:"url"`
+	Method			string	`json:"method"`
+	XApigwApiId		string	`json:"x-apigw-api-id,omitempty"`
+	HostName		string	`json:"host_name"`
+	HttpTimeout		int		`json:"http_timeout"` // seconds
+	CircuitBreaker	*circuitBreakerConfig	`json:"circuit_breaker"`
+	Retry			*retryConfig			`json:"retry"`
+}
+
+type circuitBreakerConfig struct {
+	FailureThreshold	int	`json:"failure_threshold"`
+	OpenTimeout			int	`json:"open_timeout"` // seconds
+	HalfOpenMaxCalls	int	`json:"half_open_max_calls"`
+}
+
+type retryConfig struct {
+	MaxAttempts			int	`json:"max_attempts"`
+	BaseDelay			int	`json:"base_delay_ms"`
+	MaxDelay			int	`json:"max_delay_ms"`
+}
+
+type cacheConfig struct {
+	Size				int	`json:"size"`
+	TTL					int	`json:"ttl"` // seconds
+}
+
+type expiryJobConfig struct {
+	Interval			*int	`json:"interval"` // seconds, 0 disables the scheduler
+	RenewalDays			int		`json:"renewal_days"`
+	ChunkSize			int		`json:"chunk_size"`
+}
+
+type rateLimitConfig struct {
+	RequestsPerSecond	int	`json:"requests_per_second"`
+	Burst				int	`json:"burst"`
+}
+
+// About load the config file, a missing file means an empty config (env var only)
+func loadConfigFile() (*configFile, error) {
+	path := "config.json" // default
+	if os.Getenv("CONFIG_FILE") !=  "" {
+		path = os.Getenv("CONFIG_FILE")
+	}
+
+	var config configFile
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			childLogger.Info().Str("config_file", path).Msg("config file not found, using only env var")
+			return &config, nil
+		}
+		return nil, fmt.Errorf("config file %s: %w", path, err)
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&config); err != nil {
+		return nil, fmt.Errorf("config file %s: %w", path, err)
+	}
+
+	return &config, nil
+}
+
+// About read an int env var, an invalid value is an error
+func getEnvInt(key string, value *int) error {
+	if os.Getenv(key) ==  "" {
+		return nil
+	}
+	intVar, err := strconv.Atoi(strings.TrimSpace(os.Getenv(key)))
+	if err != nil {
+		return fmt.Errorf("%s must be an integer, got %q", key, os.Getenv(key))
+	}
+	*value = intVar
+	return nil
+}
+
+// About the env var prefix of a named service (ex: go-account => SERVICE_GO_ACCOUNT)
+func serviceEnvPrefix(name string) string {
+	prefix := strings.Map(func(r rune) rune {
+		if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
+			return r
+		}
+		return '_'
+	}, name)
+	return "SERVICE_" + strings.ToUpper(prefix)
+}
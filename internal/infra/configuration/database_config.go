@@ -2,7 +2,6 @@ package configuration
 
 import(
 	"os"
-	"strconv"
 
 	"github.com/joho/godotenv"
 	
@@ -28,9 +27,9 @@ func GetDatabaseEnv() go_core_pg.DatabaseConfig {
 	if os.Getenv("DB_NAME") !=  "" {	
 		databaseConfig.DatabaseName = os.Getenv("DB_NAME")
 	}
-	if os.Getenv("DB_MAX_CONNECTION") !=  "" {
-		intVar, _ := strconv.Atoi(os.Getenv("DB_MAX_CONNECTION"))
-		databaseConfig.DbMax_Connection = intVar
+	if err := getEnvInt("DB_MAX_CONNECTION", &databaseConfig.DbMax_Connection); err != nil {
+		childLogger.Error().Err(err).Send()
+		os.Exit(3)
 	}
 
 	// Get Database Secrets
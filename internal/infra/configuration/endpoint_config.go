@@ -2,120 +2,156 @@ package configuration
 
 import(
 	"os"
+	"fmt"
 	"time"
-	"strconv"
-	
+	"errors"
+
 	"github.com/joho/godotenv"
 
 	"github.com/go-card/internal/core/model"
 )
 
-// About get services endpoints env var
-func GetEndpointEnv() []model.ApiService {
+// About get services endpoints from the config file and env var
+// The legacy slots (NAME_SERVICE_00, URL_SERVICE_00 ...) are still supported,
+// and SERVICE_<NAME>_* env vars override the values of a named service
+func GetEndpointEnv() (map[string]model.ApiService, error) {
 	childLogger.Info().Str("func","GetEndpointEnv").Send()
 
 	err := godotenv.Load(".env")
 	if err != nil {
 		childLogger.Info().Err(err).Send()
 	}
-	
-	var apiService []model.ApiService
 
-	var apiService00 model.ApiService
-	if os.Getenv("URL_SERVICE_00") !=  "" {
-		apiService00.Url = os.Getenv("URL_SERVICE_00")
-	}
-	if os.Getenv("X_APIGW_API_ID_SERVICE_00") !=  "" {
-		apiService00.XApigwApiId = os.Getenv("X_APIGW_API_ID_SERVICE_00")
-	}
-	if os.Getenv("METHOD_SERVICE_00") !=  "" {
-		apiService00.Method = os.Getenv("METHOD_SERVICE_00")
-	}
-	if os.Getenv("NAME_SERVICE_00") !=  "" {
-		apiService00.Name = os.Getenv("NAME_SERVICE_00")
-	}
-	if os.Getenv("HOST_SERVICE_00") !=  "" {
-		apiService00.HostName = os.Getenv("HOST_SERVICE_00")
-	}
-	if os.Getenv("CLIENT_HTTP_TIMEOUT_00") !=  "" {
-		intVar, _ := strconv.Atoi(os.Getenv("CLIENT_HTTP_TIMEOUT_00"))	
-		apiService00.HttpTimeout = time.Duration(intVar) * time.Second
-	} else {
-		apiService00.HttpTimeout =(5 * time.Second) // default
+	config, err := loadConfigFile()
+	if err != nil {
+		return nil, err
 	}
-	apiService = append(apiService, apiService00)
 
-	var apiService01 model.ApiService
-	if os.Getenv("URL_SERVICE_01") !=  "" {
-		apiService01.Url = os.Getenv("URL_SERVICE_01")
-	}
-	if os.Getenv("X_APIGW_API_ID_SERVICE_01") !=  "" {
-		apiService01.XApigwApiId = os.Getenv("X_APIGW_API_ID_SERVICE_01")
-	}
-	if os.Getenv("METHOD_SERVICE_01") !=  "" {
-		apiService01.Method = os.Getenv("METHOD_SERVICE_01")
-	}
-	if os.Getenv("NAME_SERVICE_01") !=  "" {
-		apiService01.Name = os.Getenv("NAME_SERVICE_01")
-	}
-	if os.Getenv("HOST_SERVICE_02") !=  "" {
-		apiService01.HostName = os.Getenv("HOST_SERVICE_01")
-	}
-	if os.Getenv("CLIENT_HTTP_TIMEOUT_01") !=  "" {
-		intVar, _ := strconv.Atoi(os.Getenv("CLIENT_HTTP_TIMEOUT_01"))	
-		apiService01.HttpTimeout = time.Duration(intVar) * time.Second
-	} else {
-		apiService01.HttpTimeout =(5 * time.Second) // default
-	}
-	apiService = append(apiService, apiService01)
+	apiService := map[string]model.ApiService{}
+	var errs []error
 
-	var apiService02 model.ApiService
-	if os.Getenv("URL_SERVICE_02") !=  "" {
-		apiService02.Url = os.Getenv("URL_SERVICE_02")
-	}
-	if os.Getenv("X_APIGW_API_ID_SERVICE_02") !=  "" {
-		apiService02.XApigwApiId = os.Getenv("X_APIGW_API_ID_SERVICE_02")
+	// config file
+	for name, service := range config.ApiServices {
+		apiServiceConfig := model.ApiService{
+			Name:			name,
+			Url:			service.Url,
+			Method:			service.Method,
+			XApigwApiId:	service.XApigwApiId,
+			HostName:		service.HostName,
+			HttpTimeout:	time.Duration(service.HttpTimeout) * time.Second,
+		}
+		if service.CircuitBreaker != nil {
+			apiServiceConfig.CircuitBreaker = model.CircuitBreakerConfig{
+				FailureThreshold:	service.CircuitBreaker.FailureThreshold,
+				OpenTimeout:		time.Duration(service.CircuitBreaker.OpenTimeout) * time.Second,
+				HalfOpenMaxCalls:	service.CircuitBreaker.HalfOpenMaxCalls,
+			}
+		}
+		if service.Retry != nil {
+			apiServiceConfig.Retry = model.RetryConfig{
+				MaxAttempts:	service.Retry.MaxAttempts,
+				BaseDelay:		time.Duration(service.Retry.BaseDelay) * time.Millisecond,
+				MaxDelay:		time.Duration(service.Retry.MaxDelay) * time.Millisecond,
+			}
+		}
+		apiService[name] = apiServiceConfig
 	}
-	if os.Getenv("METHOD_SERVICE_02") !=  "" {
-		apiService02.Method = os.Getenv("METHOD_SERVICE_02")
-	}
-	if os.Getenv("NAME_SERVICE_02") !=  "" {
-		apiService02.Name = os.Getenv("NAME_SERVICE_02")
-	}
-	if os.Getenv("HOST_SERVICE_02") !=  "" {
-		apiService02.HostName = os.Getenv("HOST_SERVICE_02")
-	}
-	if os.Getenv("CLIENT_HTTP_TIMEOUT_02") !=  "" {
-		intVar, _ := strconv.Atoi(os.Getenv("CLIENT_HTTP_TIMEOUT_02"))	
-		apiService02.HttpTimeout = time.Duration(intVar) * time.Second
-	} else {
-		apiService02.HttpTimeout =(5 * time.Second) // default
-	}
-	apiService = append(apiService, apiService02)
 
-	var apiService03 model.ApiService
-	if os.Getenv("URL_SERVICE_03") !=  "" {
-		apiService03.Url = os.Getenv("URL_SERVICE_03")
-	}
-	if os.Getenv("X_APIGW_API_ID_SERVICE_03") !=  "" {
-		apiService03.XApigwApiId = os.Getenv("X_APIGW_API_ID_SERVICE_03")
-	}
-	if os.Getenv("METHOD_SERVICE_03") !=  "" {
-		apiService03.Method = os.Getenv("METHOD_SERVICE_03")
-	}
-	if os.Getenv("NAME_SERVICE_03") !=  "" {
-		apiService03.Name = os.Getenv("NAME_SERVICE_03")
-	}
-	if os.Getenv("HOST_SERVICE_03") !=  "" {
-		apiService03.HostName = os.Getenv("HOST_SERVICE_03")
+	// legacy slots
+	for i := 0; ; i++ {
+		name := os.Getenv(fmt.Sprintf("NAME_SERVICE_%02d", i))
+		url := os.Getenv(fmt.Sprintf("URL_SERVICE_%02d", i))
+		if name == "" && url == "" {
+			break
+		}
+		if name == "" {
+			errs = append(errs, fmt.Errorf("NAME_SERVICE_%02d is required when URL_SERVICE_%02d is set", i, i))
+			continue
+		}
+
+		service := apiService[name]
+		service.Name = name
+		if url != "" {
+			service.Url = url
+		}
+		if os.Getenv(fmt.Sprintf("X_APIGW_API_ID_SERVICE_%02d", i)) !=  "" {
+			service.XApigwApiId = os.Getenv(fmt.Sprintf("X_APIGW_API_ID_SERVICE_%02d", i))
+		}
+		if os.Getenv(fmt.Sprintf("METHOD_SERVICE_%02d", i)) !=  "" {
+			service.Method = os.Getenv(fmt.Sprintf("METHOD_SERVICE_%02d", i))
+		}
+		if os.Getenv(fmt.Sprintf("HOST_SERVICE_%02d", i)) !=  "" {
+			service.HostName = os.Getenv(fmt.Sprintf("HOST_SERVICE_%02d", i))
+		}
+		timeout := 0
+		if err := getEnvInt(fmt.Sprintf("CLIENT_HTTP_TIMEOUT_%02d", i), &timeout); err != nil {
+			errs = append(errs, err)
+		} else if timeout != 0 {
+			service.HttpTimeout = time.Duration(timeout) * time.Second
+		}
+		apiService[name] = service
 	}
-	if os.Getenv("CLIENT_HTTP_TIMEOUT_03") !=  "" {
-		intVar, _ := strconv.Atoi(os.Getenv("CLIENT_HTTP_TIMEOUT_03"))	
-		apiService03.HttpTimeout = time.Duration(intVar) * time.Second
-	} else {
-		apiService03.HttpTimeout =(5 * time.Second) // default
+
+	// named overrides
+	for name, service := range apiService {
+		prefix := serviceEnvPrefix(name)
+
+		if os.Getenv(prefix + "_URL") !=  "" {
+			service.Url = os.Getenv(prefix + "_URL")
+		}
+		if os.Getenv(prefix + "_X_APIGW_API_ID") !=  "" {
+			service.XApigwApiId = os.Getenv(prefix + "_X_APIGW_API_ID")
+		}
+		if os.Getenv(prefix + "_METHOD") !=  "" {
+			service.Method = os.Getenv(prefix + "_METHOD")
+		}
+		if os.Getenv(prefix + "_HOST") !=  "" {
+			service.HostName = os.Getenv(prefix + "_HOST")
+		}
+		timeout := 0
+		if err := getEnvInt(prefix + "_HTTP_TIMEOUT", &timeout); err != nil {
+			errs = append(errs, err)
+		} else if timeout != 0 {
+			service.HttpTimeout = time.Duration(timeout) * time.Second
+		}
+
+		if err := getEnvInt(prefix + "_CB_FAILURE_THRESHOLD", &service.CircuitBreaker.FailureThreshold); err != nil {
+			errs = append(errs, err)
+		}
+		openTimeout := 0
+		if err := getEnvInt(prefix + "_CB_OPEN_TIMEOUT", &openTimeout); err != nil {
+			errs = append(errs, err)
+		} else if openTimeout != 0 {
+			service.CircuitBreaker.OpenTimeout = time.Duration(openTimeout) * time.Second
+		}
+		if err := getEnvInt(prefix + "_RETRY_MAX_ATTEMPTS", &service.Retry.MaxAttempts); err != nil {
+			errs = append(errs, err)
+		}
+
+		// defaults
+		if service.HttpTimeout == 0 {
+			service.HttpTimeout = (5 * time.Second)
+		}
+		if service.CircuitBreaker.FailureThreshold == 0 {
+			service.CircuitBreaker.FailureThreshold = 5
+		}
+		if service.CircuitBreaker.OpenTimeout == 0 {
+			service.CircuitBreaker.OpenTimeout = (30 * time.Second)
+		}
+		if service.CircuitBreaker.HalfOpenMaxCalls == 0 {
+			service.CircuitBreaker.HalfOpenMaxCalls = 1
+		}
+		if service.Retry.MaxAttempts == 0 {
+			service.Retry.MaxAttempts = 3
+		}
+		if service.Retry.BaseDelay == 0 {
+			service.Retry.BaseDelay = (100 * time.Millisecond)
+		}
+		if service.Retry.MaxDelay == 0 {
+			service.Retry.MaxDelay = (1 * time.Second)
+		}
+		apiService[name] = service
 	}
-	apiService = append(apiService, apiService03)
 
-	return apiService
+	return apiService, errors.Join(errs...)
 }
\ No newline at end of file
@@ -0,0 +1,37 @@
+package configuration
+
+import(
+	"os"
+	"fmt"
+
+	"github.com/joho/godotenv"
+
+	"github.com/go-card/internal/core/iso8583"
+)
+
+// About get the field spec of the iso 8583 server, the file ISO8583_SPEC_FILE (json) or the default spec
+func GetIso8583SpecEnv() (*iso8583.Spec, error) {
+	childLogger.Info().Str("func","GetIso8583SpecEnv").Send()
+
+	err := godotenv.Load(".env")
+	if err != nil {
+		childLogger.Info().Err(err).Send()
+	}
+
+	spec := iso8583.DefaultSpec()
+	if os.Getenv("ISO8583_SPEC_FILE") !=  "" {
+		spec, err = iso8583.LoadSpec(os.Getenv("ISO8583_SPEC_FILE"))
+		if err != nil {
+			return nil, fmt.Errorf("iso 8583 spec file %s: %w", os.Getenv("ISO8583_SPEC_FILE"), err)
+		}
+	}
+
+	if err := spec.Validate(); err != nil {
+		return nil, fmt.Errorf("iso 8583 spec: %w", err)
+	}
+	if err := spec.Check(iso8583.RequiredFields); err != nil {
+		return nil, fmt.Errorf("iso 8583 spec: %w", err)
+	}
+
+	return spec, nil
+}
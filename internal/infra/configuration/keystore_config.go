@@ -0,0 +1,66 @@
+package configuration
+
+import(
+	"os"
+	"fmt"
+	"strings"
+	"path/filepath"
+	"encoding/hex"
+
+	"github.com/joho/godotenv"
+
+	"github.com/go-card/internal/core/hsm"
+)
+
+// About get the key file of the software HSM and its master key
+// The key file (HSM_KEY_FILE, default HSM_KEY_PATH/keys.json) holds the keys encrypted with the master key,
+// the master key (hex, 32 bytes) is read from the file master_key of HSM_KEY_PATH (default /var/pod/secret, the pod secret)
+// The keys are never part of the AppServer, so they are never logged
+func GetKeyFileEnv() (string, []byte, error) {
+	childLogger.Info().Str("func","GetKeyFileEnv").Send()
+
+	err := godotenv.Load(".env")
+	if err != nil {
+		childLogger.Info().Err(err).Send()
+	}
+
+	keyPath := "/var/pod/secret" // default
+	if os.Getenv("HSM_KEY_PATH") !=  "" {
+		keyPath = os.Getenv("HSM_KEY_PATH")
+	}
+	keyFile := filepath.Join(keyPath, "keys.json") // default
+	if os.Getenv("HSM_KEY_FILE") !=  "" {
+		keyFile = os.Getenv("HSM_KEY_FILE")
+	}
+
+	file, err := os.ReadFile(filepath.Join(keyPath, "master_key"))
+	if err != nil {
+		return keyFile, nil, fmt.Errorf("master key: %w", err)
+	}
+	masterKey, err := hex.DecodeString(strings.TrimSpace(string(file)))
+	if err != nil {
+		return keyFile, nil, fmt.Errorf("master key must be hex encoded")
+	}
+
+	return keyFile, masterKey, nil
+}
+
+// About load the key store of the software HSM, every required key must have an active version
+func GetKeyStoreEnv() (*hsm.SoftwareKeyStore, []byte, error) {
+	childLogger.Info().Str("func","GetKeyStoreEnv").Send()
+
+	keyFile, masterKey, err := GetKeyFileEnv()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	keyStore, err := hsm.LoadKeyFile(keyFile, masterKey)
+	if err != nil {
+		return nil, nil, fmt.Errorf("key file %s: %w", keyFile, err)
+	}
+	if err := keyStore.Check(hsm.RequiredKeys); err != nil {
+		return nil, nil, fmt.Errorf("key file %s: %w", keyFile, err)
+	}
+
+	return keyStore, masterKey, nil
+}
@@ -2,6 +2,7 @@ package configuration
 
 import(
 	"os"
+	"errors"
 	"strconv"
 	"net"
 	"context"
@@ -12,9 +13,10 @@ import(
 	"github.com/aws/aws-sdk-go-v2/config"
 
 	"github.com/go-card/internal/core/model"
+	"github.com/go-card/internal/infra/logger"
 )
 
-var childLogger = log.With().Str("component","go-card").Str("package","internal.infra.configuration").Logger()
+var childLogger = logger.With().Str("component","go-card").Str("package","internal.infra.configuration").Logger()
 
 // Load the Pod configuration
 func GetInfoPod() (	model.InfoPod, model.Server) {
@@ -26,7 +28,6 @@ func GetInfoPod() (	model.InfoPod, model.Server) {
 	}
 
 	var infoPod 	model.InfoPod
-	var server		model.Server
 
 	if os.Getenv("API_VERSION") !=  "" {
 		infoPod.ApiVersion = os.Getenv("API_VERSION")
@@ -95,20 +96,52 @@ func GetInfoPod() (	model.InfoPod, model.Server) {
 		infoPod.AvailabilityZone = "-"
 	}
 
-	if os.Getenv("PORT") !=  "" {
-		intVar, _ := strconv.Atoi(os.Getenv("PORT"))
-		server.Port = intVar
+	server, err := GetServerEnv()
+	if err != nil {
+		childLogger.Error().Err(err).Send()
+		os.Exit(3)
 	}
+	
+	return infoPod, server
+}
+
+// Load the http server configuration
+func GetServerEnv() (model.Server, error) {
+	childLogger.Info().Str("func","GetServerEnv").Send()
+
+	err := godotenv.Load(".env")
+	if err != nil {
+		childLogger.Info().Err(err).Send()
+	}
+
+	var server	model.Server
+	var errs	[]error
 
 	server.ReadTimeout = 60
 	server.WriteTimeout = 60
 	server.IdleTimeout = 60
 	server.CtxTimeout = 5 // default
+	server.LogLevel = "info" // default
 
-	if os.Getenv("CTX_TIMEOUT") !=  "" {
-		intVar, _ := strconv.Atoi(os.Getenv("CTX_TIMEOUT"))
-		server.CtxTimeout = intVar
+	if err := getEnvInt("PORT", &server.Port); err != nil {
+		errs = append(errs, err)
 	}
-	
-	return infoPod, server
+	if err := getEnvInt("CTX_TIMEOUT", &server.CtxTimeout); err != nil {
+		errs = append(errs, err)
+	}
+	// 0 disables the grpc server
+	if err := getEnvInt("GRPC_PORT", &server.GrpcPort); err != nil {
+		errs = append(errs, err)
+	}
+	// 0 (default) disables the iso 8583 server
+	if err := getEnvInt("ISO8583_PORT", &server.IsoPort); err != nil {
+		errs = append(errs, err)
+	}
+	if os.Getenv("LOG_LEVEL") !=  "" {
+		server.LogLevel = os.Getenv("LOG_LEVEL")
+	}
+	// the bearer token of the admin routes, empty disables them
+	server.AdminToken = os.Getenv("ADMIN_TOKEN")
+
+	return server, errors.Join(errs...)
 }
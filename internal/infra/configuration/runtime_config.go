@@ -0,0 +1,396 @@
+package configuration
+
+import(
+	"os"
+	"fmt"
+	"errors"
+	"strings"
+	"strconv"
+	"reflect"
+	"time"
+	"net/url"
+	"net/http"
+
+	"github.com/joho/godotenv"
+	"github.com/rs/zerolog"
+
+	"github.com/go-card/internal/core/model"
+)
+
+// About get the rate limit from the config file and env var
+func GetRateLimitEnv() (model.RateLimit, error) {
+	childLogger.Info().Str("func","GetRateLimitEnv").Send()
+
+	err := godotenv.Load(".env")
+	if err != nil {
+		childLogger.Info().Err(err).Send()
+	}
+
+	config, err := loadConfigFile()
+	if err != nil {
+		return model.RateLimit{}, err
+	}
+
+	var rateLimit model.RateLimit
+	var errs []error
+
+	if config.RateLimit != nil {
+		rateLimit.RequestsPerSecond = config.RateLimit.RequestsPerSecond
+		rateLimit.Burst = config.RateLimit.Burst
+	}
+	if err := getEnvInt("RATE_LIMIT_RPS", &rateLimit.RequestsPerSecond); err != nil {
+		errs = append(errs, err)
+	}
+	if err := getEnvInt("RATE_LIMIT_BURST", &rateLimit.Burst); err != nil {
+		errs = append(errs, err)
+	}
+	if rateLimit.Burst == 0 {
+		rateLimit.Burst = rateLimit.RequestsPerSecond // default
+	}
+
+	return rateLimit, errors.Join(errs...)
+}
+
+// About get the account cache config from the config file and env var
+func GetAccountCacheEnv() (model.CacheConfig, error) {
+	childLogger.Info().Str("func","GetAccountCacheEnv").Send()
+
+	err := godotenv.Load(".env")
+	if err != nil {
+		childLogger.Info().Err(err).Send()
+	}
+
+	config, err := loadConfigFile()
+	if err != nil {
+		return model.CacheConfig{}, err
+	}
+
+	cacheConfig := model.CacheConfig{
+		Size: 	10000, // default
+		TTL: 	(1 * time.Hour), // default
+	}
+	var errs []error
+
+	if config.AccountCache != nil {
+		cacheConfig.Size = config.AccountCache.Size
+		cacheConfig.TTL = time.Duration(config.AccountCache.TTL) * time.Second
+	}
+	if err := getEnvInt("ACCOUNT_CACHE_SIZE", &cacheConfig.Size); err != nil {
+		errs = append(errs, err)
+	}
+	ttl := 0
+	if err := getEnvInt("ACCOUNT_CACHE_TTL", &ttl); err != nil {
+		errs = append(errs, err)
+	} else if ttl != 0 {
+		cacheConfig.TTL = time.Duration(ttl) * time.Second
+	}
+
+	return cacheConfig, errors.Join(errs...)
+}
+
+// About get the expiry job config from the config file and env var
+func GetExpiryJobEnv() (model.ExpiryJob, error) {
+	childLogger.Info().Str("func","GetExpiryJobEnv").Send()
+
+	err := godotenv.Load(".env")
+	if err != nil {
+		childLogger.Info().Err(err).Send()
+	}
+
+	config, err := loadConfigFile()
+	if err != nil {
+		return model.ExpiryJob{}, err
+	}
+
+	expiryJob := model.ExpiryJob{
+		Interval:		(1 * time.Hour), // default
+		RenewalDays:	60, // default
+		ChunkSize:		500, // default
+	}
+	var errs []error
+
+	if config.ExpiryJob != nil {
+		if config.ExpiryJob.Interval != nil {
+			expiryJob.Interval = time.Duration(*config.ExpiryJob.Interval) * time.Second
+		}
+		if config.ExpiryJob.RenewalDays != 0 {
+			expiryJob.RenewalDays = config.ExpiryJob.RenewalDays
+		}
+		if config.ExpiryJob.ChunkSize != 0 {
+			expiryJob.ChunkSize = config.ExpiryJob.ChunkSize
+		}
+	}
+	interval := int(expiryJob.Interval / time.Second)
+	if err := getEnvInt("EXPIRY_JOB_INTERVAL", &interval); err != nil {
+		errs = append(errs, err)
+	}
+	expiryJob.Interval = time.Duration(interval) * time.Second
+	if err := getEnvInt("EXPIRY_RENEWAL_DAYS", &expiryJob.RenewalDays); err != nil {
+		errs = append(errs, err)
+	}
+	if err := getEnvInt("EXPIRY_CHUNK_SIZE", &expiryJob.ChunkSize); err != nil {
+		errs = append(errs, err)
+	}
+
+	return expiryJob, errors.Join(errs...)
+}
+
+// About get the feature flags from the config file and env var (ex: FEATURE_FLAGS=flag_a=true,flag_b=false)
+func GetFeatureFlagEnv() (map[string]bool, error) {
+	childLogger.Info().Str("func","GetFeatureFlagEnv").Send()
+
+	err := godotenv.Load(".env")
+	if err != nil {
+		childLogger.Info().Err(err).Send()
+	}
+
+	config, err := loadConfigFile()
+	if err != nil {
+		return nil, err
+	}
+
+	featureFlags := map[string]bool{}
+	for name, value := range config.FeatureFlags {
+		featureFlags[name] = value
+	}
+
+	if os.Getenv("FEATURE_FLAGS") ==  "" {
+		return featureFlags, nil
+	}
+
+	var errs []error
+	for _, item := range strings.Split(os.Getenv("FEATURE_FLAGS"), ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		name, value, found := strings.Cut(item, "=")
+		if !found {
+			featureFlags[name] = true
+			continue
+		}
+		enabled, err := strconv.ParseBool(strings.TrimSpace(value))
+		if err != nil {
+			errs = append(errs, fmt.Errorf("FEATURE_FLAGS %q must be a boolean", item))
+			continue
+		}
+		featureFlags[strings.TrimSpace(name)] = enabled
+	}
+
+	return featureFlags, errors.Join(errs...)
+}
+
+// About re-read the .env file and build the runtime configuration
+// Only the fields that can be changed safely at runtime are refreshed,
+// everything else (port, database, otel) is kept from the current configuration
+func ReloadAppServer(current model.AppServer) (model.AppServer, error) {
+	childLogger.Info().Str("func","ReloadAppServer").Send()
+
+	// the os env var already set must be overwritten by the new .env values
+	err := godotenv.Overload(".env")
+	if err != nil {
+		childLogger.Info().Err(err).Send()
+	}
+
+	reloaded := current
+	var errs []error
+
+	newServer, err := GetServerEnv()
+	if err != nil {
+		errs = append(errs, err)
+	}
+	server := *current.Server
+	server.CtxTimeout = newServer.CtxTimeout
+	server.LogLevel = newServer.LogLevel
+	reloaded.Server = &server
+
+	reloaded.ApiService, err = GetEndpointEnv()
+	if err != nil {
+		errs = append(errs, err)
+	}
+
+	rateLimit, err := GetRateLimitEnv()
+	if err != nil {
+		errs = append(errs, err)
+	}
+	reloaded.RateLimit = &rateLimit
+
+	reloaded.FeatureFlags, err = GetFeatureFlagEnv()
+	if err != nil {
+		errs = append(errs, err)
+	}
+
+	return reloaded, errors.Join(errs...)
+}
+
+// About load and validate the configuration without starting the server (go-card config validate)
+func ValidateConfig() error {
+	childLogger.Info().Str("func","ValidateConfig").Send()
+
+	var appServer model.AppServer
+	var errs []error
+
+	server, err := GetServerEnv()
+	if err != nil {
+		errs = append(errs, err)
+	}
+	appServer.Server = &server
+
+	appServer.ApiService, err = GetEndpointEnv()
+	if err != nil {
+		errs = append(errs, err)
+	}
+
+	rateLimit, err := GetRateLimitEnv()
+	if err != nil {
+		errs = append(errs, err)
+	}
+	appServer.RateLimit = &rateLimit
+
+	appServer.FeatureFlags, err = GetFeatureFlagEnv()
+	if err != nil {
+		errs = append(errs, err)
+	}
+
+	accountCache, err := GetAccountCacheEnv()
+	if err != nil {
+		errs = append(errs, err)
+	}
+	appServer.AccountCache = &accountCache
+
+	expiryJob, err := GetExpiryJobEnv()
+	if err != nil {
+		errs = append(errs, err)
+	}
+	appServer.ExpiryJob = &expiryJob
+
+	if _, err := GetIso8583SpecEnv(); err != nil {
+		errs = append(errs, err)
+	}
+
+	// the secrets are not mounted in the CI, the key file is only checked when the master key exists
+	_, masterKey, err := GetKeyFileEnv()
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		childLogger.Warn().Err(err).Msg("key store not checked")
+	case err != nil:
+		errs = append(errs, err)
+	default:
+		clear(masterKey)
+		if _, _, err := GetKeyStoreEnv(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	errs = append(errs, ValidateAppServer(appServer))
+
+	return errors.Join(errs...)
+}
+
+// About validate the runtime configuration
+func ValidateAppServer(appServer model.AppServer) error {
+	childLogger.Info().Str("func","ValidateAppServer").Send()
+
+	var errs []error
+
+	if appServer.Server != nil {
+		if appServer.Server.Port <= 0 {
+			errs = append(errs, fmt.Errorf("PORT must be greater than 0, got %d", appServer.Server.Port))
+		}
+		if appServer.Server.CtxTimeout <= 0 {
+			errs = append(errs, fmt.Errorf("CTX_TIMEOUT must be greater than 0, got %d", appServer.Server.CtxTimeout))
+		}
+		if _, err := zerolog.ParseLevel(appServer.Server.LogLevel); err != nil {
+			errs = append(errs, fmt.Errorf("LOG_LEVEL %q is invalid", appServer.Server.LogLevel))
+		}
+	}
+
+	for name, apiService := range appServer.ApiService {
+		if apiService.Url == "" {
+			errs = append(errs, fmt.Errorf("api_services.%s.url is required", name))
+		} else if _, err := url.ParseRequestURI(apiService.Url); err != nil {
+			errs = append(errs, fmt.Errorf("api_services.%s.url %q is invalid", name, apiService.Url))
+		}
+		switch apiService.Method {
+		case http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		default:
+			errs = append(errs, fmt.Errorf("api_services.%s.method %q is invalid", name, apiService.Method))
+		}
+		if apiService.HttpTimeout <= 0 {
+			errs = append(errs, fmt.Errorf("api_services.%s.http_timeout must be greater than 0", name))
+		}
+		if apiService.CircuitBreaker.FailureThreshold <= 0 {
+			errs = append(errs, fmt.Errorf("api_services.%s.circuit_breaker.failure_threshold must be greater than 0", name))
+		}
+		if apiService.CircuitBreaker.OpenTimeout <= 0 {
+			errs = append(errs, fmt.Errorf("api_services.%s.circuit_breaker.open_timeout must be greater than 0", name))
+		}
+		if apiService.CircuitBreaker.HalfOpenMaxCalls <= 0 {
+			errs = append(errs, fmt.Errorf("api_services.%s.circuit_breaker.half_open_max_calls must be greater than 0", name))
+		}
+		if apiService.Retry.MaxAttempts <= 0 {
+			errs = append(errs, fmt.Errorf("api_services.%s.retry.max_attempts must be greater than 0", name))
+		}
+		if apiService.Retry.BaseDelay <= 0 || apiService.Retry.MaxDelay < apiService.Retry.BaseDelay {
+			errs = append(errs, fmt.Errorf("api_services.%s.retry.base_delay_ms must be greater than 0 and lower than max_delay_ms", name))
+		}
+	}
+
+	if appServer.AccountCache != nil {
+		if appServer.AccountCache.Size <= 0 {
+			errs = append(errs, fmt.Errorf("account_cache.size must be greater than 0, got %d", appServer.AccountCache.Size))
+		}
+		if appServer.AccountCache.TTL <= 0 {
+			errs = append(errs, fmt.Errorf("account_cache.ttl must be greater than 0"))
+		}
+	}
+
+	if appServer.ExpiryJob != nil {
+		if appServer.ExpiryJob.Interval < 0 {
+			errs = append(errs, fmt.Errorf("EXPIRY_JOB_INTERVAL must not be negative"))
+		}
+		if appServer.ExpiryJob.RenewalDays < 0 {
+			errs = append(errs, fmt.Errorf("EXPIRY_RENEWAL_DAYS must not be negative, got %d", appServer.ExpiryJob.RenewalDays))
+		}
+		if appServer.ExpiryJob.ChunkSize <= 0 {
+			errs = append(errs, fmt.Errorf("EXPIRY_CHUNK_SIZE must be greater than 0, got %d", appServer.ExpiryJob.ChunkSize))
+		}
+	}
+
+	if appServer.RateLimit != nil {
+		if appServer.RateLimit.RequestsPerSecond < 0 {
+			errs = append(errs, fmt.Errorf("RATE_LIMIT_RPS must not be negative, got %d", appServer.RateLimit.RequestsPerSecond))
+		}
+		if appServer.RateLimit.RequestsPerSecond > 0 && appServer.RateLimit.Burst <= 0 {
+			errs = append(errs, fmt.Errorf("RATE_LIMIT_BURST must be greater than 0, got %d", appServer.RateLimit.Burst))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// About list the differences of the runtime configuration
+func DiffAppServer(old model.AppServer, new model.AppServer) []string {
+	var diff []string
+
+	compare := func(field string, oldValue any, newValue any) {
+		if !reflect.DeepEqual(oldValue, newValue) {
+			diff = append(diff, fmt.Sprintf("%s: %v => %v", field, oldValue, newValue))
+		}
+	}
+
+	compare("server.ctxTimeout", old.Server.CtxTimeout, new.Server.CtxTimeout)
+	compare("server.logLevel", old.Server.LogLevel, new.Server.LogLevel)
+	compare("api_endpoints", old.ApiService, new.ApiService)
+	compare("rate_limit", derefRateLimit(old.RateLimit), derefRateLimit(new.RateLimit))
+	compare("feature_flags", old.FeatureFlags, new.FeatureFlags)
+
+	return diff
+}
+
+func derefRateLimit(rateLimit *model.RateLimit) model.RateLimit {
+	if rateLimit == nil {
+		return model.RateLimit{}
+	}
+	return *rateLimit
+}
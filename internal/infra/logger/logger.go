@@ -0,0 +1,152 @@
+package logger
+
+import (
+	"os"
+	"io"
+	"sync"
+	"time"
+	"context"
+	"encoding/json"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+
+	otellog "go.opentelemetry.io/otel/log"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// About the writer shared by all the loggers, the otel logger is attached at runtime (OTEL_LOGS=true)
+var writer = &otelWriter{out: os.Stderr}
+
+// The global logger must be replaced before the packages create their child loggers,
+// so every package builds its child logger with logger.With()
+func init() {
+	log.Logger = zerolog.New(writer).With().Timestamp().Logger().Hook(TraceHook{})
+}
+
+// About create a child logger context from the global logger
+func With() zerolog.Context {
+	return log.Logger.With()
+}
+
+// About inject the trace_id and span_id of the event context (event.Ctx(ctx))
+type TraceHook struct{}
+
+func (h TraceHook) Run(e *zerolog.Event, level zerolog.Level, msg string) {
+	spanContext := trace.SpanContextFromContext(e.GetCtx())
+	if spanContext.IsValid() {
+		e.Str("trace_id", spanContext.TraceID().String())
+		e.Str("span_id", spanContext.SpanID().String())
+	}
+}
+
+// About write the log records to the output and bridge them to the otel logger
+type otelWriter struct {
+	out		io.Writer
+	mutex	sync.RWMutex
+	logger	otellog.Logger
+}
+
+// About attach (or detach with nil) the otel logger
+func SetOtelLogger(logger otellog.Logger) {
+	writer.mutex.Lock()
+	defer writer.mutex.Unlock()
+	writer.logger = logger
+}
+
+func (w *otelWriter) Write(p []byte) (int, error) {
+	n, err := w.out.Write(p)
+
+	w.mutex.RLock()
+	logger := w.logger
+	w.mutex.RUnlock()
+
+	if logger != nil {
+		emit(logger, p)
+	}
+
+	return n, err
+}
+
+// About convert a zerolog json record into an otel log record
+func emit(logger otellog.Logger, p []byte) {
+	fields := map[string]interface{}{}
+	if err := json.Unmarshal(p, &fields); err != nil {
+		return
+	}
+
+	var record otellog.Record
+	record.SetObservedTimestamp(time.Now())
+	record.SetTimestamp(time.Now())
+
+	ctx := context.Background()
+	var traceID trace.TraceID
+	var spanID trace.SpanID
+
+	for key, value := range fields {
+		switch key {
+		case zerolog.TimestampFieldName:
+			if ts, ok := value.(string); ok {
+				if t, err := time.Parse(zerolog.TimeFieldFormat, ts); err == nil {
+					record.SetTimestamp(t)
+				}
+			}
+		case zerolog.LevelFieldName:
+			level, _ := value.(string)
+			record.SetSeverityText(level)
+			record.SetSeverity(severity(level))
+		case zerolog.MessageFieldName:
+			record.SetBody(otellog.StringValue(toString(value)))
+		case "trace_id":
+			traceID, _ = trace.TraceIDFromHex(toString(value))
+		case "span_id":
+			spanID, _ = trace.SpanIDFromHex(toString(value))
+		default:
+			record.AddAttributes(otellog.String(key, toString(value)))
+		}
+	}
+
+	// the sdk correlates the record with the span of the context
+	if traceID.IsValid() && spanID.IsValid() {
+		ctx = trace.ContextWithSpanContext(ctx, trace.NewSpanContext(trace.SpanContextConfig{
+			TraceID:	traceID,
+			SpanID:		spanID,
+			TraceFlags:	trace.FlagsSampled,
+			Remote:		true,
+		}))
+	}
+
+	logger.Emit(ctx, record)
+}
+
+func severity(level string) otellog.Severity {
+	switch level {
+	case zerolog.LevelTraceValue:
+		return otellog.SeverityTrace
+	case zerolog.LevelDebugValue:
+		return otellog.SeverityDebug
+	case zerolog.LevelInfoValue:
+		return otellog.SeverityInfo
+	case zerolog.LevelWarnValue:
+		return otellog.SeverityWarn
+	case zerolog.LevelErrorValue:
+		return otellog.SeverityError
+	case zerolog.LevelFatalValue:
+		return otellog.SeverityFatal
+	case zerolog.LevelPanicValue:
+		return otellog.SeverityFatal4
+	default:
+		return otellog.SeverityUndefined
+	}
+}
+
+func toString(value interface{}) string {
+	if s, ok := value.(string); ok {
+		return s
+	}
+	b, err := json.Marshal(value)
+	if err != nil {
+		return ""
+	}
+	return string(b)
+}
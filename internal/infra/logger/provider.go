@@ -0,0 +1,59 @@
+package logger
+
+import (
+	"context"
+
+	go_core_observ "github.com/eliezerraj/go-core/observability"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlplog/otlploggrpc"
+	"go.opentelemetry.io/otel/exporters/stdout/stdoutlog"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+	"go.opentelemetry.io/otel/sdk/resource"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+)
+
+// About create the otel LoggerProvider and attach it to the zerolog output
+// USE_STDOUT_TRACER_EXPORTER exports the logs to stdout, USE_OTLP_COLLECTOR exports them to OTEL_EXPORTER_OTLP_ENDPOINT
+func NewLoggerProvider(	ctx context.Context,
+						configOTEL *go_core_observ.ConfigOTEL,
+						infoTrace *go_core_observ.InfoTrace) (*sdklog.LoggerProvider, error) {
+	res, err := resource.New(ctx,
+		resource.WithSchemaURL(semconv.SchemaURL),
+		resource.WithAttributes(
+			semconv.ServiceNameKey.String(infoTrace.PodName),
+			semconv.ServiceVersionKey.String(infoTrace.PodVersion),
+			attribute.String("environment", infoTrace.Env),
+			attribute.String("account", infoTrace.AccountID),
+		),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	options := []sdklog.LoggerProviderOption{sdklog.WithResource(res)}
+
+	if configOTEL.UseStdoutTracerExporter {
+		exporter, err := stdoutlog.New()
+		if err != nil {
+			return nil, err
+		}
+		options = append(options, sdklog.WithProcessor(sdklog.NewBatchProcessor(exporter)))
+	}
+
+	if configOTEL.UseOtlpCollector {
+		exporter, err := otlploggrpc.New(ctx,
+			otlploggrpc.WithEndpoint(configOTEL.OtelExportEndpoint),
+			otlploggrpc.WithInsecure(),
+		)
+		if err != nil {
+			return nil, err
+		}
+		options = append(options, sdklog.WithProcessor(sdklog.NewBatchProcessor(exporter)))
+	}
+
+	provider := sdklog.NewLoggerProvider(options...)
+	SetOtelLogger(provider.Logger(infoTrace.PodName))
+
+	return provider, nil
+}
@@ -0,0 +1,35 @@
+package server
+
+import (
+	"fmt"
+	"strings"
+	"net/http"
+	"crypto/subtle"
+
+	"github.com/go-card/internal/core/erro"
+	"github.com/go-card/internal/adapter/api"
+)
+
+// About the admin routes auth, a bearer token (ADMIN_TOKEN) compared in constant time
+// The admin routes are disabled (403) when there is no token
+func AdminAuth(token string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			trace_id := fmt.Sprintf("%v", req.Context().Value("trace-request-id"))
+
+			if token == "" {
+				childLogger.Warn().Str("func","AdminAuth").Str("path", req.URL.Path).Msg("admin routes disabled, no admin token")
+				api.WriteProblem(rw, req, api.NewProblem(trace_id, erro.ErrHTTPForbiden))
+				return
+			}
+
+			bearer, found := strings.CutPrefix(req.Header.Get("Authorization"), "Bearer ")
+			if !found || subtle.ConstantTimeCompare([]byte(bearer), []byte(token)) != 1 {
+				childLogger.Warn().Str("func","AdminAuth").Str("path", req.URL.Path).Msg("admin request not authorized")
+				api.WriteProblem(rw, req, api.NewProblem(trace_id, erro.ErrUnauthorized))
+				return
+			}
+			next.ServeHTTP(rw, req)
+		})
+	}
+}
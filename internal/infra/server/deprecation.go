@@ -0,0 +1,77 @@
+package server
+
+import (
+	"time"
+	"strconv"
+	"strings"
+	"net/url"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+var (
+	// the legacy (unversioned) routes are deprecated since this date and removed after the sunset
+	legacyDeprecatedAt	= time.Date(2026, time.October, 19, 0, 0, 0, 0, time.UTC)
+	legacySunsetAt		= time.Date(2027, time.April, 30, 0, 0, 0, 0, time.UTC)
+)
+
+// About mark the legacy routes as deprecated and count their usage
+type Deprecation struct {
+	usage	metric.Int64Counter
+}
+
+// About create the deprecation middleware
+func NewDeprecation() *Deprecation {
+	childLogger.Info().Str("func","NewDeprecation").Send()
+
+	deprecation := &Deprecation{}
+
+	var err error
+	deprecation.usage, err = otel.Meter("go-card").Int64Counter(
+		"legacy_route_request",
+		metric.WithDescription("Requests on the deprecated (unversioned) routes"),
+	)
+	if err != nil {
+		childLogger.Error().Err(err).Msg("error register legacy_route_request metric")
+	}
+
+	return deprecation
+}
+
+// About add the Deprecation (RFC 9745), Sunset (RFC 8594) and successor Link headers
+// The path variables of the successor are filled with the ones of the legacy route
+// a variable the legacy route doesn't have in its path (ex: the card of POST /atc is in the body) stays a template
+func (d *Deprecation) Middleware(successor string) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			route := req.URL.Path
+			if current := mux.CurrentRoute(req); current != nil {
+				if template, err := current.GetPathTemplate(); err == nil {
+					route = template
+				}
+			}
+
+			if d.usage != nil {
+				d.usage.Add(req.Context(), 1, metric.WithAttributes(
+					attribute.String("route", route),
+					attribute.String("method", req.Method),
+				))
+			}
+
+			rw.Header().Set("Deprecation", "@" + strconv.FormatInt(legacyDeprecatedAt.Unix(), 10))
+			rw.Header().Set("Sunset", legacySunsetAt.Format(http.TimeFormat))
+			link := successor
+			for name, value := range mux.Vars(req) {
+				link = strings.ReplaceAll(link, "{" + name + "}", url.PathEscape(value))
+			}
+			rw.Header().Add("Link", "<" + link + ">; rel=\"successor-version\"")
+
+			next.ServeHTTP(rw, req)
+		})
+	}
+}
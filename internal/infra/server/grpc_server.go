@@ -0,0 +1,79 @@
+package server
+
+import (
+	"net"
+	"strconv"
+
+	"github.com/go-card/internal/core/model"
+	card_grpc "github.com/go-card/internal/adapter/grpc"
+
+	cardv1 "github.com/go-card/proto/card/v1"
+
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
+)
+
+type GrpcServer struct {
+	server			*model.Server
+	grpcServer		*grpc.Server
+	healthServer	*health.Server
+}
+
+// About create new grpc server with the health and reflection services, it runs on its own port (GRPC_PORT)
+// The otel stats handler traces the calls with the same propagator than the http server
+func NewGrpcAppServer(server *model.Server, cardServer *card_grpc.CardServer) *GrpcServer {
+	childLogger.Info().Str("func","NewGrpcAppServer").Send()
+
+	grpcServer := grpc.NewServer(
+		grpc.StatsHandler(otelgrpc.NewServerHandler()),
+		grpc.ChainUnaryInterceptor(card_grpc.RequestIDInterceptor),
+	)
+
+	cardv1.RegisterCardServiceServer(grpcServer, cardServer)
+
+	healthServer := health.NewServer()
+	healthServer.SetServingStatus(cardv1.CardService_ServiceDesc.ServiceName, grpc_health_v1.HealthCheckResponse_SERVING)
+	grpc_health_v1.RegisterHealthServer(grpcServer, healthServer)
+
+	reflection.Register(grpcServer)
+
+	return &GrpcServer{
+		server: server,
+		grpcServer: grpcServer,
+		healthServer: healthServer,
+	}
+}
+
+// About start the grpc server, it returns when the server is stopped
+func (g *GrpcServer) StartGrpcAppServer() {
+	childLogger.Info().Str("func","StartGrpcAppServer").Send()
+
+	if g.server.GrpcPort <= 0 {
+		childLogger.Info().Msg("grpc server disabled (GRPC_PORT)")
+		return
+	}
+
+	listener, err := net.Listen("tcp", ":" + strconv.Itoa(g.server.GrpcPort))
+	if err != nil {
+		childLogger.Error().Err(err).Msg("error listen grpc port")
+		return
+	}
+
+	childLogger.Info().Str("Grpc Port", strconv.Itoa(g.server.GrpcPort)).Send()
+
+	err = g.grpcServer.Serve(listener)
+	if err != nil {
+		childLogger.Error().Err(err).Msg("canceling grpc server !!!")
+	}
+}
+
+// About stop the grpc server, the running calls are finished
+func (g *GrpcServer) StopGrpcAppServer() {
+	childLogger.Info().Str("func","StopGrpcAppServer").Send()
+
+	g.healthServer.Shutdown()
+	g.grpcServer.GracefulStop()
+}
@@ -0,0 +1,163 @@
+package server
+
+import (
+	"io"
+	"net"
+	"sync"
+	"time"
+	"errors"
+	"context"
+	"strconv"
+
+	"github.com/go-card/internal/core/model"
+	iso8583_adapter "github.com/go-card/internal/adapter/iso8583"
+	core_iso8583 "github.com/go-card/internal/core/iso8583"
+)
+
+// the messages of a connection authorized at the same time, the next message is read when one is finished
+const maxConnMessages = 32
+
+type IsoServer struct {
+	server		*model.Server
+	spec		*core_iso8583.Spec
+	handler		*iso8583_adapter.AuthorizationHandler
+	listener	net.Listener
+	mutex		sync.Mutex
+	conns		map[net.Conn]struct{}
+	wg			sync.WaitGroup
+}
+
+// About create new iso 8583 server, it runs on its own port (ISO8583_PORT) with the framing of the spec
+func NewIsoAppServer(	server *model.Server,
+						spec *core_iso8583.Spec,
+						handler *iso8583_adapter.AuthorizationHandler) *IsoServer {
+	childLogger.Info().Str("func","NewIsoAppServer").Send()
+
+	return &IsoServer{
+		server: server,
+		spec: spec,
+		handler: handler,
+		conns: map[net.Conn]struct{}{},
+	}
+}
+
+// About start the iso 8583 server, it returns when the server is stopped
+func (i *IsoServer) StartIsoAppServer() {
+	childLogger.Info().Str("func","StartIsoAppServer").Send()
+
+	if i.server.IsoPort <= 0 {
+		childLogger.Info().Msg("iso 8583 server disabled (ISO8583_PORT)")
+		return
+	}
+
+	listener, err := net.Listen("tcp", ":" + strconv.Itoa(i.server.IsoPort))
+	if err != nil {
+		childLogger.Error().Err(err).Msg("error listen iso 8583 port")
+		return
+	}
+	i.mutex.Lock()
+	i.listener = listener
+	i.mutex.Unlock()
+
+	childLogger.Info().Str("Iso8583 Port", strconv.Itoa(i.server.IsoPort)).Send()
+
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			if !errors.Is(err, net.ErrClosed) {
+				childLogger.Error().Err(err).Msg("canceling iso 8583 server !!!")
+			}
+			return
+		}
+		i.mutex.Lock()
+		i.conns[conn] = struct{}{}
+		i.mutex.Unlock()
+
+		i.wg.Add(1)
+		go i.serve(conn)
+	}
+}
+
+// About serve the messages of a connection, the messages are authorized concurrently
+// and the responses are written as they are ready (the acquirer matches them by the DE11/DE37)
+// A message that can't be unpacked has no response, the connection is kept
+// At most maxConnMessages are authorized at the same time, a panic of a message is logged and it has no response
+func (i *IsoServer) serve(conn net.Conn) {
+	defer i.wg.Done()
+	defer func() {
+		i.mutex.Lock()
+		delete(i.conns, conn)
+		i.mutex.Unlock()
+		conn.Close()
+	}()
+
+	childLogger.Info().Str("remote_addr", conn.RemoteAddr().String()).Msg("iso 8583 connection opened")
+
+	var writeMutex sync.Mutex
+	var messages sync.WaitGroup
+	running := make(chan struct{}, maxConnMessages)
+	defer messages.Wait()
+
+	for {
+		conn.SetReadDeadline(time.Now().Add(time.Duration(i.server.IdleTimeout) * time.Second))
+		data, err := i.spec.ReadFrame(conn)
+		if err != nil {
+			if !errors.Is(err, io.EOF) && !errors.Is(err, net.ErrClosed) {
+				childLogger.Info().Err(err).Str("remote_addr", conn.RemoteAddr().String()).Msg("iso 8583 connection closed")
+			}
+			return
+		}
+
+		request, err := i.spec.Unpack(data)
+		if err != nil {
+			childLogger.Error().Err(err).Str("remote_addr", conn.RemoteAddr().String()).Msg("error unpack iso 8583 message")
+			continue
+		}
+
+		running <- struct{}{}
+		messages.Add(1)
+		go func() {
+			defer messages.Done()
+			defer func() { <-running }()
+			defer func() {
+				if r := recover(); r != nil {
+					childLogger.Error().Interface("panic", r).Str("remote_addr", conn.RemoteAddr().String()).Str("mti", request.MTI).Msg("panic handle iso 8583 message")
+				}
+			}()
+
+			response, err := i.handler.Authorize(context.Background(), request)
+			if err != nil {
+				childLogger.Error().Err(err).Str("remote_addr", conn.RemoteAddr().String()).Msg("error handle iso 8583 message")
+				return
+			}
+			data, err := i.spec.Pack(response)
+			if err != nil {
+				childLogger.Error().Err(err).Msg("error pack iso 8583 message")
+				return
+			}
+
+			writeMutex.Lock()
+			defer writeMutex.Unlock()
+			conn.SetWriteDeadline(time.Now().Add(time.Duration(i.server.WriteTimeout) * time.Second))
+			if err := i.spec.WriteFrame(conn, data); err != nil {
+				childLogger.Error().Err(err).Str("remote_addr", conn.RemoteAddr().String()).Msg("error write iso 8583 message")
+			}
+		}()
+	}
+}
+
+// About stop the iso 8583 server, no message is read anymore and the running authorizations are finished
+func (i *IsoServer) StopIsoAppServer() {
+	childLogger.Info().Str("func","StopIsoAppServer").Send()
+
+	i.mutex.Lock()
+	if i.listener != nil {
+		i.listener.Close()
+	}
+	for conn := range i.conns {
+		conn.SetReadDeadline(time.Now())
+	}
+	i.mutex.Unlock()
+
+	i.wg.Wait()
+}
@@ -0,0 +1,77 @@
+package server
+
+import (
+	"sync"
+	"time"
+	"net/http"
+	"fmt"
+
+	"github.com/go-card/internal/core/model"
+	"github.com/go-card/internal/core/erro"
+	"github.com/go-card/internal/adapter/api"
+)
+
+// About a token bucket rate limiter, the limits can be changed at runtime
+type RateLimiter struct {
+	mutex		sync.Mutex
+	rate		float64
+	burst		float64
+	tokens		float64
+	lastRefill	time.Time
+}
+
+// About create a rate limiter
+func NewRateLimiter(rateLimit model.RateLimit) *RateLimiter {
+	childLogger.Info().Str("func","NewRateLimiter").Interface("rateLimit", rateLimit).Send()
+
+	rateLimiter := &RateLimiter{}
+	rateLimiter.SetLimit(rateLimit)
+	return rateLimiter
+}
+
+// About change the limits (0 requests per second disables the limiter)
+func (r *RateLimiter) SetLimit(rateLimit model.RateLimit) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.rate = float64(rateLimit.RequestsPerSecond)
+	r.burst = float64(rateLimit.Burst)
+	r.tokens = r.burst
+	r.lastRefill = time.Now()
+}
+
+// About check if there is a token available
+func (r *RateLimiter) allow() bool {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.rate <= 0 {
+		return true
+	}
+
+	now := time.Now()
+	r.tokens = r.tokens + now.Sub(r.lastRefill).Seconds() * r.rate
+	if r.tokens > r.burst {
+		r.tokens = r.burst
+	}
+	r.lastRefill = now
+
+	if r.tokens < 1 {
+		return false
+	}
+	r.tokens = r.tokens - 1
+	return true
+}
+
+// About the rate limit middleware
+func (r *RateLimiter) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if !r.allow() {
+			childLogger.Warn().Str("func","RateLimiter").Str("path", req.URL.Path).Msg("too many requests")
+
+			api.WriteProblem(rw, req, api.NewProblem(fmt.Sprintf("%v", req.Context().Value("trace-request-id")), erro.ErrTooManyRequests))
+			return
+		}
+		next.ServeHTTP(rw, req)
+	})
+}
@@ -0,0 +1,183 @@
+package server
+
+import (
+	"strings"
+	"net/http"
+	"encoding/json"
+
+	"github.com/go-card/internal/adapter/api"
+	"github.com/go-card/internal/core/model"
+
+	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gorilla/mux/otelmux"
+)
+
+// About register all the routes of the http server
+// A route without an openapi entry is logged, see Routes and "go-card openapi check"
+func NewRouter(	httpRouters *api.HttpRouters,
+				appServer *model.AppServer,
+				rateLimiter *RateLimiter) *mux.Router {
+	childLogger.Info().Str("func","NewRouter").Send()
+
+	myRouter := mux.NewRouter().StrictSlash(true)
+	myRouter.Use(core_middleware.MiddleWareHandlerHeader)
+
+	myRouter.Handle("/metrics", promhttp.Handler())
+
+	// the spec is built from the registered routes, at the end
+	openAPI := &api.OpenAPI{}
+	docs := myRouter.Methods(http.MethodGet, http.MethodOptions).Subrouter()
+	docs.HandleFunc("/openapi.json", openAPI.Handler)
+	docs.HandleFunc("/docs", api.DocsHandler)
+
+	myRouter.HandleFunc("/", func(rw http.ResponseWriter, req *http.Request) {
+		childLogger.Debug().Msg("/")
+
+		appServerMutex.RLock()
+		defer appServerMutex.RUnlock()
+		json.NewEncoder(rw).Encode(appServer)
+	})
+
+	health := myRouter.Methods(http.MethodGet, http.MethodOptions).Subrouter()
+    health.HandleFunc("/health", httpRouters.Health)
+
+	live := myRouter.Methods(http.MethodGet, http.MethodOptions).Subrouter()
+    live.HandleFunc("/live", httpRouters.Live)
+
+	ready := myRouter.Methods(http.MethodGet, http.MethodOptions).Subrouter()
+    ready.HandleFunc("/ready", httpRouters.Ready)
+
+	header := myRouter.Methods(http.MethodGet, http.MethodOptions).Subrouter()
+    header.HandleFunc("/header", httpRouters.Header)
+
+	stat := myRouter.Methods(http.MethodGet, http.MethodOptions).Subrouter()
+    stat.HandleFunc("/stat", httpRouters.Stat)
+
+	wk_ctx := myRouter.Methods(http.MethodGet, http.MethodOptions).Subrouter()
+    wk_ctx.HandleFunc("/context", httpRouters.Context)
+
+	myRouter.HandleFunc("/info", func(rw http.ResponseWriter, req *http.Request) {
+		childLogger.Info().Str("HandleFunc","/info").Send()
+
+		rw.Header().Set("Content-Type", "application/json")
+
+		appServerMutex.RLock()
+		defer appServerMutex.RUnlock()
+		json.NewEncoder(rw).Encode(appServer)
+	})
+
+	// legacy routes, deprecated by /v1
+	deprecation := NewDeprecation()
+
+	addCard := myRouter.Methods(http.MethodPost, http.MethodOptions).Subrouter()
+	addCard.HandleFunc("/card", api.MiddleWareErrorHandler(httpRouters.AddCard))		
+	addCard.Use(otelmux.Middleware("go-card"))
+	addCard.Use(rateLimiter.Middleware)
+	addCard.Use(deprecation.Middleware("/v1/cards"))
+
+	getCard := myRouter.Methods(http.MethodGet, http.MethodOptions).Subrouter()
+	getCard.HandleFunc("/card/{id}", api.MiddleWareErrorHandler(httpRouters.GetCard))		
+	getCard.Use(otelmux.Middleware("go-card"))
+	getCard.Use(rateLimiter.Middleware)
+	getCard.Use(deprecation.Middleware("/v1/cards/{id}"))
+
+	updateCard := myRouter.Methods(http.MethodPost, http.MethodOptions).Subrouter()
+	updateCard.HandleFunc("/atc", api.MiddleWareErrorHandler(httpRouters.UpdateCard))		
+	updateCard.Use(otelmux.Middleware("go-card"))
+	updateCard.Use(rateLimiter.Middleware)
+	updateCard.Use(deprecation.Middleware("/v1/cards/{id}/atc"))
+
+	getCardToken := myRouter.Methods(http.MethodGet, http.MethodOptions).Subrouter()
+	getCardToken.HandleFunc("/cardToken/{token}", api.MiddleWareErrorHandler(httpRouters.GetCardToken))		
+	getCardToken.Use(otelmux.Middleware("go-card"))
+	getCardToken.Use(rateLimiter.Middleware)
+	getCardToken.Use(deprecation.Middleware("/v1/tokens/{token}"))
+	
+	createCardToken := myRouter.Methods(http.MethodPost, http.MethodOptions).Subrouter()
+	createCardToken.HandleFunc("/cardToken", api.MiddleWareErrorHandler(httpRouters.CreateCardToken))		
+	createCardToken.Use(otelmux.Middleware("go-card"))
+	createCardToken.Use(rateLimiter.Middleware)
+	createCardToken.Use(deprecation.Middleware("/v1/cards/{id}/tokens"))
+
+	// v1 routes
+	v1 := myRouter.PathPrefix("/v1").Subrouter()
+	v1.Use(otelmux.Middleware("go-card"))
+	v1.Use(rateLimiter.Middleware)
+
+	v1Post := v1.Methods(http.MethodPost, http.MethodOptions).Subrouter()
+	v1Post.HandleFunc("/cards", api.MiddleWareErrorHandler(httpRouters.AddCard))
+	v1Post.HandleFunc("/cards/{id}/atc", api.MiddleWareErrorHandler(httpRouters.IncrementAtc))
+	v1Post.HandleFunc("/cards/{id}/tokens", api.MiddleWareErrorHandler(httpRouters.CreateToken))
+	v1Post.HandleFunc("/cards/{id}/reissue", api.MiddleWareErrorHandler(httpRouters.ReissueCard))
+	v1Post.HandleFunc("/cards/{id}/cvv/verify", api.MiddleWareErrorHandler(httpRouters.VerifyCvv))
+	v1Post.HandleFunc("/cards/{id}/dcvv/verify", api.MiddleWareErrorHandler(httpRouters.VerifyDcvv))
+	v1Post.HandleFunc("/cards/{id}/pin", api.MiddleWareErrorHandler(httpRouters.SetPin))
+	v1Post.HandleFunc("/cards/{id}/pin/verify", api.MiddleWareErrorHandler(httpRouters.VerifyPin))
+	v1Post.HandleFunc("/cards/{id}/controls/evaluate", api.MiddleWareErrorHandler(httpRouters.EvaluateControls))
+	v1Post.HandleFunc("/cards/{id}/authorize", api.MiddleWareErrorHandler(httpRouters.Authorize))
+
+	v1Put := v1.Methods(http.MethodPut, http.MethodOptions).Subrouter()
+	v1Put.HandleFunc("/cards/{id}/pin", api.MiddleWareErrorHandler(httpRouters.ChangePin))
+	v1Put.HandleFunc("/cards/{id}/controls", api.MiddleWareErrorHandler(httpRouters.UpdateControls))
+
+	v1Get := v1.Methods(http.MethodGet, http.MethodOptions).Subrouter()
+	v1Get.HandleFunc("/cards/{id}", api.MiddleWareErrorHandler(httpRouters.GetCard))
+	v1Get.HandleFunc("/cards/{id}/dcvv", api.MiddleWareErrorHandler(httpRouters.GetDcvv))
+	v1Get.HandleFunc("/cards/{id}/controls", api.MiddleWareErrorHandler(httpRouters.GetControls))
+	v1Get.HandleFunc("/cards/{id}/authorizations", api.MiddleWareErrorHandler(httpRouters.ListAuthorizations))
+	v1Get.HandleFunc("/tokens/{token}", api.MiddleWareErrorHandler(httpRouters.GetCardToken))
+
+	purgeAccountCache := myRouter.Methods(http.MethodDelete, http.MethodOptions).Subrouter()
+	purgeAccountCache.Use(otelmux.Middleware("go-card"))
+	purgeAccountCache.Use(rateLimiter.Middleware)
+	adminToken := ""
+	if appServer.Server != nil {
+		adminToken = appServer.Server.AdminToken
+	}
+	purgeAccountCache.Use(AdminAuth(adminToken))
+	purgeAccountCache.HandleFunc("/admin/cache/account", api.MiddleWareErrorHandler(httpRouters.PurgeAccountCache))
+	purgeAccountCache.HandleFunc("/admin/cache/account/{id}", api.MiddleWareErrorHandler(httpRouters.PurgeAccountCache))
+
+	missing := openAPI.Build(Routes(myRouter), appServer.InfoPod.ApiVersion)
+	if len(missing) > 0 {
+		childLogger.Warn().Strs("routes", missing).Msg("routes without openapi entry")
+	}
+
+	return myRouter
+}
+
+// About list the routes (method and path template) registered in the router
+// The methods of a route are inherited from its parent subrouter, a route without method is listed as GET
+func Routes(router *mux.Router) []api.Route {
+	routes := []api.Route{}
+
+	router.Walk(func(route *mux.Route, _ *mux.Router, ancestors []*mux.Route) error {
+		// a subrouter (PathPrefix, Methods) has no handler
+		if route.GetHandler() == nil {
+			return nil
+		}
+		path, err := route.GetPathTemplate()
+		if err != nil {
+			return nil
+		}
+
+		methods, err := route.GetMethods()
+		for i := len(ancestors) - 1; err != nil && i >= 0; i-- {
+			methods, err = ancestors[i].GetMethods()
+		}
+		if err != nil {
+			methods = []string{http.MethodGet}
+		}
+
+		for _, method := range methods {
+			if method == http.MethodOptions {
+				continue
+			}
+			routes = append(routes, api.Route{Method: strings.ToUpper(method), Path: path})
+		}
+		return nil
+	})
+
+	return routes
+}
@@ -0,0 +1,42 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/go-card/internal/core/model"
+	"github.com/go-card/internal/adapter/api"
+)
+
+func newTestRouter() []api.Route {
+	httpRouters := api.NewHttpRouters(nil, 0)
+	router := NewRouter(&httpRouters, &model.AppServer{InfoPod: &model.InfoPod{}}, NewRateLimiter(model.RateLimit{}))
+	return Routes(router)
+}
+
+func TestRoutesHaveOpenAPIEntries(t *testing.T) {
+	missing := (&api.OpenAPI{}).Build(newTestRouter(), "")
+	if len(missing) > 0 {
+		t.Fatalf("routes without openapi entry: %v", missing)
+	}
+}
+
+func TestRoutesInheritMethods(t *testing.T) {
+	routes := map[string]bool{}
+	for _, route := range newTestRouter() {
+		routes[route.String()] = true
+	}
+
+	for _, route := range []string{
+		"POST /v1/cards",
+		"GET /v1/cards/{id}",
+		"POST /atc",
+		"DELETE /admin/cache/account/{id}",
+	} {
+		if !routes[route] {
+			t.Errorf("route %s not listed", route)
+		}
+	}
+	if routes["OPTIONS /v1/cards"] {
+		t.Errorf("OPTIONS must not be listed")
+	}
+}
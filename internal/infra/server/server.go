@@ -2,47 +2,51 @@ package server
 
 import (
 	"time"
-	"encoding/json"
 	"net/http"
 	"strconv"
 	"os"
 	"os/signal"
 	"syscall"
 	"context"
+	"sync"
 
 	"github.com/go-card/internal/adapter/api"	
 	"github.com/go-card/internal/core/model"
+	"github.com/go-card/internal/infra/configuration"
+	"github.com/go-card/internal/infra/logger"
 	
 	go_core_observ "github.com/eliezerraj/go-core/observability"  
 	go_core_midleware "github.com/eliezerraj/go-core/middleware"
 
-	"github.com/gorilla/mux"
+	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
 
 	// Trace
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/trace"
 	"go.opentelemetry.io/otel/propagation"
-	"go.opentelemetry.io/contrib/instrumentation/github.com/gorilla/mux/otelmux"
 	 sdktrace "go.opentelemetry.io/otel/sdk/trace"
 	 
 	// Metrics
 	"runtime/metrics"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/exporters/prometheus"
-	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"go.opentelemetry.io/otel/metric"
 	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
 	"go.opentelemetry.io/otel/sdk/resource"
 	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+
+	// Logs
+	sdklog "go.opentelemetry.io/otel/sdk/log"
 )
 
 var (
-	childLogger 	= log.With().Str("component","go-card").Str("package","internal.infra.server").Logger()
+	childLogger 	= logger.With().Str("component","go-card").Str("package","internal.infra.server").Logger()
 	core_middleware go_core_midleware.ToolsMiddleware
 	tracerProvider	go_core_observ.TracerProvider
 	infoTrace 		go_core_observ.InfoTrace
 	tracer			trace.Tracer
+	appServerMutex	sync.RWMutex
 )
 
 type HttpServer struct {
@@ -122,6 +126,46 @@ func setupGoRuntimeMetrics(meter metric.Meter) {
 	}
 }
 
+// About reload the runtime configuration (SIGHUP)
+// An invalid configuration is rejected and the current one is kept
+func reloadConfiguration(	httpRouters *api.HttpRouters,
+							appServer *model.AppServer,
+							rateLimiter *RateLimiter) {
+	childLogger.Info().Str("func","reloadConfiguration").Send()
+
+	appServerMutex.RLock()
+	current := *appServer
+	appServerMutex.RUnlock()
+
+	reloaded, err := configuration.ReloadAppServer(current)
+	diff := configuration.DiffAppServer(current, reloaded)
+	if err == nil {
+		err = configuration.ValidateAppServer(reloaded)
+	}
+	if err != nil {
+		childLogger.Error().Err(err).Strs("diff", diff).Msg("invalid configuration rejected, keeping the current configuration")
+		return
+	}
+	if len(diff) == 0 {
+		childLogger.Info().Msg("configuration reloaded, no changes detected")
+		return
+	}
+
+	logLevel, _ := zerolog.ParseLevel(reloaded.Server.LogLevel)
+	zerolog.SetGlobalLevel(logLevel)
+
+	httpRouters.SetRuntimeConfig(time.Duration(reloaded.Server.CtxTimeout),
+								reloaded.ApiService,
+								reloaded.FeatureFlags)
+	rateLimiter.SetLimit(*reloaded.RateLimit)
+
+	appServerMutex.Lock()
+	*appServer = reloaded
+	appServerMutex.Unlock()
+
+	childLogger.Info().Strs("diff", diff).Msg("configuration reloaded SUCCESSFULL")
+}
+
 // About start http server
 func (h HttpServer) StartHttpAppServer(	ctx context.Context, 
 										httpRouters *api.HttpRouters,
@@ -131,18 +175,20 @@ func (h HttpServer) StartHttpAppServer(	ctx context.Context,
 	// --------- OTEL traces ---------------
 	var initTracerProvider *sdktrace.TracerProvider
 	
-	if appServer.InfoPod.OtelTraces {
-		infoTrace.PodName = appServer.InfoPod.PodName
-		infoTrace.PodVersion = appServer.InfoPod.ApiVersion
-		infoTrace.ServiceType = "k8-workload"
-		infoTrace.Env = appServer.InfoPod.Env
-		infoTrace.AccountID = appServer.InfoPod.AccountID
+	infoTrace.PodName = appServer.InfoPod.PodName
+	infoTrace.PodVersion = appServer.InfoPod.ApiVersion
+	infoTrace.ServiceType = "k8-workload"
+	infoTrace.Env = appServer.InfoPod.Env
+	infoTrace.AccountID = appServer.InfoPod.AccountID
+
+	// W3C traceparent/tracestate and baggage, extracted from the inbound requests and injected on the outbound calls
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
 
+	if appServer.InfoPod.OtelTraces {
 		initTracerProvider = tracerProvider.NewTracerProvider(	ctx, 
 																appServer.ConfigOTEL, 
 																&infoTrace)
 
-		otel.SetTextMapPropagator(propagation.TraceContext{})
 		otel.SetTracerProvider(initTracerProvider)
 		tracer = initTracerProvider.Tracer(appServer.InfoPod.PodName)
 	}
@@ -151,17 +197,39 @@ func (h HttpServer) StartHttpAppServer(	ctx context.Context,
 	var meterProvider *sdkmetric.MeterProvider
 	
 	if appServer.InfoPod.OtelMetrics {
-		meterProvider, err := initMeterProvider(ctx, infoTrace.PodName)
+		var err error
+		meterProvider, err = initMeterProvider(ctx, infoTrace.PodName)
 		if err != nil {
 			childLogger.Error().Err(err).Msg("Error start Otel Metrics Provider")
 		} else {
+			otel.SetMeterProvider(meterProvider)
 			meter := meterProvider.Meter(infoTrace.PodName)
 			setupGoRuntimeMetrics(meter)
 			childLogger.Info().Msg("Otel Metrics Provider started SUCCESSFULL")
 		}
 	}
 
+	// --------- OTEL logs ---------------
+	var loggerProvider *sdklog.LoggerProvider
+
+	if appServer.InfoPod.OtelLogs {
+		var err error
+		loggerProvider, err = logger.NewLoggerProvider(ctx, appServer.ConfigOTEL, &infoTrace)
+		if err != nil {
+			childLogger.Error().Err(err).Msg("Error start Otel Logs Provider")
+		} else {
+			childLogger.Info().Msg("Otel Logs Provider started SUCCESSFULL")
+		}
+	}
+
 	defer func() {
+		if loggerProvider != nil {
+			logger.SetOtelLogger(nil)
+			if err := loggerProvider.Shutdown(ctx); err != nil {
+				childLogger.Error().Err(err).Msg("failed to stop otel logs")
+			}
+		}
+
 		if meterProvider != nil {
 			if err := meterProvider.Shutdown(ctx); err != nil {
 				childLogger.Error().Err(err).Msg("failed to stop instrumentation")
@@ -177,57 +245,8 @@ func (h HttpServer) StartHttpAppServer(	ctx context.Context,
 		childLogger.Info().Msg("stop done !!!")
 	}()
 	
-	myRouter := mux.NewRouter().StrictSlash(true)
-	myRouter.Use(core_middleware.MiddleWareHandlerHeader)
-
-	myRouter.Handle("/metrics", promhttp.Handler())
-
-	myRouter.HandleFunc("/", func(rw http.ResponseWriter, req *http.Request) {
-		childLogger.Debug().Msg("/")
-		json.NewEncoder(rw).Encode(appServer)
-	})
-
-	health := myRouter.Methods(http.MethodGet, http.MethodOptions).Subrouter()
-    health.HandleFunc("/health", httpRouters.Health)
-
-	live := myRouter.Methods(http.MethodGet, http.MethodOptions).Subrouter()
-    live.HandleFunc("/live", httpRouters.Live)
-
-	header := myRouter.Methods(http.MethodGet, http.MethodOptions).Subrouter()
-    header.HandleFunc("/header", httpRouters.Header)
-
-	stat := myRouter.Methods(http.MethodGet, http.MethodOptions).Subrouter()
-    stat.HandleFunc("/stat", httpRouters.Stat)
-
-	wk_ctx := myRouter.Methods(http.MethodGet, http.MethodOptions).Subrouter()
-    wk_ctx.HandleFunc("/context", httpRouters.Context)
-
-	myRouter.HandleFunc("/info", func(rw http.ResponseWriter, req *http.Request) {
-		childLogger.Info().Str("HandleFunc","/info").Send()
-
-		rw.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(rw).Encode(appServer)
-	})
-	
-	addCard := myRouter.Methods(http.MethodPost, http.MethodOptions).Subrouter()
-	addCard.HandleFunc("/card", core_middleware.MiddleWareErrorHandler(httpRouters.AddCard))		
-	addCard.Use(otelmux.Middleware("go-card"))
-
-	getCard := myRouter.Methods(http.MethodGet, http.MethodOptions).Subrouter()
-	getCard.HandleFunc("/card/{id}", core_middleware.MiddleWareErrorHandler(httpRouters.GetCard))		
-	getCard.Use(otelmux.Middleware("go-card"))
-
-	updateCard := myRouter.Methods(http.MethodPost, http.MethodOptions).Subrouter()
-	updateCard.HandleFunc("/atc", core_middleware.MiddleWareErrorHandler(httpRouters.UpdateCard))		
-	updateCard.Use(otelmux.Middleware("go-card"))
-
-	getCardToken := myRouter.Methods(http.MethodGet, http.MethodOptions).Subrouter()
-	getCardToken.HandleFunc("/cardToken/{id}", core_middleware.MiddleWareErrorHandler(httpRouters.GetCardToken))		
-	getCardToken.Use(otelmux.Middleware("go-card"))
-	
-	createCardToken := myRouter.Methods(http.MethodPost, http.MethodOptions).Subrouter()
-	createCardToken.HandleFunc("/cardToken", core_middleware.MiddleWareErrorHandler(httpRouters.CreateCardToken))		
-	createCardToken.Use(otelmux.Middleware("go-card"))
+	rateLimiter := NewRateLimiter(*appServer.RateLimit)
+	myRouter := NewRouter(httpRouters, appServer, rateLimiter)
 
 	srv := http.Server{
 		Addr:         ":" +  strconv.Itoa(h.httpServer.Port),      	
@@ -250,15 +269,17 @@ func (h HttpServer) StartHttpAppServer(	ctx context.Context,
 	ch := make(chan os.Signal, 1)
 	signal.Notify(ch, syscall.SIGHUP, syscall.SIGINT, syscall.SIGTERM)
 
+	loop:
 	for {
 		sig := <-ch
 
 		switch sig {
 		case syscall.SIGHUP:
 			childLogger.Info().Msg("Received SIGHUP: reloading configuration...")
+			reloadConfiguration(httpRouters, appServer, rateLimiter)
 		case syscall.SIGINT, syscall.SIGTERM:
 			childLogger.Info().Msg("Received SIGINT/SIGTERM termination signal. Exiting")
-			return
+			break loop
 		default:
 			childLogger.Info().Interface("Received signal:", sig).Send()
 		}
@@ -0,0 +1,452 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.9
+// 	protoc        v5.29.3
+// source: card/v1/card.proto
+
+package cardv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type Card struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	FkAccountId   int64                  `protobuf:"varint,2,opt,name=fk_account_id,json=fkAccountId,proto3" json:"fk_account_id,omitempty"`
+	AccountId     string                 `protobuf:"bytes,3,opt,name=account_id,json=accountId,proto3" json:"account_id,omitempty"`
+	CardNumber    string                 `protobuf:"bytes,4,opt,name=card_number,json=cardNumber,proto3" json:"card_number,omitempty"`
+	TokenData     string                 `protobuf:"bytes,5,opt,name=token_data,json=tokenData,proto3" json:"token_data,omitempty"`
+	Holder        string                 `protobuf:"bytes,6,opt,name=holder,proto3" json:"holder,omitempty"`
+	Type          string                 `protobuf:"bytes,7,opt,name=type,proto3" json:"type,omitempty"`
+	Model         string                 `protobuf:"bytes,8,opt,name=model,proto3" json:"model,omitempty"`
+	Atc           int64                  `protobuf:"varint,9,opt,name=atc,proto3" json:"atc,omitempty"`
+	Status        string                 `protobuf:"bytes,10,opt,name=status,proto3" json:"status,omitempty"`
+	ExpiredAt     *timestamppb.Timestamp `protobuf:"bytes,11,opt,name=expired_at,json=expiredAt,proto3" json:"expired_at,omitempty"`
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,12,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	UpdatedAt     *timestamppb.Timestamp `protobuf:"bytes,13,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	TenantId      string                 `protobuf:"bytes,14,opt,name=tenant_id,json=tenantId,proto3" json:"tenant_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Card) Reset() {
+	*x = Card{}
+	mi := &file_card_v1_card_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Card) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Card) ProtoMessage() {}
+
+func (x *Card) ProtoReflect() protoreflect.Message {
+	mi := &file_card_v1_card_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Card.ProtoReflect.Descriptor instead.
+func (*Card) Descriptor() ([]byte, []int) {
+	return file_card_v1_card_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Card) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *Card) GetFkAccountId() int64 {
+	if x != nil {
+		return x.FkAccountId
+	}
+	return 0
+}
+
+func (x *Card) GetAccountId() string {
+	if x != nil {
+		return x.AccountId
+	}
+	return ""
+}
+
+func (x *Card) GetCardNumber() string {
+	if x != nil {
+		return x.CardNumber
+	}
+	return ""
+}
+
+func (x *Card) GetTokenData() string {
+	if x != nil {
+		return x.TokenData
+	}
+	return ""
+}
+
+func (x *Card) GetHolder() string {
+	if x != nil {
+		return x.Holder
+	}
+	return ""
+}
+
+func (x *Card) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *Card) GetModel() string {
+	if x != nil {
+		return x.Model
+	}
+	return ""
+}
+
+func (x *Card) GetAtc() int64 {
+	if x != nil {
+		return x.Atc
+	}
+	return 0
+}
+
+func (x *Card) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *Card) GetExpiredAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ExpiredAt
+	}
+	return nil
+}
+
+func (x *Card) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *Card) GetUpdatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return nil
+}
+
+func (x *Card) GetTenantId() string {
+	if x != nil {
+		return x.TenantId
+	}
+	return ""
+}
+
+type GetCardRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	CardNumber    string                 `protobuf:"bytes,1,opt,name=card_number,json=cardNumber,proto3" json:"card_number,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetCardRequest) Reset() {
+	*x = GetCardRequest{}
+	mi := &file_card_v1_card_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetCardRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetCardRequest) ProtoMessage() {}
+
+func (x *GetCardRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_card_v1_card_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetCardRequest.ProtoReflect.Descriptor instead.
+func (*GetCardRequest) Descriptor() ([]byte, []int) {
+	return file_card_v1_card_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *GetCardRequest) GetCardNumber() string {
+	if x != nil {
+		return x.CardNumber
+	}
+	return ""
+}
+
+type IncrementAtcRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	CardNumber    string                 `protobuf:"bytes,1,opt,name=card_number,json=cardNumber,proto3" json:"card_number,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *IncrementAtcRequest) Reset() {
+	*x = IncrementAtcRequest{}
+	mi := &file_card_v1_card_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *IncrementAtcRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*IncrementAtcRequest) ProtoMessage() {}
+
+func (x *IncrementAtcRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_card_v1_card_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use IncrementAtcRequest.ProtoReflect.Descriptor instead.
+func (*IncrementAtcRequest) Descriptor() ([]byte, []int) {
+	return file_card_v1_card_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *IncrementAtcRequest) GetCardNumber() string {
+	if x != nil {
+		return x.CardNumber
+	}
+	return ""
+}
+
+type GetCardTokenRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Token         string                 `protobuf:"bytes,1,opt,name=token,proto3" json:"token,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetCardTokenRequest) Reset() {
+	*x = GetCardTokenRequest{}
+	mi := &file_card_v1_card_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetCardTokenRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetCardTokenRequest) ProtoMessage() {}
+
+func (x *GetCardTokenRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_card_v1_card_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetCardTokenRequest.ProtoReflect.Descriptor instead.
+func (*GetCardTokenRequest) Descriptor() ([]byte, []int) {
+	return file_card_v1_card_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *GetCardTokenRequest) GetToken() string {
+	if x != nil {
+		return x.Token
+	}
+	return ""
+}
+
+type GetCardTokenResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Cards         []*Card                `protobuf:"bytes,1,rep,name=cards,proto3" json:"cards,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetCardTokenResponse) Reset() {
+	*x = GetCardTokenResponse{}
+	mi := &file_card_v1_card_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetCardTokenResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetCardTokenResponse) ProtoMessage() {}
+
+func (x *GetCardTokenResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_card_v1_card_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetCardTokenResponse.ProtoReflect.Descriptor instead.
+func (*GetCardTokenResponse) Descriptor() ([]byte, []int) {
+	return file_card_v1_card_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *GetCardTokenResponse) GetCards() []*Card {
+	if x != nil {
+		return x.Cards
+	}
+	return nil
+}
+
+var File_card_v1_card_proto protoreflect.FileDescriptor
+
+const file_card_v1_card_proto_rawDesc = "" +
+	"\n" +
+	"\x12card/v1/card.proto\x12\acard.v1\x1a\x1fgoogle/protobuf/timestamp.proto\"\xd3\x03\n" +
+	"\x04Card\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12\"\n" +
+	"\rfk_account_id\x18\x02 \x01(\x03R\vfkAccountId\x12\x1d\n" +
+	"\n" +
+	"account_id\x18\x03 \x01(\tR\taccountId\x12\x1f\n" +
+	"\vcard_number\x18\x04 \x01(\tR\n" +
+	"cardNumber\x12\x1d\n" +
+	"\n" +
+	"token_data\x18\x05 \x01(\tR\ttokenData\x12\x16\n" +
+	"\x06holder\x18\x06 \x01(\tR\x06holder\x12\x12\n" +
+	"\x04type\x18\a \x01(\tR\x04type\x12\x14\n" +
+	"\x05model\x18\b \x01(\tR\x05model\x12\x10\n" +
+	"\x03atc\x18\t \x01(\x03R\x03atc\x12\x16\n" +
+	"\x06status\x18\n" +
+	" \x01(\tR\x06status\x129\n" +
+	"\n" +
+	"expired_at\x18\v \x01(\v2\x1a.google.protobuf.TimestampR\texpiredAt\x129\n" +
+	"\n" +
+	"created_at\x18\f \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x129\n" +
+	"\n" +
+	"updated_at\x18\r \x01(\v2\x1a.google.protobuf.TimestampR\tupdatedAt\x12\x1b\n" +
+	"\ttenant_id\x18\x0e \x01(\tR\btenantId\"1\n" +
+	"\x0eGetCardRequest\x12\x1f\n" +
+	"\vcard_number\x18\x01 \x01(\tR\n" +
+	"cardNumber\"6\n" +
+	"\x13IncrementAtcRequest\x12\x1f\n" +
+	"\vcard_number\x18\x01 \x01(\tR\n" +
+	"cardNumber\"+\n" +
+	"\x13GetCardTokenRequest\x12\x14\n" +
+	"\x05token\x18\x01 \x01(\tR\x05token\";\n" +
+	"\x14GetCardTokenResponse\x12#\n" +
+	"\x05cards\x18\x01 \x03(\v2\r.card.v1.CardR\x05cards2\xca\x01\n" +
+	"\vCardService\x121\n" +
+	"\aGetCard\x12\x17.card.v1.GetCardRequest\x1a\r.card.v1.Card\x12;\n" +
+	"\fIncrementAtc\x12\x1c.card.v1.IncrementAtcRequest\x1a\r.card.v1.Card\x12K\n" +
+	"\fGetCardToken\x12\x1c.card.v1.GetCardTokenRequest\x1a\x1d.card.v1.GetCardTokenResponseB)Z'github.com/go-card/proto/card/v1;cardv1b\x06proto3"
+
+var (
+	file_card_v1_card_proto_rawDescOnce sync.Once
+	file_card_v1_card_proto_rawDescData []byte
+)
+
+func file_card_v1_card_proto_rawDescGZIP() []byte {
+	file_card_v1_card_proto_rawDescOnce.Do(func() {
+		file_card_v1_card_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_card_v1_card_proto_rawDesc), len(file_card_v1_card_proto_rawDesc)))
+	})
+	return file_card_v1_card_proto_rawDescData
+}
+
+var file_card_v1_card_proto_msgTypes = make([]protoimpl.MessageInfo, 5)
+var file_card_v1_card_proto_goTypes = []any{
+	(*Card)(nil),                  // 0: card.v1.Card
+	(*GetCardRequest)(nil),        // 1: card.v1.GetCardRequest
+	(*IncrementAtcRequest)(nil),   // 2: card.v1.IncrementAtcRequest
+	(*GetCardTokenRequest)(nil),   // 3: card.v1.GetCardTokenRequest
+	(*GetCardTokenResponse)(nil),  // 4: card.v1.GetCardTokenResponse
+	(*timestamppb.Timestamp)(nil), // 5: google.protobuf.Timestamp
+}
+var file_card_v1_card_proto_depIdxs = []int32{
+	5, // 0: card.v1.Card.expired_at:type_name -> google.protobuf.Timestamp
+	5, // 1: card.v1.Card.created_at:type_name -> google.protobuf.Timestamp
+	5, // 2: card.v1.Card.updated_at:type_name -> google.protobuf.Timestamp
+	0, // 3: card.v1.GetCardTokenResponse.cards:type_name -> card.v1.Card
+	1, // 4: card.v1.CardService.GetCard:input_type -> card.v1.GetCardRequest
+	2, // 5: card.v1.CardService.IncrementAtc:input_type -> card.v1.IncrementAtcRequest
+	3, // 6: card.v1.CardService.GetCardToken:input_type -> card.v1.GetCardTokenRequest
+	0, // 7: card.v1.CardService.GetCard:output_type -> card.v1.Card
+	0, // 8: card.v1.CardService.IncrementAtc:output_type -> card.v1.Card
+	4, // 9: card.v1.CardService.GetCardToken:output_type -> card.v1.GetCardTokenResponse
+	7, // [7:10] is the sub-list for method output_type
+	4, // [4:7] is the sub-list for method input_type
+	4, // [4:4] is the sub-list for extension type_name
+	4, // [4:4] is the sub-list for extension extendee
+	0, // [0:4] is the sub-list for field type_name
+}
+
+func init() { file_card_v1_card_proto_init() }
+func file_card_v1_card_proto_init() {
+	if File_card_v1_card_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_card_v1_card_proto_rawDesc), len(file_card_v1_card_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   5,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_card_v1_card_proto_goTypes,
+		DependencyIndexes: file_card_v1_card_proto_depIdxs,
+		MessageInfos:      file_card_v1_card_proto_msgTypes,
+	}.Build()
+	File_card_v1_card_proto = out.File
+	file_card_v1_card_proto_goTypes = nil
+	file_card_v1_card_proto_depIdxs = nil
+}